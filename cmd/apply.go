@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"sai/cmd/handlers"
+	"sai/pkg/manifest"
+	"sai/pkg/output"
+
+	"github.com/spf13/cobra"
+)
+
+var manifestFlag string
+
+// applyCmd brings the software listed in a manifest to its desired state
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Bring software to the state described in a manifest",
+	Long: `Apply reads a manifest of software entries, queries their current state with
+the status actions of the providers and runs only the actions needed to reach
+the desired state. With --dry-run the plan is printed and nothing is changed.
+
+Example manifest:
+  software:
+    - name: nginx
+      state: installed   # installed (default) or absent
+      version: "1.24"    # optional
+      provider: apt      # optional provider override
+      service: running   # running or stopped
+      enabled: true      # start at boot
+    - name: telnet
+      state: absent`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runApply(cmd.Context(), manifestFlag)
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&manifestFlag, "file", "f", "sai.yaml", "Manifest file describing the desired state")
+	rootCmd.AddCommand(applyCmd)
+}
+
+// runApply reconciles the software of a manifest with its desired state
+func runApply(ctx context.Context, path string) error {
+	format, err := output.ParseFormat(outputFlag)
+	if err != nil {
+		return err
+	}
+	m, err := manifest.Load(path)
+	if err != nil {
+		return err
+	}
+
+	// The plan is computed from real status queries, even in dry run mode
+	handlers.SetDryRun(false)
+	restore := redirectMessages(format)
+	plan, err := planManifest(ctx, m)
+	restore()
+	if err != nil {
+		return err
+	}
+
+	if dryRunFlag {
+		restore := redirectMessages(format)
+		err := resolvePlan(ctx, plan)
+		restore()
+		if err != nil {
+			return err
+		}
+		return renderPlan(os.Stdout, format, plan)
+	}
+	if !yesFlag {
+		if err := confirmPlan(ctx, path, plan); err != nil {
+			return err
+		}
+	}
+	return applyPlan(withConfirmed(ctx), format, plan)
+}
+
+// confirmPlan asks the user to go ahead with a plan that interrupts services
+// or removes software or resources, showing the commands of every step
+func confirmPlan(ctx context.Context, path string, plan []manifest.Step) error {
+	restore := silenceMessages()
+	err := resolvePlan(ctx, plan)
+	restore()
+	if err != nil {
+		return err
+	}
+
+	needed := false
+	for _, step := range plan {
+		if handlers.NeedsConfirmation(step.Action) || hasDestructiveStep(step.Commands) {
+			needed = true
+		}
+	}
+	if !needed {
+		return nil
+	}
+	return confirm("apply", path, func(w io.Writer) error {
+		return writeSteps(w, plan)
+	})
+}
+
+// planManifest observes the current state of every entry and returns the
+// steps needed to reach the desired state
+func planManifest(ctx context.Context, m *manifest.Manifest) ([]manifest.Step, error) {
+	plan := []manifest.Step{}
+	for _, entry := range m.Software {
+		state, provider, err := handlers.ObserveState(ctx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("observing %s: %w", entry.Name, err)
+		}
+		for _, step := range entry.Plan(state) {
+			// Package actions reuse the provider that reported the state
+			if !step.IsService() {
+				step.Provider = provider
+			}
+			plan = append(plan, step)
+		}
+	}
+	return plan, nil
+}
+
+// resolvePlan runs the steps of the plan in dry run mode to fill in the
+// exact commands each of them would execute
+func resolvePlan(ctx context.Context, plan []manifest.Step) error {
+	handlers.SetDryRun(true)
+	defer handlers.SetDryRun(false)
+	for i, step := range plan {
+		result := output.NewResult(step.Software, step.Action)
+		result.DryRun = true
+		stepCtx := handlers.WithVersion(output.Track(ctx, result), step.Version)
+		if err := SupportedCommands[step.Action](stepCtx, step.Software, step.Provider); err != nil {
+			return fmt.Errorf("%s %s: %w", step.Action, step.Software, err)
+		}
+		plan[i].Commands = result.Plan
+	}
+	return nil
+}
+
+// applyPlan runs the steps of the plan. A failing step skips the remaining
+// steps of the same software; the other software is still reconciled.
+func applyPlan(ctx context.Context, format output.Format, plan []manifest.Step) error {
+	var firstErr error
+	failed := make(map[string]bool)
+	results := []*output.Result{}
+	for _, step := range plan {
+		if failed[step.Software] {
+			continue
+		}
+		result, err := executeAction(handlers.WithVersion(ctx, step.Version), format, step.Action, step.Software, step.Provider)
+		results = append(results, result)
+		if err != nil {
+			failed[step.Software] = true
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s %s: %w", step.Action, step.Software, err)
+			}
+		}
+	}
+
+	if len(results) == 0 && !format.IsMachine() {
+		fmt.Println("Nothing to do: everything is in the desired state")
+		return firstErr
+	}
+	if err := output.Render(os.Stdout, format, results...); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// renderPlan writes the plan in the given format
+func renderPlan(w io.Writer, format output.Format, plan []manifest.Step) error {
+	if format.IsMachine() {
+		return output.Encode(w, format, plan)
+	}
+	if len(plan) == 0 {
+		fmt.Fprintln(w, "Nothing to do: everything is in the desired state")
+		return nil
+	}
+
+	fmt.Fprintln(w, "[DRY RUN] The following actions would be executed:")
+	return writeSteps(w, plan)
+}
+
+// writeSteps writes the steps of a plan and their commands as a table
+func writeSteps(w io.Writer, plan []manifest.Step) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOFTWARE\tACTION\tPROVIDER\tREASON\tCOMMANDS")
+	for _, step := range plan {
+		commands := make([]string, len(step.Commands))
+		for i, c := range step.Commands {
+			commands[i] = c.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", step.Software, step.Action, output.Dash(step.Provider), step.Reason, output.Dash(strings.Join(commands, "; ")))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+)
+
+// TestApply tests that apply runs only the actions needed to reach the desired state
+func TestApply(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "sai.yaml")
+	content := `software:
+  - name: nginx
+    provider: apt
+    service: running
+  - name: jq
+    provider: apt
+  - name: git
+    provider: apt
+    state: absent
+`
+	if err := os.WriteFile(manifestPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// nginx is missing, jq is installed, git is not installed
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Name == "dpkg" && cmd.Args[1] != "jq" {
+			return &runner.Result{Command: cmd, ExitCode: 1, Stderr: "dpkg-query: package '" + cmd.Args[1] + "' is not installed"},
+				&errs.CommandFailedError{Command: cmd.String(), ExitCode: 1}
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	defer func() { dryRunFlag, outputFlag = false, "" }()
+
+	run := func() (string, error) {
+		oldStdout := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+		err := runApply(context.Background(), manifestPath)
+		w.Close()
+		os.Stdout = oldStdout
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		return buf.String(), err
+	}
+
+	t.Run("Dry Run", func(t *testing.T) {
+		recorder.Reset()
+		dryRunFlag = true
+		out, err := run()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(out, "install") || !strings.Contains(out, "start") || strings.Contains(out, "uninstall") {
+			t.Errorf("Expected install and start of nginx in the plan, got: %s", out)
+		}
+		if !strings.Contains(out, "apt-get install -y nginx") || !strings.Contains(out, "systemctl start nginx") {
+			t.Errorf("Expected the resolved commands in the plan, got: %s", out)
+		}
+		for _, cmd := range recorder.Commands() {
+			if cmd.Name != "dpkg" {
+				t.Errorf("Expected only status queries in dry run mode, got: %s", cmd.String())
+			}
+		}
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		recorder.Reset()
+		dryRunFlag = false
+		if _, err := run(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var changes []string
+		for _, cmd := range recorder.Commands() {
+			if cmd.Name != "dpkg" {
+				changes = append(changes, cmd.String())
+			}
+		}
+		expected := []string{"apt-get install -y nginx", "systemctl start nginx"}
+		if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("Expected %v, got %v", expected, changes)
+		}
+	})
+}
+
+// TestApplyConfirmation tests that plans removing software need a confirmation
+func TestApplyConfirmation(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "sai.yaml")
+	if err := os.WriteFile(manifestPath, []byte("software:\n  - name: jq\n    provider: apt\n    state: absent\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	defer func() { yesFlag, outputFlag = false, "" }()
+
+	removals := func() []string {
+		var changes []string
+		for _, cmd := range recorder.Commands() {
+			if cmd.Name != "dpkg" {
+				changes = append(changes, cmd.String())
+			}
+		}
+		return changes
+	}
+
+	discardOutput := func(f func()) {
+		oldStdout := os.Stdout
+		os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		f()
+		os.Stdout = oldStdout
+	}
+
+	var err error
+	discardOutput(func() { err = runApply(context.Background(), manifestPath) })
+	var confirmErr *errs.ConfirmationError
+	if !errors.As(err, &confirmErr) || errs.ExitCode(err) != errs.ExitUsage {
+		t.Errorf("Expected a confirmation error, got %v", err)
+	}
+	if changes := removals(); len(changes) != 0 {
+		t.Errorf("Expected no changes without confirmation, got %v", changes)
+	}
+
+	recorder.Reset()
+	yesFlag = true
+	discardOutput(func() { err = runApply(context.Background(), manifestPath) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if changes := removals(); len(changes) != 1 || changes[0] != "apt-get remove -y jq" {
+		t.Errorf("Expected jq to be removed, got %v", changes)
+	}
+}
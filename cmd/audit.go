@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sai/pkg/audit"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+
+	"github.com/spf13/cobra"
+)
+
+var auditLogFlag string
+
+var (
+	auditSinceFlag    string
+	auditUntilFlag    string
+	auditSoftwareFlag string
+	auditActionFlag   string
+)
+
+// auditCmd queries the audit log of executed commands
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the commands executed by sai",
+	Long: `Audit shows the entries of the audit log, an append-only JSON Lines file
+recording every command executed by sai: time, invoking user, argv, working
+directory, software, action, provider, exit code, duration and dry-run flag.
+
+The log is written to --audit-log, $SAI_AUDIT_LOG, /var/log/sai/audit.jsonl
+when running as root, or ~/.local/state/sai/audit.jsonl. It is rotated when
+it grows past 10 MiB, keeping 5 rotated files.
+
+Times accept RFC 3339 timestamps, dates (2006-01-02) or durations relative to
+now (24h, 30m).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, err := auditFilter()
+		if err != nil {
+			return err
+		}
+		return queryAudit(os.Stdout, audit.NewLog(auditLogFlag), filter)
+	},
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditSinceFlag, "since", "", "Only show commands run at or after this time")
+	auditCmd.Flags().StringVar(&auditUntilFlag, "until", "", "Only show commands run at or before this time")
+	auditCmd.Flags().StringVar(&auditSoftwareFlag, "software", "", "Only show commands run for this software")
+	auditCmd.Flags().StringVar(&auditActionFlag, "action", "", "Only show commands run for this action")
+	rootCmd.AddCommand(auditCmd)
+}
+
+// auditFilter builds the filter from the audit flags
+func auditFilter() (audit.Filter, error) {
+	filter := audit.Filter{Software: auditSoftwareFlag, Action: auditActionFlag}
+	var err error
+	if filter.Since, err = parseTime(auditSinceFlag, time.Now()); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseTime(auditUntilFlag, time.Now()); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+// parseTime parses an RFC 3339 timestamp, a date or a duration before now
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, errs.Usagef("invalid time %q: use an RFC 3339 timestamp, a date or a duration", value)
+}
+
+// queryAudit writes the audit entries selected by the filter
+func queryAudit(w io.Writer, log *audit.Log, filter audit.Filter) error {
+	format, err := output.ParseFormat(outputFlag)
+	if err != nil {
+		return err
+	}
+	entries, err := log.Query(filter)
+	if err != nil {
+		return err
+	}
+
+	if format.IsMachine() {
+		return output.Encode(w, format, entries)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(w, "No matching commands in %s\n", log.Path())
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tUSER\tSOFTWARE\tACTION\tPROVIDER\tEXIT\tDURATION\tCOMMAND")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			e.Time.Local().Format(time.DateTime), output.Dash(e.User), output.Dash(e.Software), output.Dash(e.Action), output.Dash(e.Provider),
+			e.ExitCode, time.Duration(e.DurationMS)*time.Millisecond, strings.Join(e.Argv, " "))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sai/pkg/audit"
+)
+
+// TestParseTime tests the accepted time formats of the audit filters
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		value    string
+		expected time.Time
+		valid    bool
+	}{
+		{"", time.Time{}, true},
+		{"2024-04-30T08:00:00Z", time.Date(2024, 4, 30, 8, 0, 0, 0, time.UTC), true},
+		{"24h", now.Add(-24 * time.Hour), true},
+		{"yesterday", time.Time{}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseTime(tc.value, now)
+			if tc.valid && (err != nil || !got.Equal(tc.expected)) {
+				t.Errorf("Expected %v, got %v (error: %v)", tc.expected, got, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+// TestQueryAudit tests rendering the audit log
+func TestQueryAudit(t *testing.T) {
+	log := audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	log.Append(audit.Entry{Time: time.Now(), Argv: []string{"apt-get", "install", "-y", "nginx"}, Software: "nginx", Action: "install"})
+	log.Append(audit.Entry{Time: time.Now(), Argv: []string{"dpkg", "-s", "redis"}, Software: "redis", Action: "status"})
+	defer func() { outputFlag = "" }()
+
+	var buf bytes.Buffer
+	if err := queryAudit(&buf, log, audit.Filter{Software: "nginx"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "apt-get install -y nginx") || strings.Contains(buf.String(), "redis") {
+		t.Errorf("Expected only the nginx command, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	outputFlag = "json"
+	if err := queryAudit(&buf, log, audit.Filter{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Count(buf.String(), `"argv"`) != 2 {
+		t.Errorf("Expected two JSON entries, got: %s", buf.String())
+	}
+}
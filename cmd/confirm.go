@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sai/cmd/handlers"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+
+	"golang.org/x/term"
+)
+
+var yesFlag bool
+
+// isInteractive reports whether the user can answer prompts on stdin
+var isInteractive = func() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// promptInput is where the answers to prompts are read from
+var promptInput io.Reader = os.Stdin
+
+type confirmedKey struct{}
+
+// withConfirmed returns a context whose actions were already confirmed, so
+// they run without asking again
+func withConfirmed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, confirmedKey{}, true)
+}
+
+// isConfirmed reports whether the actions of the context were already confirmed
+func isConfirmed(ctx context.Context) bool {
+	confirmed, _ := ctx.Value(confirmedKey{}).(bool)
+	return confirmed
+}
+
+// confirmAction resolves an action that changes the system to its commands
+// and, when it interrupts services or removes software or resources, asks the
+// user to go ahead. Read-only actions, dry runs and --yes skip the check.
+func confirmAction(ctx context.Context, action, software, provider string) error {
+	if skipConfirmation(ctx, action) {
+		return nil
+	}
+	// The dry run goes through the same provider selection and checks as the
+	// action itself, so its errors are the ones the action would return
+	planned, err := resolveAction(ctx, action, software, provider)
+	if err != nil {
+		return err
+	}
+	if !handlers.NeedsConfirmation(action) && !hasDestructiveStep(planned.Plan) {
+		return nil
+	}
+	return confirm(action, software, func(w io.Writer) error {
+		return output.RenderPlan(w, planned)
+	})
+}
+
+// confirmTargets asks once for an action on several targets. Targets that
+// cannot be resolved are left out of the plan; they fail the same way when
+// the action runs.
+func confirmTargets(ctx context.Context, action string, targets []target, provider string) error {
+	if skipConfirmation(ctx, action) {
+		return nil
+	}
+	needed := handlers.NeedsConfirmation(action)
+	names := make([]string, len(targets))
+	var planned []*output.Result
+	for i, t := range targets {
+		names[i] = t.name
+		result, err := resolveAction(handlers.WithVersion(ctx, t.version), action, t.name, provider)
+		if err != nil {
+			continue
+		}
+		planned = append(planned, result)
+		needed = needed || hasDestructiveStep(result.Plan)
+	}
+	if !needed {
+		return nil
+	}
+	return confirm(action, strings.Join(names, ", "), func(w io.Writer) error {
+		return output.RenderPlan(w, planned...)
+	})
+}
+
+// skipConfirmation reports whether an action runs without asking: it is
+// read-only, a dry run, already confirmed or --yes was given
+func skipConfirmation(ctx context.Context, action string) bool {
+	return yesFlag || dryRunFlag || isConfirmed(ctx) || handlers.ActionRisk(action) == handlers.RiskReadOnly
+}
+
+// resolveAction runs an action in dry run mode, without printing anything,
+// and returns its result holding the commands it would run
+func resolveAction(ctx context.Context, action, software, provider string) (*output.Result, error) {
+	planned := output.NewResult(software, action)
+	planned.DryRun = true
+	handlers.SetDryRun(true)
+	restore := silenceMessages()
+	err := SupportedCommands[action](runner.WithQuiet(output.Track(ctx, planned)), software, provider)
+	restore()
+	handlers.SetDryRun(false)
+	return planned, err
+}
+
+// confirm shows what an action will do and asks the user to go ahead.
+// Sessions that cannot prompt are refused, since nobody can confirm.
+func confirm(action, target string, show func(io.Writer) error) error {
+	if !isInteractive() {
+		return &errs.ConfirmationError{Action: action, Software: target}
+	}
+
+	fmt.Fprintf(os.Stderr, "About to %s %s:\n", action, target)
+	if err := show(os.Stderr); err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stderr, "\nContinue? [y/N] ")
+	answer, _ := bufio.NewReader(promptInput).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	}
+	return &errs.ConfirmationError{Action: action, Software: target, Declined: true}
+}
+
+// hasDestructiveStep reports whether any step of a plan is flagged destructive
+func hasDestructiveStep(steps []output.Step) bool {
+	for _, step := range steps {
+		if step.Destructive {
+			return true
+		}
+	}
+	return false
+}
+
+// silenceMessages discards the progress messages and warnings printed while
+// resolving actions to their commands, until the returned function is called
+func silenceMessages() func() {
+	stdout, stderr := os.Stdout, os.Stderr
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return func() {}
+	}
+	os.Stdout, os.Stderr = devNull, devNull
+	return func() {
+		os.Stdout, os.Stderr = stdout, stderr
+		devNull.Close()
+	}
+}
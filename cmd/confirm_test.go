@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"sai/cmd/handlers"
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+)
+
+// TestConfirmation tests the confirmation of disruptive and destructive actions
+func TestConfirmation(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	defer func() {
+		isInteractive = func() bool { return false }
+		promptInput = os.Stdin
+		yesFlag, dryRunFlag, outputFlag = false, false, ""
+		handlers.SetDryRun(false)
+	}()
+
+	testCases := []struct {
+		name        string
+		action      string
+		provider    string
+		interactive bool
+		answer      string
+		yes         bool
+		dryRun      bool
+		expected    string
+		exitCode    int
+	}{
+		{"Non-Interactive Uninstall", "uninstall", "apt", false, "", false, false, "", errs.ExitUsage},
+		{"Non-Interactive Stop", "stop", "", false, "", false, false, "", errs.ExitUsage},
+		{"Yes Flag", "uninstall", "apt", false, "", true, false, "apt-get remove -y nginx", errs.ExitOK},
+		{"Dry Run", "uninstall", "apt", false, "", false, true, "", errs.ExitOK},
+		{"Confirmed", "uninstall", "apt", true, "y\n", false, false, "apt-get remove -y nginx", errs.ExitOK},
+		{"Declined", "uninstall", "apt", true, "n\n", false, false, "", errs.ExitError},
+		{"No Answer", "disable", "", true, "", false, false, "", errs.ExitError},
+		{"Install Needs No Confirmation", "install", "apt", false, "", false, false, "apt-get install -y nginx", errs.ExitOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder.Reset()
+			isInteractive = func() bool { return tc.interactive }
+			promptInput = strings.NewReader(tc.answer)
+			yesFlag, dryRunFlag, outputFlag = tc.yes, tc.dryRun, ""
+			handlers.SetDryRun(tc.dryRun)
+
+			restore := silenceMessages()
+			err := runAction(context.Background(), tc.action, "nginx", tc.provider)
+			restore()
+
+			if code := errs.ExitCode(err); code != tc.exitCode {
+				t.Fatalf("Expected exit code %d, got %d (error: %v)", tc.exitCode, code, err)
+			}
+			var confirmErr *errs.ConfirmationError
+			if err != nil && !errors.As(err, &confirmErr) {
+				t.Errorf("Expected a confirmation error, got %v", err)
+			}
+
+			var commands []string
+			for _, cmd := range recorder.Commands() {
+				commands = append(commands, cmd.String())
+			}
+			if tc.expected == "" && len(commands) != 0 {
+				t.Errorf("Expected no commands to run, got %v", commands)
+			}
+			if tc.expected != "" && (len(commands) == 0 || commands[0] != tc.expected) {
+				t.Errorf("Expected %q to run, got %v", tc.expected, commands)
+			}
+		})
+	}
+}
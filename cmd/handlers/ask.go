@@ -15,6 +15,6 @@ func NewAskHandler() *AskHandler {
 }
 
 // Handle executes the ask command
-func (h *AskHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *AskHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
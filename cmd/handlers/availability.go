@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"sai/cmd/providers/artifact"
+	"sai/cmd/providers/container"
+	"sai/cmd/providers/definition"
+	"sai/pkg/errs"
+	registry "sai/pkg/providers"
+	"sai/pkg/runner"
+)
+
+// providerDefinition returns the definition of a provider, or nil for
+// providers implemented by plugins
+func providerDefinition(name string) *definition.Definition {
+	if pluginFor(name) != nil {
+		return nil
+	}
+	d, err := definition.Get(name)
+	if err != nil {
+		return nil
+	}
+	return d
+}
+
+// FallbackProvidersByOSDistro lists, in order of preference, the providers
+// tried after the default one when it is not available
+var FallbackProvidersByOSDistro = map[OSDistroKey][]string{
+	{OSLinux, LinuxRedHat}: {ProviderYUM, ProviderRPM},
+	{OSLinux, LinuxSuse}:   {ProviderRPM},
+	{OSLinux, LinuxOther}:  {ProviderDNF, ProviderYUM, ProviderRPM, ProviderZypper, ProviderPacman, ProviderAPK, ProviderBrew},
+}
+
+// Availability is the result of probing a provider
+type Availability struct {
+	Provider   string `json:"provider"`
+	Available  bool   `json:"available"`
+	Binary     string `json:"binary,omitempty"`
+	Version    string `json:"version,omitempty"`
+	NeedsRoot  bool   `json:"needs_root"`
+	Privileged bool   `json:"privileged"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// isPrivileged reports whether sai runs with administrator privileges
+func isPrivileged() bool {
+	// Geteuid returns -1 on Windows, where elevation is handled by the tools themselves
+	uid := os.Geteuid()
+	return uid == 0 || uid == -1
+}
+
+// probeBinary locates the binary of a provider
+func probeBinary(ctx context.Context, name string) Availability {
+	availability := Availability{Provider: name, Privileged: isPrivileged()}
+	if tools, ok := artifact.Tools[name]; ok {
+		return probeTools(ctx, availability, tools)
+	}
+	if runtimes, ok := container.Runtimes[name]; ok {
+		return probeTools(ctx, availability, runtimes)
+	}
+	if name == ProviderCompose {
+		_, path, err := container.FindCompose(ctx)
+		var unavailable *errs.ProviderUnavailableError
+		if errors.As(err, &unavailable) {
+			availability.Reason = unavailable.Reason
+			return availability
+		}
+		availability.Available, availability.Binary = true, path
+		return availability
+	}
+	d := providerDefinition(name)
+	if d == nil {
+		return probePlugin(ctx, availability)
+	}
+	availability.NeedsRoot = d.NeedsRoot
+	return probeTools(ctx, availability, d.Binaries)
+}
+
+// probeTools locates the first of the binaries found in the PATH. A provider
+// without binaries is always available.
+func probeTools(ctx context.Context, availability Availability, binaries []string) Availability {
+	if len(binaries) == 0 {
+		availability.Available = true
+		return availability
+	}
+	for _, binary := range binaries {
+		if path, err := runner.LookPath(ctx, binary); err == nil {
+			availability.Available = true
+			availability.Binary = path
+			return availability
+		}
+	}
+	availability.Reason = fmt.Sprintf("%s not found in PATH", strings.Join(binaries, ", "))
+	return availability
+}
+
+// probePlugin locates the external plugin of a provider that is not built in
+func probePlugin(ctx context.Context, availability Availability) Availability {
+	plugin, err := registry.LoadPlugin(ctx, availability.Provider)
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		availability.Reason = "unknown provider"
+	case err != nil:
+		availability.Reason = err.Error()
+	default:
+		availability.Available = true
+		availability.Binary = plugin.Path
+		availability.Version = plugin.Version
+	}
+	return availability
+}
+
+// ProbeProvider checks whether a provider is usable, including its version
+func ProbeProvider(ctx context.Context, name string) Availability {
+	availability := probeBinary(ctx, name)
+	if !availability.Available {
+		return availability
+	}
+
+	// Plugins report their version in the handshake
+	d := providerDefinition(name)
+	if d == nil {
+		return availability
+	}
+	cmd := runner.NewCommand(availability.Binary, d.VersionArgs...)
+	cmd.Quiet = true
+	if result, err := runner.Run(ctx, cmd); err == nil {
+		output := strings.TrimSpace(result.Stdout)
+		if output == "" {
+			output = strings.TrimSpace(result.Stderr)
+		}
+		availability.Version, _, _ = strings.Cut(output, "\n")
+	}
+	return availability
+}
+
+// ProviderChain returns the providers tried, in order, for the current platform
+func ProviderChain() []string {
+	os, distro := detectCurrentOS()
+	key := OSDistroKey{OS: os, Distro: distro}
+
+	chain := []string{ProviderAPT}
+	if provider, ok := DefaultProvidersByOSDistro[key]; ok {
+		chain = []string{provider}
+	}
+	for _, fallback := range FallbackProvidersByOSDistro[key] {
+		if fallback != chain[0] {
+			chain = append(chain, fallback)
+		}
+	}
+	return chain
+}
+
+// selectDefaultProvider returns the first available provider of the chain
+func selectDefaultProvider(ctx context.Context) (string, error) {
+	chain := ProviderChain()
+	for _, provider := range chain {
+		if probeBinary(ctx, provider).Available {
+			return provider, nil
+		}
+	}
+	return chain[0], &errs.ProviderUnavailableError{
+		Provider: chain[0],
+		Reason:   fmt.Sprintf("none of the providers for this platform is installed (tried %s)", strings.Join(chain, ", ")),
+	}
+}
+
+// checkPrivileges warns when a mutating action is about to run without the
+// privileges the provider needs
+func checkPrivileges(availability Availability, action string) {
+	if !availability.NeedsRoot || availability.Privileged || !isMutatingAction(action) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s usually requires root privileges to %s; consider running with sudo\n",
+		availability.Provider, action)
+}
@@ -1,23 +1,45 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
-	"runtime"
+	"os"
+	"sort"
 	"strings"
 
 	"sai/cmd/providers"
+	"sai/cmd/providers/container"
+	"sai/cmd/providers/definition"
 	"sai/cmd/providers/os/service"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/platform"
+	registry "sai/pkg/providers"
 )
 
-// CommandHandler function type
-type CommandHandler func(string, string)
+// CommandHandler runs an action on a piece of software with an optional provider
+type CommandHandler func(ctx context.Context, software, provider string) error
+
+// Handler is implemented by every command handler
+type Handler interface {
+	Handle(software, provider string) error
+	setContext(ctx context.Context)
+}
+
+// Invoke runs the handler with the given context, so that concurrent
+// invocations each carry their own runner and result
+func Invoke(ctx context.Context, h Handler, software, provider string) error {
+	h.setContext(ctx)
+	return h.Handle(software, provider)
+}
 
 // ProviderType represents a category of providers
 type ProviderType string
 
 // Provider interface defines methods for provider implementations
 type Provider interface {
-	Execute(action, software string) error
+	Execute(ctx context.Context, action, software string) error
 }
 
 // Provider types
@@ -25,22 +47,56 @@ const (
 	ProviderTypeOS        ProviderType = "os"        // OS package managers
 	ProviderTypeContainer ProviderType = "container" // Container orchestration tools
 	ProviderTypeCloud     ProviderType = "cloud"     // Cloud service providers
+	ProviderTypeLanguage  ProviderType = "language"  // Language package managers
+	ProviderTypeArtifact  ProviderType = "artifact"  // Release artifacts installed by sai itself
+	ProviderTypeService   ProviderType = "service"   // Service managers
 )
 
+// installsPackages reports whether the providers of the type install
+// packages, whose names the saidata maps per provider and distribution
+func (t ProviderType) installsPackages() bool {
+	return t == ProviderTypeOS || t == ProviderTypeLanguage
+}
+
 // Supported OS providers
 const (
-	ProviderRPM    = "rpm"
-	ProviderAPT    = "apt"
-	ProviderBrew   = "brew"
-	ProviderWinget = "winget"
-	ProviderPacman = "pacman"
-	ProviderZypper = "zypper"
+	ProviderRPM     = "rpm"
+	ProviderDNF     = "dnf"
+	ProviderYUM     = "yum"
+	ProviderAPT     = "apt"
+	ProviderBrew    = "brew"
+	ProviderWinget  = "winget"
+	ProviderPacman  = "pacman"
+	ProviderZypper  = "zypper"
+	ProviderAPK     = "apk"
+	ProviderSnap    = "snap"
+	ProviderFlatpak = "flatpak"
+	ProviderNix     = "nix"
 )
 
 // Supported container providers
 const (
 	ProviderHelm    = "helm"
 	ProviderKubectl = "kubectl"
+	ProviderDocker  = "docker"
+	ProviderPodman  = "podman"
+	ProviderCompose = "compose"
+)
+
+// Supported language providers
+const (
+	ProviderPip   = "pip"
+	ProviderPipx  = "pipx"
+	ProviderNPM   = "npm"
+	ProviderCargo = "cargo"
+	ProviderGem   = "gem"
+	ProviderGo    = "go"
+)
+
+// Supported artifact providers
+const (
+	ProviderBinary = "binary"
+	ProviderSource = "source"
 )
 
 // Supported cloud providers
@@ -61,9 +117,9 @@ const (
 const (
 	LinuxRedHat = "redhat"
 	LinuxDebian = "debian"
-	LinuxUbuntu = "ubuntu"
 	LinuxSuse   = "suse"
 	LinuxArch   = "arch"
+	LinuxAlpine = "alpine"
 	LinuxOther  = "other"
 )
 
@@ -77,21 +133,42 @@ type OSDistroKey struct {
 var ProvidersByType = map[ProviderType][]string{
 	ProviderTypeOS: {
 		ProviderRPM,
+		ProviderDNF,
+		ProviderYUM,
 		ProviderAPT,
 		ProviderBrew,
 		ProviderWinget,
 		ProviderPacman,
 		ProviderZypper,
+		ProviderAPK,
+		ProviderSnap,
+		ProviderFlatpak,
+		ProviderNix,
 	},
 	ProviderTypeContainer: {
 		ProviderHelm,
 		ProviderKubectl,
+		ProviderDocker,
+		ProviderPodman,
+		ProviderCompose,
 	},
 	ProviderTypeCloud: {
 		ProviderAWS,
 		ProviderAzure,
 		ProviderGCP,
 	},
+	ProviderTypeLanguage: {
+		ProviderPip,
+		ProviderPipx,
+		ProviderNPM,
+		ProviderCargo,
+		ProviderGem,
+		ProviderGo,
+	},
+	ProviderTypeArtifact: {
+		ProviderBinary,
+		ProviderSource,
+	},
 }
 
 // AllProviders contains all supported providers, created by merging all provider types
@@ -112,11 +189,11 @@ var DefaultProvidersByOSDistro = map[OSDistroKey]string{
 	{OSMacOS, ""}: ProviderBrew,
 
 	// Linux distro mappings
-	{OSLinux, LinuxRedHat}: ProviderRPM,
+	{OSLinux, LinuxRedHat}: ProviderDNF,
 	{OSLinux, LinuxDebian}: ProviderAPT,
-	{OSLinux, LinuxUbuntu}: ProviderAPT,
 	{OSLinux, LinuxSuse}:   ProviderZypper,
 	{OSLinux, LinuxArch}:   ProviderPacman,
+	{OSLinux, LinuxAlpine}: ProviderAPK,
 	{OSLinux, LinuxOther}:  ProviderAPT,
 }
 
@@ -125,46 +202,29 @@ type BaseHandler struct {
 	Action       string
 	Provider     string
 	ProviderType ProviderType
-}
-
-// OSProvider handles OS package manager operations
-type OSProvider struct {
-	Name string
-}
-
-// Execute runs the command for an OS provider
-func (p *OSProvider) Execute(action, software string) error {
-	fmt.Printf("Executing %s %s with OS provider %s\n", action, software, p.Name)
-	// Actual implementation would run the appropriate package manager command
-	return nil
-}
-
-// ContainerProvider handles container operations
-type ContainerProvider struct {
-	Name string
-}
 
-// Execute runs the command for a container provider
-func (p *ContainerProvider) Execute(action, software string) error {
-	fmt.Printf("Executing %s %s with container provider %s\n", action, software, p.Name)
-	// Actual implementation would run the appropriate container command
-	return nil
+	ctx context.Context
 }
 
-// CloudProvider handles cloud operations
-type CloudProvider struct {
-	Name string
+// setContext sets the context of this handler invocation
+func (h *BaseHandler) setContext(ctx context.Context) {
+	h.ctx = ctx
 }
 
-// Execute runs the command for a cloud provider
-func (p *CloudProvider) Execute(action, software string) error {
-	fmt.Printf("Executing %s %s with cloud provider %s\n", action, software, p.Name)
-	// Actual implementation would run the appropriate cloud command
-	return nil
+// Context returns the context of this handler invocation, defaulting to the
+// global execution context
+func (h *BaseHandler) Context() context.Context {
+	if h.ctx != nil {
+		return h.ctx
+	}
+	return executionContext()
 }
 
 // newProvider creates a new provider instance based on provider name and type
 func newProvider(name string, providerType ProviderType) providers.Provider {
+	if plugin := pluginFor(name); plugin != nil {
+		return plugin
+	}
 	switch providerType {
 	case ProviderTypeOS:
 		return providers.NewOSProvider(name)
@@ -172,11 +232,35 @@ func newProvider(name string, providerType ProviderType) providers.Provider {
 		return providers.NewContainerProvider(name)
 	case ProviderTypeCloud:
 		return providers.NewCloudProvider(name)
+	case ProviderTypeLanguage:
+		return providers.NewLanguageProvider(name)
+	case ProviderTypeArtifact:
+		return providers.NewArtifactProvider(name)
 	default:
 		return providers.NewOSProvider("apt") // Default fallback
 	}
 }
 
+// sortedProviders returns the names of all supported providers, plugins
+// included, in alphabetical order
+func sortedProviders() []string {
+	names := append([]string(nil), AllProviders...)
+	if definitions, err := definition.All(); err == nil {
+		for _, d := range definitions {
+			if !containsString(names, d.Name) {
+				names = append(names, d.Name)
+			}
+		}
+	}
+	for _, plugin := range registry.ListPlugins() {
+		if valid, _ := validateProvider(plugin); valid == "" {
+			names = append(names, plugin)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 // validateProvider validates the provider string and returns its type
 func validateProvider(provider string) (string, ProviderType) {
 	provider = strings.TrimSpace(strings.ToLower(provider))
@@ -192,23 +276,74 @@ func validateProvider(provider string) (string, ProviderType) {
 		}
 	}
 
+	// Custom provider definitions
+	if d, err := definition.Get(provider); err == nil && d != nil {
+		return provider, ProviderType(d.Type)
+	}
+
 	return "", ""
 }
 
-// detectLinuxDistro attempts to determine the Linux distribution
-func detectLinuxDistro() string {
-	// Implementation omitted for brevity
-	return LinuxDebian
+// containsString reports whether the list contains the value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// detectPlatform detects the platform sai is running on; tests may replace it
+var detectPlatform = platform.Detect
+
+// detectLinuxDistro maps the detected distribution family to a Linux distro constant
+func detectLinuxDistro(p platform.Platform) string {
+	switch p.Family {
+	case platform.FamilyRedHat:
+		return LinuxRedHat
+	case platform.FamilyDebian:
+		return LinuxDebian
+	case platform.FamilySuse:
+		return LinuxSuse
+	case platform.FamilyArch:
+		return LinuxArch
+	case platform.FamilyAlpine:
+		return LinuxAlpine
+	default:
+		return LinuxOther
+	}
 }
 
 // detectCurrentOS gets the current OS and distro
 func detectCurrentOS() (string, string) {
-	os := runtime.GOOS
+	p := detectPlatform()
 	var distro string
-	if os == OSLinux {
-		distro = detectLinuxDistro()
+	if p.OS == OSLinux {
+		distro = detectLinuxDistro(p)
 	}
-	return os, distro
+	return p.OS, distro
+}
+
+// runsContainers reports whether the provider runs software as containers on
+// the local host, managing their lifecycle instead of the service manager
+func runsContainers(provider string) bool {
+	_, ok := container.Runtimes[provider]
+	return ok || provider == ProviderCompose
+}
+
+// performsServiceAction reports whether a provider performs a service action
+// itself, like cloud providers starting instances, instead of leaving it to the
+// service manager
+func performsServiceAction(provider, action string) bool {
+	if runsContainers(provider) {
+		return true
+	}
+	if plugin := pluginFor(provider); plugin != nil {
+		return plugin.Supports(action)
+	}
+	d := providerDefinition(provider)
+	return d != nil && d.Supports(action)
 }
 
 // isServiceAction checks if the action is a service operation
@@ -222,33 +357,53 @@ func isServiceAction(action string) bool {
 	return false
 }
 
-// DetectDefaultProvider detects the default provider for the current OS
+// DetectDefaultProvider detects the default provider for the current OS,
+// regardless of whether it is installed
 func (h *BaseHandler) DetectDefaultProvider() string {
-	os, distro := detectCurrentOS()
-	key := OSDistroKey{OS: os, Distro: distro}
-	if provider, ok := DefaultProvidersByOSDistro[key]; ok {
-		return provider
-	}
-	// Default fallback
-	return ProviderAPT
+	return ProviderChain()[0]
 }
 
-// SetProvider sets the provider and type based on the specified provider name
-func (h *BaseHandler) SetProvider(provider string) {
-	if provider == "" {
-		h.Provider = h.DetectDefaultProvider()
+// SetProvider sets the provider and type based on the specified provider name.
+// Without a provider, the first available provider of the platform chain is
+// used. An explicitly requested provider is never replaced by another one:
+// when it is unknown or not installed an error is returned.
+func (h *BaseHandler) SetProvider(provider string) error {
+	ctx := h.Context()
+
+	if strings.TrimSpace(provider) == "" {
+		selected, err := selectDefaultProvider(ctx)
+		if err != nil && !IsDryRun() {
+			return err
+		}
+		h.Provider = selected
 		h.ProviderType = ProviderTypeOS
-		return
+		checkPrivileges(probeBinary(ctx, selected), h.Action)
+		return nil
 	}
 
+	if _, err := definition.All(); err != nil {
+		return fmt.Errorf("loading provider definitions: %w", err)
+	}
 	validProvider, providerType := validateProvider(provider)
-	if validProvider != "" {
-		h.Provider = validProvider
-		h.ProviderType = providerType
-	} else {
-		h.Provider = h.DetectDefaultProvider()
-		h.ProviderType = ProviderTypeOS
+	if validProvider == "" {
+		var err error
+		if validProvider, providerType, err = loadPluginProvider(ctx, provider); err != nil {
+			return err
+		}
+	}
+
+	availability := probeBinary(ctx, validProvider)
+	if !availability.Available {
+		if !IsDryRun() {
+			return &errs.ProviderUnavailableError{Provider: validProvider, Reason: availability.Reason}
+		}
+		fmt.Fprintf(os.Stderr, "Warning: provider %s is not available: %s\n", validProvider, availability.Reason)
 	}
+
+	h.Provider = validProvider
+	h.ProviderType = providerType
+	checkPrivileges(availability, h.Action)
+	return nil
 }
 
 // GetProvider returns the provider name and type
@@ -258,57 +413,140 @@ func (h *BaseHandler) GetProvider() (string, ProviderType) {
 
 // formatMessage formats the message for the given action
 func formatMessage(action, software, provider string, providerType ProviderType) string {
-	if isServiceAction(action) {
+	if isServiceAction(action) && providerType == ProviderTypeService {
 		return fmt.Sprintf("%s service %s using %s provider", action, software, provider)
 	}
 	return fmt.Sprintf("%s %s using %s provider %s", action, software, providerType, provider)
 }
 
-// handleServiceAction handles service actions
-func (h *BaseHandler) handleServiceAction(software string) {
-	// Check if in dry run mode
-	if IsDryRun() {
-		fmt.Printf("[DRY RUN] Service command would be executed: %s service %s\n", h.Action, software)
-		return
+// resolveSoftware looks the software up in the saidata catalog, falling back
+// to the literal name with a warning when it is unknown
+func resolveSoftware(software string) (*data.Software, error) {
+	sw, err := data.Resolve(software)
+	if err != nil {
+		return nil, fmt.Errorf("loading saidata: %w", err)
 	}
+	if !sw.Known {
+		fmt.Fprintf(os.Stderr, "Warning: no saidata found for %s, using the name as is\n", software)
+	}
+	return sw, nil
+}
 
-	fmt.Printf("%s service %s\n", h.Action, software)
+// handleServiceAction handles service actions
+func (h *BaseHandler) handleServiceAction(sw *data.Software) error {
 	// Create a service provider for the current OS
-	serviceProvider := service.GetProvider(runtime.GOOS)
-	err := serviceProvider.Execute(h.Action, software)
-	if err != nil {
-		fmt.Printf("Error executing service command: %v\n", err)
+	p := detectPlatform()
+	serviceProvider := service.ForPlatform(p)
+	serviceName := sw.ServiceName(serviceProvider.GetServiceManager(), p.Distro, p.Family)
+	output.FromContext(h.Context()).SetProvider(serviceProvider.GetServiceManager(), string(ProviderTypeService), serviceName)
+
+	fmt.Printf("%s service %s using %s\n", h.Action, sw.Name, serviceProvider.GetServiceManager())
+	ctx := data.WithSoftware(h.Context(), sw)
+	if err := serviceProvider.Execute(ctx, h.Action, serviceName); err != nil {
+		return fmt.Errorf("%s service %s: %w", h.Action, sw.Name, err)
 	}
+	h.recordAction(sw, serviceProvider.GetServiceManager(), ProviderTypeService, serviceName, "")
+	return nil
 }
 
 // handlePackageAction handles package actions
-func (h *BaseHandler) handlePackageAction(software string) {
+func (h *BaseHandler) handlePackageAction(sw *data.Software) error {
 	// Get provider details
 	provider, providerType := h.GetProvider()
 
-	// Check if in dry run mode
-	if IsDryRun() {
-		fmt.Printf("[DRY RUN] Command would be executed: %s %s using %s provider %s\n",
-			h.Action, software, providerType, provider)
-		return
+	target := sw.Name
+	if providerType.installsPackages() {
+		p := detectPlatform()
+		target = sw.PackageName(provider, p.Distro, p.Family)
 	}
+	output.FromContext(h.Context()).SetProvider(provider, string(providerType), target)
 
-	fmt.Println(formatMessage(h.Action, software, provider, providerType))
+	ctx := data.WithSoftware(h.Context(), sw)
+	// Providers report the installed version through the result, so keep one
+	// even when the caller did not ask for a structured result
+	if output.FromContext(ctx) == nil {
+		ctx = output.WithResult(ctx, output.NewResult(sw.Name, h.Action))
+	}
+	version := RequestedVersion(ctx)
+	if err := checkPinning(provider, h.Action, version); err != nil {
+		return fmt.Errorf("%s %s: %w", h.Action, sw.Name, err)
+	}
+	if version != "" && SupportsVersion(h.Action) {
+		ctx = definition.WithVariables(ctx, map[string]string{"version": version})
+	} else {
+		version = ""
+	}
+
+	plugin := pluginFor(provider)
+	if plugin != nil {
+		options := pluginOptions(sw)
+		if version != "" {
+			options["version"] = version
+		}
+		ctx = registry.WithOptions(ctx, options)
+	}
+
+	// Plugins run their own commands, so only they can tell what they would
+	// do; built-in providers add their commands to the plan in dry run mode
+	if IsDryRun() && plugin != nil {
+		return pluginDryRun(ctx, plugin, h.Action, target)
+	}
+
+	fmt.Println(formatMessage(h.Action, sw.Name, provider, providerType))
+
+	if provider == ProviderSource && (h.Action == "install" || h.Action == "upgrade") {
+		// The dependencies are not the software: they get neither its version nor its result
+		if err := installBuildDependencies(h.Context(), sw); err != nil {
+			return err
+		}
+	}
 
 	providerImpl := newProvider(provider, providerType)
-	err := providerImpl.Execute(h.Action, software)
-	if err != nil {
-		fmt.Printf("Error executing command: %v\n", err)
+	if err := providerImpl.Execute(ctx, h.Action, target); err != nil {
+		return fmt.Errorf("%s %s: %w", h.Action, sw.Name, err)
 	}
+	installed := h.reportVersion(ctx, sw, provider, providerType, target)
+	h.recordAction(sw, provider, providerType, target, installed)
+	return nil
 }
 
 // Handle executes the handler with specified software and provider
-func (h *BaseHandler) Handle(software, provider string) {
-	h.SetProvider(provider)
+func (h *BaseHandler) Handle(software, provider string) error {
+	sw, err := resolveSoftware(software)
+	if err != nil {
+		return err
+	}
 
+	// Containers are started, stopped and logged by their runtime, so service
+	// actions and logs of software sai runs as containers use the same provider
+	if provider == "" && (isServiceAction(h.Action) || h.Action == "logs") {
+		if installed := installedProvider(sw.Name); runsContainers(installed) {
+			fmt.Printf("Using provider %s, which installed %s\n", installed, sw.Name)
+			provider = installed
+		}
+	}
 	if isServiceAction(h.Action) {
-		h.handleServiceAction(software)
-	} else {
-		h.handlePackageAction(software)
+		if provider == "" {
+			// Service actions go through the service manager, not the package provider
+			return h.handleServiceAction(sw)
+		}
+		// An explicit provider performs the action itself, or refuses it
+		if err := h.SetProvider(provider); err != nil {
+			return err
+		}
+		if !performsServiceAction(h.Provider, h.Action) {
+			return &errs.UnsupportedActionError{Action: h.Action, Provider: h.Provider}
+		}
+		return h.handlePackageAction(sw)
+	}
+
+	if provider == "" && reuseProviderActions[h.Action] {
+		if provider = installedProvider(sw.Name); provider != "" {
+			fmt.Printf("Using provider %s, which installed %s\n", provider, sw.Name)
+		}
+	}
+	if err := h.SetProvider(provider); err != nil {
+		return err
 	}
+	return h.handlePackageAction(sw)
 }
@@ -15,6 +15,6 @@ func NewBuildHandler() *BuildHandler {
 }
 
 // Handle executes the build command
-func (h *BuildHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *BuildHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -15,6 +15,6 @@ func NewCheckHandler() *CheckHandler {
 }
 
 // Handle executes the check command
-func (h *CheckHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *CheckHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -17,12 +17,24 @@ func NewConfigHandler() *ConfigHandler {
 }
 
 // Handle executes the config command
-func (h *ConfigHandler) Handle(software string, provider string) {
+func (h *ConfigHandler) Handle(software string, provider string) error {
 	fmt.Printf("Configuring %s settings...\n", software)
-	fmt.Println("Configuration options will be displayed here.")
+
+	sw, err := resolveSoftware(software)
+	if err != nil {
+		return err
+	}
+	if len(sw.ConfigFiles) > 0 {
+		fmt.Println("Configuration files:")
+		for _, file := range sw.ConfigFiles {
+			fmt.Printf("  - %s\n", file)
+		}
+	} else {
+		fmt.Println("Configuration options will be displayed here.")
+	}
 
 	// Display different config options based on the software
-	switch software {
+	switch sw.Name {
 	case "nginx":
 		fmt.Println("Available configuration options for nginx:")
 		fmt.Println("  - Server settings")
@@ -38,4 +50,5 @@ func (h *ConfigHandler) Handle(software string, provider string) {
 		fmt.Println("  - Basic settings")
 		fmt.Println("  - Advanced options")
 	}
+	return nil
 }
@@ -20,26 +20,74 @@ func NewDebugHandler() *DebugHandler {
 }
 
 // Handle executes the debug command
-func (h *DebugHandler) Handle(software string, provider string) {
+func (h *DebugHandler) Handle(software string, provider string) error {
 	fmt.Printf("Debug information for %s:\n", software)
 	fmt.Println("-----------------------------------")
 
 	// Display system information
 	fmt.Println("System information:")
-	fmt.Printf("  OS: %s\n", runtime.GOOS)
-	fmt.Printf("  Architecture: %s\n", runtime.GOARCH)
+	p := detectPlatform()
+	fmt.Printf("  OS: %s\n", p.OS)
+	if p.Distro != "" {
+		fmt.Printf("  Distribution: %s %s (%s family)\n", p.Distro, p.Version, p.Family)
+	}
+	if p.Init != "" {
+		fmt.Printf("  Init system: %s\n", p.Init)
+	}
+	fmt.Printf("  Architecture: %s\n", p.Arch)
 	fmt.Printf("  Go version: %s\n", runtime.Version())
 
 	// Display software specific debug info
+	sw, err := resolveSoftware(software)
+	if err != nil {
+		return err
+	}
 	fmt.Println("\nSoftware debug info:")
+	if sw.Known {
+		fmt.Printf("  Saidata: %s\n", sw.Description)
+		resolvedProvider := provider
+		if resolvedProvider == "" {
+			resolvedProvider = h.DetectDefaultProvider()
+		}
+		fmt.Printf("  Package: %s\n", sw.PackageName(resolvedProvider, p.Distro, p.Family))
+		if len(sw.Services) > 0 {
+			fmt.Printf("  Service: %s\n", sw.ServiceName("", p.Distro, p.Family))
+		}
+		for _, port := range sw.Ports {
+			fmt.Printf("  Port: %d/%s\n", port.Port, port.Protocol)
+		}
+	} else {
+		fmt.Println("  Saidata: not available")
+	}
 	if provider != "" {
 		fmt.Printf("  Using provider: %s\n", provider)
 	} else {
 		fmt.Printf("  Using default provider\n")
 	}
 
+	fmt.Println("\nProvider availability:")
+	candidates := ProviderChain()
+	if provider != "" {
+		candidates = []string{provider}
+	}
+	for _, name := range candidates {
+		availability := ProbeProvider(h.Context(), name)
+		if !availability.Available {
+			fmt.Printf("  %s: not available (%s)\n", name, availability.Reason)
+			continue
+		}
+		fmt.Printf("  %s: %s", name, availability.Binary)
+		if availability.Version != "" {
+			fmt.Printf(" (%s)", availability.Version)
+		}
+		if availability.NeedsRoot && !availability.Privileged {
+			fmt.Print(", requires root")
+		}
+		fmt.Println()
+	}
+
 	fmt.Println("\nDiagnostic commands that would be run:")
-	switch software {
+	switch sw.Name {
 	case "nginx":
 		fmt.Println("  - nginx -t (Test configuration)")
 		fmt.Println("  - nginx -V (Version and build information)")
@@ -50,4 +98,5 @@ func (h *DebugHandler) Handle(software string, provider string) {
 		fmt.Println("  - which " + software + " (Binary location)")
 		fmt.Println("  - " + software + " --version (Version information)")
 	}
+	return nil
 }
@@ -15,6 +15,6 @@ func NewDisableHandler() *DisableHandler {
 }
 
 // Handle executes the disable command
-func (h *DisableHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *DisableHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
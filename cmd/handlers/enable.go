@@ -15,6 +15,6 @@ func NewEnableHandler() *EnableHandler {
 }
 
 // Handle executes the enable command
-func (h *EnableHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *EnableHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
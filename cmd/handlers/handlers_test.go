@@ -2,12 +2,33 @@ package handlers
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"sai/cmd/providers/container"
+	"sai/cmd/providers/definition"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/platform"
+	"sai/pkg/runner"
+	"sai/pkg/state"
 )
 
+// TestMain replaces the command runner so tests never touch the real system
+func TestMain(m *testing.M) {
+	runner.SetDefault(runner.NewRecorder())
+	state.SetDefault(nil)
+	os.Exit(m.Run())
+}
+
 // captureOutput captures stdout during test execution
 func captureOutput(f func()) string {
 	// Save original stdout
@@ -31,9 +52,25 @@ func captureOutput(f func()) string {
 	return buf.String()
 }
 
+// captureStderr captures stderr during test execution
+func captureStderr(f func()) string {
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	f()
+
+	w.Close()
+	os.Stderr = oldStderr
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
 // Define a common interface that all handlers implement
 type HandlerInterface interface {
-	Handle(string, string)
+	Handle(string, string) error
 }
 
 // TestBaseHandlerPackageCommands tests the BaseHandler with package management commands
@@ -105,6 +142,52 @@ func TestBaseHandlerServiceCommands(t *testing.T) {
 	}
 }
 
+// TestExplicitServiceProvider tests service actions with an explicit
+// provider, which performs them itself instead of the service manager
+func TestExplicitServiceProvider(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Missing = []string{"sai-provider-bogus"}
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	SetDryRun(false)
+
+	captureOutput(func() {
+		if err := NewStartHandler().Handle("ec2/i-123", "aws"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	commands := recorder.Commands()
+	if len(commands) == 0 || commands[0].String() != "aws ec2 start-instances --instance-ids i-123 --region us-east-1" {
+		t.Errorf("Expected the instance to be started by aws, got: %v", commands)
+	}
+
+	testCases := []struct {
+		name             string
+		provider         string
+		expectedExitCode int
+	}{
+		{"Package Provider", "apt", errs.ExitUnsupportedAction},
+		{"Unknown Provider", "bogus", errs.ExitProviderUnavailable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder.Reset()
+			var err error
+			captureOutput(func() {
+				err = NewStartHandler().Handle("nginx", tc.provider)
+			})
+			if code := errs.ExitCode(err); code != tc.expectedExitCode {
+				t.Errorf("Expected exit code %d, got %d (error: %v)", tc.expectedExitCode, code, err)
+			}
+			for _, cmd := range recorder.Commands() {
+				if cmd.Name == "service" || cmd.Name == "systemctl" {
+					t.Errorf("Expected the service manager not to run, got: %s", cmd)
+				}
+			}
+		})
+	}
+}
+
 // TestDryRunMode tests that dry run mode prevents actual command execution
 func TestDryRunMode(t *testing.T) {
 	testCases := []struct {
@@ -113,8 +196,8 @@ func TestDryRunMode(t *testing.T) {
 		software       string
 		expectedOutput string
 	}{
-		{"Package Command", func() HandlerInterface { return NewInstallHandler() }, "nginx", "[DRY RUN] Command would be executed"},
-		{"Service Command", func() HandlerInterface { return NewStartHandler() }, "redis", "[DRY RUN] Service command would be executed"},
+		{"Package Command", func() HandlerInterface { return NewInstallHandler() }, "nginx", "[DRY RUN] Would run: "},
+		{"Service Command", func() HandlerInterface { return NewStartHandler() }, "redis", "[DRY RUN] Would run: "},
 	}
 
 	for _, tc := range testCases {
@@ -138,6 +221,62 @@ func TestDryRunMode(t *testing.T) {
 	}
 }
 
+// TestDryRunPlan tests the execution plan built in dry run mode
+func TestDryRunPlan(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	detectPlatform = func() platform.Platform {
+		return platform.Platform{OS: OSLinux, Distro: "debian", Family: platform.FamilyDebian}
+	}
+	defer func() { detectPlatform = platform.Detect }()
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	testCases := []struct {
+		name     string
+		handler  Handler
+		provider string
+		expected output.Step
+	}{
+		{"Install", NewInstallHandler(), "apt", output.Step{
+			Command: "apt-get install -y apache2", NeedsRoot: true,
+		}},
+		{"Uninstall", NewUninstallHandler(), "brew", output.Step{
+			Command: "brew uninstall httpd", Destructive: true,
+		}},
+		{"Service", NewRestartHandler(), "", output.Step{
+			Command: "systemctl restart apache2", NeedsRoot: true,
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := output.NewResult("apache", tc.name)
+			captureOutput(func() {
+				if err := Invoke(output.WithResult(context.Background(), result), tc.handler, "apache", tc.provider); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			})
+
+			if len(result.Plan) != 1 {
+				t.Fatalf("Expected one planned step, got: %+v", result.Plan)
+			}
+			step := result.Plan[0]
+			if step.Command != tc.expected.Command || step.NeedsRoot != tc.expected.NeedsRoot || step.Destructive != tc.expected.Destructive {
+				t.Errorf("Expected step %+v, got %+v", tc.expected, step)
+			}
+			if strings.Join(step.Argv, " ") != tc.expected.Command {
+				t.Errorf("Expected argv of %q, got %q", tc.expected.Command, step.Argv)
+			}
+		})
+	}
+
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run in dry run mode, got: %v", commands)
+	}
+}
+
 // TestUtilityHandlers tests the utility command handlers
 func TestUtilityHandlers(t *testing.T) {
 	testCases := []struct {
@@ -167,6 +306,670 @@ func TestUtilityHandlers(t *testing.T) {
 	}
 }
 
+// TestHandlerErrors tests that handler failures are returned as typed errors
+func TestHandlerErrors(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+
+	testCases := []struct {
+		name             string
+		handlerFactory   func() HandlerInterface
+		provider         string
+		respond          func(runner.Command) (*runner.Result, error)
+		expectedExitCode int
+	}{
+		{
+			"Unsupported Action",
+			func() HandlerInterface { return NewBuildHandler() },
+			"apt",
+			nil,
+			errs.ExitUnsupportedAction,
+		},
+		{
+			"Command Failed",
+			func() HandlerInterface { return NewInstallHandler() },
+			"apt",
+			func(cmd runner.Command) (*runner.Result, error) {
+				return &runner.Result{Command: cmd, ExitCode: 100}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 100}
+			},
+			errs.ExitCommandFailed,
+		},
+		{
+			"Software Not Found",
+			func() HandlerInterface { return NewInstallHandler() },
+			"apt",
+			func(cmd runner.Command) (*runner.Result, error) {
+				return &runner.Result{Command: cmd, ExitCode: 100, Stderr: "E: Unable to locate package nginx"},
+					&errs.CommandFailedError{Command: cmd.String(), ExitCode: 100}
+			},
+			errs.ExitSoftwareNotFound,
+		},
+		{
+			"Success",
+			func() HandlerInterface { return NewInstallHandler() },
+			"apt",
+			nil,
+			errs.ExitOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetDryRun(false)
+			recorder.Respond = tc.respond
+
+			var err error
+			captureOutput(func() {
+				err = tc.handlerFactory().Handle("nginx", tc.provider)
+			})
+
+			if code := errs.ExitCode(err); code != tc.expectedExitCode {
+				t.Errorf("Expected exit code %d, got %d (error: %v)", tc.expectedExitCode, code, err)
+			}
+		})
+	}
+}
+
+// TestDetectDefaultProvider tests default provider selection per detected platform
+func TestDetectDefaultProvider(t *testing.T) {
+	defer func() { detectPlatform = platform.Detect }()
+
+	testCases := []struct {
+		platform platform.Platform
+		expected string
+	}{
+		{platform.Platform{OS: OSLinux, Distro: "ubuntu", Family: platform.FamilyDebian}, ProviderAPT},
+		{platform.Platform{OS: OSLinux, Distro: "fedora", Family: platform.FamilyRedHat}, ProviderDNF},
+		{platform.Platform{OS: OSLinux, Distro: "rocky", Family: platform.FamilyRedHat}, ProviderDNF},
+		{platform.Platform{OS: OSLinux, Distro: "opensuse-leap", Family: platform.FamilySuse}, ProviderZypper},
+		{platform.Platform{OS: OSLinux, Distro: "arch", Family: platform.FamilyArch}, ProviderPacman},
+		{platform.Platform{OS: OSLinux, Distro: "alpine", Family: platform.FamilyAlpine}, ProviderAPK},
+		{platform.Platform{OS: OSMacOS}, ProviderBrew},
+		{platform.Platform{OS: OSWindows}, ProviderWinget},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.platform.OS+"_"+tc.platform.Distro, func(t *testing.T) {
+			p := tc.platform
+			detectPlatform = func() platform.Platform { return p }
+
+			h := &BaseHandler{}
+			if provider := h.DetectDefaultProvider(); provider != tc.expected {
+				t.Errorf("Expected provider %s, got %s", tc.expected, provider)
+			}
+		})
+	}
+}
+
+// TestProviderSelection tests provider probing and the fallback chain
+func TestProviderSelection(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	detectPlatform = func() platform.Platform {
+		return platform.Platform{OS: OSLinux, Distro: "opensuse-leap", Family: platform.FamilySuse}
+	}
+	defer func() { detectPlatform = platform.Detect }()
+
+	testCases := []struct {
+		name             string
+		provider         string
+		missing          []string
+		dryRun           bool
+		expectedProvider string
+		expectedExitCode int
+	}{
+		{"Default Available", "", nil, false, ProviderZypper, errs.ExitOK},
+		{"Fallback", "", []string{"zypper"}, false, ProviderRPM, errs.ExitOK},
+		{"Nothing Available", "", []string{"zypper", "rpm"}, false, "", errs.ExitProviderUnavailable},
+		{"Nothing Available Dry Run", "", []string{"zypper", "rpm"}, true, ProviderZypper, errs.ExitOK},
+		{"Explicit Available", "brew", nil, false, ProviderBrew, errs.ExitOK},
+		{"Explicit Missing", "brew", []string{"brew"}, false, "", errs.ExitProviderUnavailable},
+		{"Explicit Missing Dry Run", "brew", []string{"brew"}, true, ProviderBrew, errs.ExitOK},
+		{"Unknown", "nosuchprovider", nil, false, "", errs.ExitProviderUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			SetDryRun(tc.dryRun)
+			defer SetDryRun(false)
+			recorder.Missing = tc.missing
+
+			h := &BaseHandler{Action: "status"}
+			err := h.SetProvider(tc.provider)
+			if code := errs.ExitCode(err); code != tc.expectedExitCode {
+				t.Fatalf("Expected exit code %d, got %d (error: %v)", tc.expectedExitCode, code, err)
+			}
+			if err == nil && h.Provider != tc.expectedProvider {
+				t.Errorf("Expected provider %s, got %s", tc.expectedProvider, h.Provider)
+			}
+		})
+	}
+}
+
+// TestRedHatProviderChain tests the dnf, yum and rpm fallbacks of Red Hat hosts
+func TestRedHatProviderChain(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	detectPlatform = func() platform.Platform {
+		return platform.Platform{OS: OSLinux, Distro: "almalinux", Family: platform.FamilyRedHat}
+	}
+	defer func() { detectPlatform = platform.Detect }()
+
+	if chain := ProviderChain(); strings.Join(chain, ",") != "dnf,yum,rpm" {
+		t.Errorf("Expected the dnf, yum, rpm chain, got %v", chain)
+	}
+
+	testCases := []struct {
+		missing  []string
+		expected string
+	}{
+		{nil, ProviderDNF},
+		{[]string{"dnf"}, ProviderYUM},
+		{[]string{"dnf", "yum"}, ProviderRPM},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			recorder.Missing = tc.missing
+			h := &BaseHandler{Action: "install"}
+			if err := h.SetProvider(""); err != nil || h.Provider != tc.expected {
+				t.Errorf("Expected provider %s, got %s (%v)", tc.expected, h.Provider, err)
+			}
+		})
+	}
+}
+
+// TestProbeProvider tests that probing reports the binary and its version
+func TestProbeProvider(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Command: cmd, Stdout: "v3.14.0+g1234\n"}, nil
+	}
+	ctx := runner.WithRunner(context.Background(), recorder)
+
+	availability := ProbeProvider(ctx, ProviderHelm)
+	if !availability.Available || availability.Binary != "/usr/bin/helm" || availability.Version != "v3.14.0+g1234" {
+		t.Errorf("Unexpected availability: %+v", availability)
+	}
+
+	commands := recorder.Commands()
+	if len(commands) != 1 || commands[0].String() != "/usr/bin/helm version --short" || !commands[0].Quiet {
+		t.Errorf("Expected a quiet version command, got: %v", commands)
+	}
+}
+
+// TestStateRecording tests that changes are recorded and reuse the installing provider
+func TestStateRecording(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Name == "brew" && cmd.Args[0] == "info" {
+			return &runner.Result{Command: cmd, Stdout: "/opt/homebrew/Cellar/jq/1.7.1 (19 files)\n"}, nil
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	store := state.NewStore(filepath.Join(t.TempDir(), state.FileName))
+	state.SetDefault(store)
+	defer state.SetDefault(nil)
+	SetDryRun(false)
+
+	captureOutput(func() {
+		if err := NewInstallHandler().Handle("jq", "brew"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	record, err := store.Get("jq")
+	if err != nil || record == nil || !record.Installed || record.Provider != "brew" || record.Version != "1.7.1" {
+		t.Fatalf("Expected jq to be recorded as installed with brew 1.7.1, got: %+v, %v", record, err)
+	}
+	if cmds := record.History[0].Commands; len(cmds) != 0 {
+		t.Errorf("Expected no commands without a result in the context, got: %v", cmds)
+	}
+
+	recorder.Reset()
+	captureOutput(func() {
+		if err := NewUninstallHandler().Handle("jq", ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	commands := recorder.Commands()
+	if len(commands) != 1 || commands[0].String() != "brew uninstall jq" {
+		t.Errorf("Expected uninstall through brew, got: %v", commands)
+	}
+	if record, _ := store.Get("jq"); record.Installed {
+		t.Errorf("Expected jq to be recorded as removed")
+	}
+}
+
+// TestBinaryProvider tests installing a release binary from a file:// URL,
+// recording its version and removing its files on uninstall
+func TestBinaryProvider(t *testing.T) {
+	dir := t.TempDir()
+	artifact := []byte("#!/bin/sh\necho sai-test-tool 1.0\n")
+	sum := sha256.Sum256(artifact)
+	os.WriteFile(filepath.Join(dir, "sai-test-tool"), artifact, 0o644)
+	os.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte(hex.EncodeToString(sum[:])+"  sai-test-tool\n"), 0o644)
+	saidata := filepath.Join(dir, "sai-test-tool.yaml")
+	os.WriteFile(saidata, []byte(`name: sai-test-tool
+binary:
+  url: file://`+filepath.ToSlash(dir)+`/sai-test-tool
+  version: "1.0"
+  checksums_url: file://`+filepath.ToSlash(dir)+`/SHA256SUMS
+`), 0o644)
+	if err := data.LoadData(saidata); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SAI_STATE_DIR", t.TempDir())
+	store := state.NewStore(filepath.Join(t.TempDir(), state.FileName))
+	state.SetDefault(store)
+	defer state.SetDefault(nil)
+	prefix := t.TempDir()
+	SetContext(definition.WithVariables(context.Background(), map[string]string{"prefix": prefix}))
+	defer SetContext(context.Background())
+	SetDryRun(false)
+
+	installed := filepath.Join(prefix, "bin", "sai-test-tool")
+	captureOutput(func() {
+		if err := NewInstallHandler().Handle("sai-test-tool", ProviderBinary); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	if content, err := os.ReadFile(installed); err != nil || !bytes.Equal(content, artifact) {
+		t.Fatalf("Expected the artifact to be installed in %s, got %q, %v", installed, content, err)
+	}
+	record, err := store.Get("sai-test-tool")
+	if err != nil || record == nil || record.Provider != ProviderBinary || record.ProviderType != string(ProviderTypeArtifact) || record.Version != "1.0" {
+		t.Fatalf("Expected sai-test-tool 1.0 to be recorded as installed with binary, got: %+v, %v", record, err)
+	}
+
+	captureOutput(func() {
+		if err := NewUninstallHandler().Handle("sai-test-tool", ""); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	if _, err := os.Stat(installed); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to be removed, got %v", installed, err)
+	}
+}
+
+// TestSourceBuildDependencies tests that the build dependencies of a source
+// recipe are installed with the package manager of the platform first
+func TestSourceBuildDependencies(t *testing.T) {
+	saidata := filepath.Join(t.TempDir(), "sai-test-source.yaml")
+	os.WriteFile(saidata, []byte(`name: sai-test-source
+source:
+  url: https://example.com/sai-test-source-{{.version}}.tar.gz
+  version: "1.0"
+  sha256: 0000000000000000000000000000000000000000000000000000000000000000
+  build_system: make
+  build_dependencies:
+    - name: libssl-dev
+      provider: apt
+    - name: openssl-devel
+      provider: dnf
+    - name: pkg-config
+`), 0o644)
+	if err := data.LoadData(saidata); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	detectPlatform = func() platform.Platform {
+		return platform.Platform{OS: OSLinux, Distro: "debian", Family: platform.FamilyDebian}
+	}
+	defer func() { detectPlatform = platform.Detect }()
+	t.Setenv("SAI_STATE_DIR", t.TempDir())
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	result := output.NewResult("sai-test-source", "install")
+	captureOutput(func() {
+		if err := Invoke(output.WithResult(context.Background(), result), NewInstallHandler(), "sai-test-source", ProviderSource); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	var plan []string
+	for _, step := range result.Plan {
+		plan = append(plan, step.Command)
+	}
+	if len(plan) < 3 || plan[0] != "apt-get install -y libssl-dev" || plan[1] != "apt-get install -y pkg-config" ||
+		plan[2] != "download https://example.com/sai-test-source-1.0.tar.gz" {
+		t.Errorf("Expected the apt build dependencies to be installed before the build, got %q", plan)
+	}
+
+	// The version requested for the software does not pin its dependencies
+	result = output.NewResult("sai-test-source", "install")
+	ctx := WithVersion(output.WithResult(context.Background(), result), "1.0")
+	captureOutput(func() {
+		if err := Invoke(ctx, NewInstallHandler(), "sai-test-source", ProviderSource); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	plan = nil
+	for _, step := range result.Plan {
+		plan = append(plan, step.Command)
+	}
+	if len(plan) < 3 || plan[0] != "apt-get install -y libssl-dev" || plan[1] != "apt-get install -y pkg-config" ||
+		plan[2] != "download https://example.com/sai-test-source-1.0.tar.gz" {
+		t.Errorf("Expected unpinned build dependencies and a pinned build, got %q", plan)
+	}
+	if result.Provider != ProviderSource || result.Target != "sai-test-source" {
+		t.Errorf("Expected the result to describe the source build, got %s %s", result.Provider, result.Target)
+	}
+}
+
+// TestContainerRuntimeProvider tests running software as a container, and
+// that service actions on it go through the container runtime
+func TestContainerRuntimeProvider(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	store := state.NewStore(filepath.Join(t.TempDir(), state.FileName))
+	state.SetDefault(store)
+	defer state.SetDefault(nil)
+	SetDryRun(false)
+
+	captureOutput(func() {
+		if err := NewInstallHandler().Handle("nginx", ProviderDocker); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	record, err := store.Get("nginx")
+	if err != nil || record == nil || record.Provider != ProviderDocker || record.ProviderType != string(ProviderTypeContainer) || record.Version != "stable" {
+		t.Fatalf("Expected nginx stable to be recorded as installed with docker, got: %+v, %v", record, err)
+	}
+
+	testCases := []struct {
+		name     string
+		handler  Handler
+		provider string
+		expected string
+	}{
+		{"Start Installed Container", NewStartHandler(), "", "docker start nginx"},
+		{"Restart With Podman", NewRestartHandler(), ProviderPodman, "podman restart nginx"},
+		{"Logs", NewLogHandler(), ProviderDocker, "docker logs nginx"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder.Reset()
+			captureOutput(func() {
+				if err := Invoke(context.Background(), tc.handler, "nginx", tc.provider); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			})
+			commands := recorder.Commands()
+			if len(commands) != 1 || strings.Join(commands[0].Argv(), " ") != tc.expected {
+				t.Errorf("Expected %q, got %v", tc.expected, commands)
+			}
+		})
+	}
+}
+
+// TestComposeProvider tests that the compose provider brings the project of
+// the saidata up and manages its lifecycle instead of the service manager
+func TestComposeProvider(t *testing.T) {
+	t.Setenv("SAI_STATE_DIR", t.TempDir())
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Command: cmd, Stdout: "3f1c2a\n"}, nil
+	}
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	store := state.NewStore(filepath.Join(t.TempDir(), state.FileName))
+	state.SetDefault(store)
+	defer state.SetDefault(nil)
+	SetDryRun(false)
+
+	captureOutput(func() {
+		if err := NewInstallHandler().Handle("prometheus", ProviderCompose); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	record, err := store.Get("prometheus")
+	if err != nil || record == nil || record.Provider != ProviderCompose || record.ProviderType != string(ProviderTypeContainer) {
+		t.Fatalf("Expected prometheus to be recorded as installed with compose, got: %+v, %v", record, err)
+	}
+	file := filepath.Join(container.ComposeDir(), "prometheus", "compose.yaml")
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("Expected the compose file to be generated: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		handler  Handler
+		expected string
+	}{
+		{"Stop Installed Project", NewStopHandler(), "docker compose -p prometheus -f " + file + " stop"},
+		{"Logs", NewLogHandler(), "docker compose -p prometheus -f " + file + " logs"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder.Reset()
+			captureOutput(func() {
+				if err := Invoke(context.Background(), tc.handler, "prometheus", ""); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			})
+			// The availability of compose is probed before the command runs
+			commands := recorder.Commands()
+			if len(commands) == 0 || strings.Join(commands[len(commands)-1].Argv(), " ") != tc.expected ||
+				strings.Join(commands[0].Argv(), " ") != "docker compose version" {
+				t.Errorf("Expected a probe of docker compose and %q, got %v", tc.expected, commands)
+			}
+		})
+	}
+}
+
+// TestUpdateHandler tests that the update action reaches the providers whose
+// definitions describe it
+func TestUpdateHandler(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Args[0] == "check-update" {
+			// Updates are available
+			return &runner.Result{Command: cmd, ExitCode: 100}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 100}
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	SetDryRun(false)
+
+	testCases := []struct {
+		provider string
+		expected string
+	}{
+		{ProviderDNF, "dnf check-update nginx"},
+		{ProviderYUM, "yum check-update nginx"},
+		{ProviderAPK, "apk update"},
+		{ProviderSnap, "snap refresh --list"},
+		{ProviderFlatpak, "flatpak update --appstream"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.provider, func(t *testing.T) {
+			recorder.Reset()
+			captureOutput(func() {
+				if err := Invoke(context.Background(), NewUpdateHandler(), "nginx", tc.provider); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			})
+			commands := recorder.Commands()
+			if len(commands) != 1 || strings.Join(commands[0].Argv(), " ") != tc.expected {
+				t.Errorf("Expected %q, got %v", tc.expected, commands)
+			}
+		})
+	}
+}
+
+// TestPluginProvider tests that providers which are not built in are served
+// by external plugins
+func TestPluginProvider(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if strings.Contains(cmd.Stdin, `"handshake"`) {
+			return &runner.Result{Command: cmd, Stdout: `{"protocol":1,"type":"os","actions":["install","status"]}`}, nil
+		}
+		return &runner.Result{Command: cmd, Stdout: `{"success":true,"commands":["acme-get nginx"],"version":"1.24.0"}`}, nil
+	}
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	SetDryRun(false)
+
+	captureOutput(func() {
+		if err := NewInstallHandler().Handle("nginx", "acme"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	commands := recorder.Commands()
+	if len(commands) != 2 || commands[1].Name != "/usr/bin/sai-provider-acme" ||
+		!strings.Contains(commands[1].Stdin, `"action":"install"`) || !strings.Contains(commands[1].Stdin, `"name":"nginx"`) {
+		t.Fatalf("Expected a handshake and an install request, got: %+v", commands)
+	}
+
+	SetDryRun(true)
+	defer SetDryRun(false)
+	out := captureOutput(func() {
+		if err := NewInstallHandler().Handle("nginx", "acme"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "[DRY RUN] Would run: acme-get nginx") {
+		t.Errorf("Expected the commands reported by the plugin, got: %s", out)
+	}
+
+	var unsupported *errs.UnsupportedActionError
+	if err := NewUpgradeHandler().Handle("nginx", "acme"); !errors.As(err, &unsupported) {
+		t.Errorf("Expected an unsupported action error, got: %v", err)
+	}
+}
+
+// TestVersionPinning tests installing a specific version and reporting the
+// version actually installed
+func TestVersionPinning(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Name == "dpkg" {
+			return &runner.Result{Command: cmd, Stdout: "Package: nginx\nStatus: install ok installed\nVersion: 1.22.1-9\n"}, nil
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	SetDryRun(false)
+
+	result := output.NewResult("nginx", "install")
+	ctx := WithVersion(output.WithResult(context.Background(), result), "1.24.0")
+	var stderr string
+	stdout := captureOutput(func() {
+		stderr = captureStderr(func() {
+			if err := Invoke(ctx, NewInstallHandler(), "nginx", "apt"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		})
+	})
+	commands := recorder.Commands()
+	if len(commands) != 2 || commands[0].String() != "apt-get install -y nginx=1.24.0" || commands[1].String() != "dpkg -s nginx" {
+		t.Fatalf("Expected a pinned install and a status query, got: %v", commands)
+	}
+	if result.Get("version") != "1.22.1-9" || !strings.Contains(stdout, "Installed nginx version 1.22.1-9") {
+		t.Errorf("Expected the installed version to be reported, got %q and output: %s", result.Get("version"), stdout)
+	}
+	if !strings.Contains(stderr, "version 1.24.0 of nginx was requested") {
+		t.Errorf("Expected a warning about the version mismatch, got: %s", stderr)
+	}
+
+	recorder.Reset()
+	var err error
+	captureOutput(func() {
+		err = Invoke(WithVersion(context.Background(), "1.24.0"), NewInstallHandler(), "nginx", "pacman")
+	})
+	var pinning *errs.VersionPinningError
+	if !errors.As(err, &pinning) || errs.ExitCode(err) != errs.ExitUnsupportedAction {
+		t.Errorf("Expected a version pinning error, got %v", err)
+	}
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands for an unsupported pin, got: %v", commands)
+	}
+}
+
+// TestActionRisk tests the risk classes of the actions
+func TestActionRisk(t *testing.T) {
+	testCases := []struct {
+		action       string
+		risk         Risk
+		confirmation bool
+	}{
+		{"status", RiskReadOnly, false},
+		{"install", RiskChange, false},
+		{"restart", RiskDisruptive, true},
+		{"stop", RiskDisruptive, true},
+		{"disable", RiskDisruptive, true},
+		{"uninstall", RiskDestructive, true},
+		{"delete", RiskDestructive, true},
+		{"custom", RiskChange, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.action, func(t *testing.T) {
+			if risk := ActionRisk(tc.action); risk != tc.risk {
+				t.Errorf("Expected %s to be %s, got %s", tc.action, tc.risk, risk)
+			}
+			if got := NeedsConfirmation(tc.action); got != tc.confirmation {
+				t.Errorf("Expected NeedsConfirmation(%s) = %v, got %v", tc.action, tc.confirmation, got)
+			}
+		})
+	}
+}
+
+// TestSaidataResolution tests that handlers pass provider specific names from saidata
+func TestSaidataResolution(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	detectPlatform = func() platform.Platform {
+		return platform.Platform{OS: OSLinux, Distro: "ubuntu", Family: platform.FamilyDebian}
+	}
+	defer func() { detectPlatform = platform.Detect }()
+	SetDryRun(false)
+
+	testCases := []struct {
+		handlerFactory func() HandlerInterface
+		software       string
+		provider       string
+		expected       string
+	}{
+		{func() HandlerInterface { return NewInstallHandler() }, "apache", "apt", "apt-get install -y apache2"},
+		{func() HandlerInterface { return NewInstallHandler() }, "redis", "apt", "apt-get install -y redis-server"},
+		{func() HandlerInterface { return NewInstallHandler() }, "unknown-software", "apt", "apt-get install -y unknown-software"},
+		{func() HandlerInterface { return NewInstallHandler() }, "nginx", "helm", "helm install nginx nginx --repo https://charts.bitnami.com/bitnami"},
+		{func() HandlerInterface { return NewInstallHandler() }, "golangci-lint", "go", "go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest"},
+		{func() HandlerInterface { return NewInstallHandler() }, "black", "pipx", "pipx install black"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.software+"_"+tc.provider, func(t *testing.T) {
+			recorder.Reset()
+			captureOutput(func() {
+				if err := tc.handlerFactory().Handle(tc.software, tc.provider); err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			})
+
+			// The install is followed by a status query reporting the installed version
+			commands := recorder.Commands()
+			if len(commands) == 0 || commands[0].String() != tc.expected {
+				t.Errorf("Expected command '%s', got: %v", tc.expected, commands)
+			}
+		})
+	}
+}
+
 // TestHandlerCreation ensures all handlers can be created without errors
 func TestHandlerCreation(t *testing.T) {
 	handlers := []struct {
@@ -17,7 +17,7 @@ func NewHelpHandler() *HelpHandler {
 }
 
 // Handle executes the help command
-func (h *HelpHandler) Handle(software string, provider string) {
+func (h *HelpHandler) Handle(software string, provider string) error {
 	fmt.Println("SAI - Smart Software Management CLI")
 	fmt.Println("-----------------------------------")
 	fmt.Println("Available commands:")
@@ -45,6 +45,7 @@ func (h *HelpHandler) Handle(software string, provider string) {
 	fmt.Println("Global Flags:")
 	fmt.Println("    --provider - Specify a provider to use for the command")
 	fmt.Println("    --dry-run  - Show what commands would be executed without running them")
+	fmt.Println("    --output   - Output format: table (default), json or yaml")
 	fmt.Println("")
 	fmt.Println("Usage:")
 	fmt.Println("  sai <software> <command>")
@@ -57,4 +58,15 @@ func (h *HelpHandler) Handle(software string, provider string) {
 	fmt.Println("  sai nginx install --provider apt")
 	fmt.Println("  sai nginx install --dry-run")
 	fmt.Println("  sai --provider apt nginx install")
+	fmt.Println("  sai nginx status --output json")
+	fmt.Println("")
+	fmt.Println("Exit Codes:")
+	fmt.Println("    0 - Success")
+	fmt.Println("    1 - Generic error")
+	fmt.Println("    2 - Usage error")
+	fmt.Println("    3 - Action not supported by the provider")
+	fmt.Println("    4 - Provider unknown or not available")
+	fmt.Println("    5 - Software not found")
+	fmt.Println("    6 - Executed command failed")
+	return nil
 }
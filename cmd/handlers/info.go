@@ -15,6 +15,6 @@ func NewInfoHandler() *InfoHandler {
 }
 
 // Handle executes the info command
-func (h *InfoHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *InfoHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -15,6 +15,6 @@ func NewInstallHandler() *InstallHandler {
 }
 
 // Handle executes the install command
-func (h *InstallHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *InstallHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
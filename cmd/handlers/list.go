@@ -15,6 +15,6 @@ func NewListHandler() *ListHandler {
 }
 
 // Handle executes the list command
-func (h *ListHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *ListHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
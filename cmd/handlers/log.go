@@ -9,12 +9,12 @@ type LogHandler struct {
 func NewLogHandler() *LogHandler {
 	return &LogHandler{
 		BaseHandler: BaseHandler{
-			Action: "Logging",
+			Action: "logs",
 		},
 	}
 }
 
 // Handle executes the log command
-func (h *LogHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *LogHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -15,6 +15,6 @@ func NewMonitorHandler() *MonitorHandler {
 }
 
 // Handle executes the monitor command
-func (h *MonitorHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *MonitorHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
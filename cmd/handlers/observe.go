@@ -15,6 +15,6 @@ func NewObserveHandler() *ObserveHandler {
 }
 
 // Handle executes the observe command
-func (h *ObserveHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *ObserveHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	registry "sai/pkg/providers"
+)
+
+// pluginFor returns the plugin registered for a provider, or nil for
+// built-in providers
+func pluginFor(name string) *registry.Plugin {
+	if provider, ok := registry.LookupProvider(name); ok {
+		return provider.Plugin
+	}
+	return nil
+}
+
+// loadPluginProvider discovers the external plugin implementing a provider
+// that is not built in, returning its name and type
+func loadPluginProvider(ctx context.Context, name string) (string, ProviderType, error) {
+	name = strings.TrimSpace(strings.ToLower(name))
+	plugin, err := registry.LoadPlugin(ctx, name)
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", "", &errs.ProviderUnavailableError{
+			Provider: name,
+			Reason:   fmt.Sprintf("unknown provider (supported: %s)", strings.Join(sortedProviders(), ", ")),
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return name, ProviderType(plugin.Type), nil
+}
+
+// pluginOptions returns the options sent to plugins along with each request
+func pluginOptions(sw *data.Software) map[string]string {
+	p := detectPlatform()
+	return map[string]string{
+		"name":   sw.Name,
+		"os":     p.OS,
+		"distro": p.Distro,
+		"family": p.Family,
+	}
+}
+
+// pluginDryRun asks a plugin what it would do and adds the commands it reports
+// to the execution plan
+func pluginDryRun(ctx context.Context, plugin *registry.Plugin, action, software string) error {
+	resp, err := plugin.Invoke(ctx, action, software, true)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", action, software, err)
+	}
+	if len(resp.Commands) == 0 {
+		fmt.Printf("[DRY RUN] Plugin %s would %s %s\n", plugin.Name, action, software)
+	}
+	result := output.FromContext(ctx)
+	for _, command := range resp.Commands {
+		result.AddStep(output.Step{Command: command, Destructive: isDestructiveAction(action)})
+		fmt.Printf("[DRY RUN] Would run: %s\n", command)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"sai/cmd/providers/os/service"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/manifest"
+	"sai/pkg/output"
+	registry "sai/pkg/providers"
+	"sai/pkg/runner"
+)
+
+// ObserveState queries the current state of a manifest entry through the
+// status actions of its package and service providers. It returns the state
+// together with the provider that was queried. Status queries only read the
+// system, so callers must not enable dry run mode while observing.
+func ObserveState(ctx context.Context, entry manifest.Entry) (manifest.State, string, error) {
+	var state manifest.State
+
+	sw, err := resolveSoftware(entry.Name)
+	if err != nil {
+		return state, "", err
+	}
+	provider := entry.Provider
+	if provider == "" {
+		provider = installedProvider(sw.Name)
+	}
+	h := &BaseHandler{Action: "status", ctx: ctx}
+	if err := h.SetProvider(provider); err != nil {
+		return state, "", err
+	}
+
+	p := detectPlatform()
+	target := sw.Name
+	if h.ProviderType.installsPackages() {
+		target = sw.PackageName(h.Provider, p.Distro, p.Family)
+	}
+
+	state.Installed, state.Version, err = queryPackage(ctx, sw, h.Provider, h.ProviderType, target)
+	if err != nil {
+		return state, h.Provider, err
+	}
+
+	if state.Installed && entry.ManagesService() {
+		serviceProvider := service.ForPlatform(p)
+		serviceName := sw.ServiceName(serviceProvider.GetServiceManager(), p.Distro, p.Family)
+		result := output.NewResult(sw.Name, "status")
+		result.SetProvider(serviceProvider.GetServiceManager(), string(ProviderTypeService), serviceName)
+		serviceState, err := serviceProvider.Status(output.WithResult(ctx, result), serviceName)
+		if err != nil {
+			return state, h.Provider, err
+		}
+		state.Running, state.Enabled = serviceState.Running, serviceState.Enabled
+	}
+	return state, h.Provider, nil
+}
+
+// queryPackage runs the status action of a provider quietly and reports
+// whether the package is installed, along with its version when the provider
+// can tell
+func queryPackage(ctx context.Context, sw *data.Software, provider string, providerType ProviderType, target string) (bool, string, error) {
+	result := output.NewResult(sw.Name, "status")
+	result.SetProvider(provider, string(providerType), target)
+	statusCtx := output.WithResult(data.WithSoftware(runner.WithQuiet(ctx), sw), result)
+	statusCtx = registry.WithOptions(statusCtx, pluginOptions(sw))
+	err := newProvider(provider, providerType).Execute(statusCtx, "status", target)
+
+	var notFound *errs.SoftwareNotFoundError
+	var commandErr *errs.CommandFailedError
+	switch {
+	case err == nil:
+		return true, result.Get("version"), nil
+	case errors.As(err, &notFound), errors.As(err, &commandErr):
+		// A failing status query means the software is not there
+		return false, "", nil
+	}
+	return false, "", err
+}
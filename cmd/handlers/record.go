@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"sai/pkg/data"
+	"sai/pkg/output"
+	"sai/pkg/state"
+)
+
+// reuseProviderActions act on installed software, so without an explicit
+// provider they use the provider that installed it
+var reuseProviderActions = map[string]bool{
+	"uninstall": true,
+	"upgrade":   true,
+}
+
+// installedProvider returns the provider recorded as having installed the
+// software, or an empty string when sai did not install it
+func installedProvider(software string) string {
+	record, err := state.Default().Get(software)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: reading the local state: %v\n", err)
+		return ""
+	}
+	if record == nil || !record.Installed {
+		return ""
+	}
+	return record.Provider
+}
+
+// recordAction records a successful change in the local state. Failing to
+// record only produces a warning, since the action itself succeeded.
+func (h *BaseHandler) recordAction(sw *data.Software, provider string, providerType ProviderType, target, version string) {
+	store := state.Default()
+	if store == nil || IsDryRun() || !isMutatingAction(h.Action) {
+		return
+	}
+
+	ctx := h.Context()
+	result := output.FromContext(ctx)
+	action := state.Action{
+		Action:       h.Action,
+		Provider:     provider,
+		ProviderType: string(providerType),
+		Target:       target,
+		Version:      version,
+		Time:         time.Now().UTC(),
+		User:         state.CurrentUser(),
+		Commands:     result.CommandLines(),
+	}
+
+	if err := store.Record(sw.Name, action); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: recording %s %s in %s: %v\n", h.Action, sw.Name, store.Path(), err)
+	}
+}
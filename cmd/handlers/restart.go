@@ -15,6 +15,6 @@ func NewRestartHandler() *RestartHandler {
 }
 
 // Handle executes the restart command
-func (h *RestartHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *RestartHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
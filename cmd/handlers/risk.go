@@ -0,0 +1,86 @@
+package handlers
+
+// Risk classifies actions by the harm they can do to a running system
+type Risk int
+
+const (
+	// RiskReadOnly actions only inspect the system
+	RiskReadOnly Risk = iota
+	// RiskChange actions install, update or start software
+	RiskChange
+	// RiskDisruptive actions interrupt running services
+	RiskDisruptive
+	// RiskDestructive actions remove software or resources
+	RiskDestructive
+)
+
+// String returns the name of the risk class
+func (r Risk) String() string {
+	switch r {
+	case RiskReadOnly:
+		return "read-only"
+	case RiskChange:
+		return "change"
+	case RiskDisruptive:
+		return "disruptive"
+	default:
+		return "destructive"
+	}
+}
+
+// actionRisks is the risk class of every action
+var actionRisks = map[string]Risk{
+	"ask":          RiskReadOnly,
+	"check":        RiskReadOnly,
+	"config":       RiskReadOnly,
+	"debug":        RiskReadOnly,
+	"help":         RiskReadOnly,
+	"info":         RiskReadOnly,
+	"list":         RiskReadOnly,
+	"log":          RiskReadOnly,
+	"logs":         RiskReadOnly,
+	"monitor":      RiskReadOnly,
+	"observe":      RiskReadOnly,
+	"search":       RiskReadOnly,
+	"status":       RiskReadOnly,
+	"test":         RiskReadOnly,
+	"trace":        RiskReadOnly,
+	"troubleshoot": RiskReadOnly,
+	"build":        RiskChange,
+	"create":       RiskChange,
+	"enable":       RiskChange,
+	"install":      RiskChange,
+	"start":        RiskChange,
+	"update":       RiskChange,
+	"upgrade":      RiskChange,
+	"disable":      RiskDisruptive,
+	"restart":      RiskDisruptive,
+	"stop":         RiskDisruptive,
+	"delete":       RiskDestructive,
+	"uninstall":    RiskDestructive,
+}
+
+// ActionRisk returns the risk class of an action. Unknown actions, such as
+// the custom actions of plugins, are assumed to change the system.
+func ActionRisk(action string) Risk {
+	if risk, ok := actionRisks[action]; ok {
+		return risk
+	}
+	return RiskChange
+}
+
+// NeedsConfirmation reports whether the action must be confirmed before it
+// runs, because it interrupts services or removes software or resources
+func NeedsConfirmation(action string) bool {
+	return ActionRisk(action) >= RiskDisruptive
+}
+
+// isMutatingAction reports whether the action changes the system
+func isMutatingAction(action string) bool {
+	return ActionRisk(action) >= RiskChange
+}
+
+// isDestructiveAction reports whether the action removes software or resources
+func isDestructiveAction(action string) bool {
+	return ActionRisk(action) == RiskDestructive
+}
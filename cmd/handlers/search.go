@@ -15,6 +15,6 @@ func NewSearchHandler() *SearchHandler {
 }
 
 // Handle executes the search command
-func (h *SearchHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *SearchHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -1,15 +1,22 @@
 package handlers
 
 import (
+	"context"
+
+	"sai/cmd/providers/artifact"
 	"sai/cmd/providers/cloud"
 	"sai/cmd/providers/container"
+	"sai/cmd/providers/language"
 	"sai/cmd/providers/os/pkgmanager"
 	"sai/cmd/providers/os/service"
+	"sai/pkg/audit"
+	"sai/pkg/platform"
 )
 
 // Global settings for handlers
 var (
-	dryRunMode bool
+	dryRunMode  bool
+	baseContext = context.Background()
 )
 
 // SetDryRun sets the dry run mode for all handlers and providers
@@ -21,9 +28,34 @@ func SetDryRun(enabled bool) {
 	service.SetDryRun(enabled)
 	cloud.SetDryRun(enabled)
 	container.SetDryRun(enabled)
+	language.SetDryRun(enabled)
+	artifact.SetDryRun(enabled)
+	audit.SetDryRun(enabled)
 }
 
 // IsDryRun returns whether dry run mode is enabled
 func IsDryRun() bool {
 	return dryRunMode
 }
+
+// SetContext sets the context used when providers execute commands,
+// allowing in-flight commands to be cancelled
+func SetContext(ctx context.Context) {
+	baseContext = ctx
+}
+
+// executionContext returns the context used when providers execute commands
+func executionContext() context.Context {
+	return baseContext
+}
+
+// SetPlatform replaces the detection of the platform sai is running on with
+// a fixed one; nil restores detection
+func SetPlatform(p *platform.Platform) {
+	if p == nil {
+		detectPlatform = platform.Detect
+		return
+	}
+	fixed := *p
+	detectPlatform = func() platform.Platform { return fixed }
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"sai/pkg/data"
+	"sai/pkg/output"
+)
+
+// installBuildDependencies installs the build dependencies of the source
+// recipe of the software with the package manager of the platform. Each
+// dependency is installed unpinned with a result of its own, whose planned
+// steps join the plan of the software.
+func installBuildDependencies(ctx context.Context, sw *data.Software) error {
+	if sw.Source == nil || len(sw.Source.BuildDependencies) == 0 {
+		return nil
+	}
+	provider, err := selectDefaultProvider(ctx)
+	if err != nil && !IsDryRun() {
+		return fmt.Errorf("installing the build dependencies of %s: %w", sw.Name, err)
+	}
+	p := detectPlatform()
+	deps := sw.Source.Dependencies(provider, p.Distro, p.Family)
+	if len(deps) == 0 {
+		return nil
+	}
+
+	fmt.Printf("Installing the build dependencies of %s using %s\n", sw.Name, provider)
+	osProvider := newProvider(provider, ProviderTypeOS)
+	plan := output.FromContext(ctx)
+	for _, dep := range deps {
+		result := output.NewResult(dep, "install")
+		if err := osProvider.Execute(output.WithResult(ctx, result), "install", dep); err != nil {
+			return fmt.Errorf("installing build dependency %s of %s: %w", dep, sw.Name, err)
+		}
+		for _, step := range result.Plan {
+			plan.AddStep(step)
+		}
+	}
+	return nil
+}
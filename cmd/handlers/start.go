@@ -15,6 +15,6 @@ func NewStartHandler() *StartHandler {
 }
 
 // Handle executes the start command
-func (h *StartHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *StartHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -15,6 +15,6 @@ func NewStatusHandler() *StatusHandler {
 }
 
 // Handle executes the status command
-func (h *StatusHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *StatusHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -15,6 +15,6 @@ func NewStopHandler() *StopHandler {
 }
 
 // Handle executes the stop command
-func (h *StopHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *StopHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -15,6 +15,6 @@ func NewTestHandler() *TestHandler {
 }
 
 // Handle executes the test command
-func (h *TestHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *TestHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
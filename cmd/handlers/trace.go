@@ -15,6 +15,6 @@ func NewTraceHandler() *TraceHandler {
 }
 
 // Handle executes the trace command
-func (h *TraceHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *TraceHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
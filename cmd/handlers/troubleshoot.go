@@ -17,12 +17,19 @@ func NewTroubleshootHandler() *TroubleshootHandler {
 }
 
 // Handle executes the troubleshoot command
-func (h *TroubleshootHandler) Handle(software string, provider string) {
+func (h *TroubleshootHandler) Handle(software string, provider string) error {
 	fmt.Printf("Troubleshooting %s...\n", software)
 	fmt.Println("Running diagnostic checks...")
 
+	sw, err := resolveSoftware(software)
+	if err != nil {
+		return err
+	}
+	p := detectPlatform()
+	serviceName := sw.ServiceName("", p.Distro, p.Family)
+
 	// Software-specific troubleshooting steps
-	switch software {
+	switch sw.Name {
 	case "nginx":
 		fmt.Println("\nNginx troubleshooting:")
 		fmt.Println("  1. Checking if Nginx is running...")
@@ -48,12 +55,20 @@ func (h *TroubleshootHandler) Handle(software string, provider string) {
 	default:
 		fmt.Println("\nGeneric troubleshooting:")
 		fmt.Println("  1. Checking if the service is running...")
-		fmt.Println("     Would run: systemctl status " + software)
+		fmt.Println("     Would run: systemctl status " + serviceName)
 		fmt.Println("  2. Checking logs...")
-		fmt.Println("     Would run: journalctl -u " + software + " --since '1 hour ago'")
-		fmt.Println("  3. Checking system resources...")
+		fmt.Println("     Would run: journalctl -u " + serviceName + " --since '1 hour ago'")
+		for _, logFile := range sw.LogFiles {
+			fmt.Println("     Would run: tail -n 50 " + logFile)
+		}
+		fmt.Println("  3. Checking open ports...")
+		for _, port := range sw.Ports {
+			fmt.Printf("     Would run: ss -ltnp 'sport = :%d'\n", port.Port)
+		}
+		fmt.Println("  4. Checking system resources...")
 		fmt.Println("     Would run: free -m && df -h")
 	}
 
 	fmt.Println("\nTroubleshooting complete. For more detailed analysis, try the debug command.")
+	return nil
 }
@@ -15,6 +15,6 @@ func NewUninstallHandler() *UninstallHandler {
 }
 
 // Handle executes the uninstall command
-func (h *UninstallHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *UninstallHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
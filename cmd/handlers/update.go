@@ -9,12 +9,12 @@ type UpdateHandler struct {
 func NewUpdateHandler() *UpdateHandler {
 	return &UpdateHandler{
 		BaseHandler: BaseHandler{
-			Action: "Updating",
+			Action: "update",
 		},
 	}
 }
 
 // Handle executes the update command
-func (h *UpdateHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *UpdateHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -15,6 +15,6 @@ func NewUpgradeHandler() *UpgradeHandler {
 }
 
 // Handle executes the upgrade command
-func (h *UpgradeHandler) Handle(software string, provider string) {
-	h.BaseHandler.Handle(software, provider)
+func (h *UpgradeHandler) Handle(software string, provider string) error {
+	return h.BaseHandler.Handle(software, provider)
 }
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/manifest"
+	"sai/pkg/output"
+)
+
+// pinningActions are the actions that accept a specific version
+var pinningActions = []string{"install", "upgrade"}
+
+// SupportsVersion reports whether the action accepts a specific version
+func SupportsVersion(action string) bool {
+	for _, a := range pinningActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+type versionKey struct{}
+
+// WithVersion returns a context requesting a specific version of the software
+// from the install and upgrade actions
+func WithVersion(ctx context.Context, version string) context.Context {
+	if version == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, versionKey{}, version)
+}
+
+// RequestedVersion returns the version requested through the context, if any
+func RequestedVersion(ctx context.Context) string {
+	version, _ := ctx.Value(versionKey{}).(string)
+	return version
+}
+
+// checkPinning fails when a version is requested from a provider whose
+// commands for the action cannot install a specific version. Plugins receive
+// the version as an option and decide by themselves.
+func checkPinning(provider, action, version string) error {
+	if version == "" || !SupportsVersion(action) {
+		return nil
+	}
+	if d := providerDefinition(provider); d != nil && !d.Pins(action) {
+		return &errs.VersionPinningError{Provider: provider, Version: version}
+	}
+	return nil
+}
+
+// reportVersion returns the version installed by an install or upgrade
+// action, querying the provider unless the action already reported it, records
+// it in the result and warns when it is not the requested one
+func (h *BaseHandler) reportVersion(ctx context.Context, sw *data.Software, provider string, providerType ProviderType, target string) string {
+	if IsDryRun() || !SupportsVersion(h.Action) {
+		return ""
+	}
+	result := output.FromContext(ctx)
+	version := result.Get("version")
+	if version == "" {
+		installed, queried, err := queryPackage(ctx, sw, provider, providerType, target)
+		if err != nil || !installed || queried == "" {
+			return ""
+		}
+		version = queried
+		result.Set("version", version)
+	}
+
+	fmt.Printf("Installed %s version %s\n", sw.Name, version)
+	if requested := RequestedVersion(ctx); requested != "" && !manifest.VersionMatches(version, requested) {
+		fmt.Fprintf(os.Stderr, "Warning: version %s of %s was requested, but %s is installed\n", requested, sw.Name, version)
+	}
+	return version
+}
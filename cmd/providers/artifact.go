@@ -0,0 +1,22 @@
+package providers
+
+import (
+	"context"
+	artifactprovider "sai/cmd/providers/artifact"
+)
+
+// artifactProviderAdapter adapts an artifact.Provider to providers.Provider
+type artifactProviderAdapter struct {
+	provider artifactprovider.Provider
+}
+
+// Execute implements the Provider interface
+func (a *artifactProviderAdapter) Execute(ctx context.Context, action, software string) error {
+	return a.provider.Execute(ctx, action, software)
+}
+
+// NewArtifactProvider creates the appropriate artifact provider based on the name
+func NewArtifactProvider(name string) Provider {
+	provider := artifactprovider.NewProvider(name)
+	return &artifactProviderAdapter{provider: provider}
+}
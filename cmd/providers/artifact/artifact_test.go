@@ -0,0 +1,760 @@
+package artifact
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// tarGz returns a gzipped tar archive of the files
+func tarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// zipArchive returns a zip archive of the files
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+// sha returns the hex SHA-256 of the content
+func sha(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// serve serves the files from a local HTTP server
+func serve(t *testing.T, files map[string][]byte) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// setup isolates the receipts and the prefix of a test and returns a
+// context installing the software into the prefix
+func setup(t *testing.T, sw *data.Software) (context.Context, string) {
+	t.Setenv("SAI_STATE_DIR", t.TempDir())
+	prefix := t.TempDir()
+	ctx := data.WithSoftware(context.Background(), sw)
+	ctx = definition.WithVariables(ctx, map[string]string{"prefix": prefix})
+	return runner.WithQuiet(ctx), prefix
+}
+
+// TestBinaryInstall tests installing, querying and uninstalling release
+// binaries from a local HTTP server and from file:// URLs
+func TestBinaryInstall(t *testing.T) {
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+	archive := tarGz(t, map[string]string{
+		"tool-1.0/tool":        "tool 1.0",
+		"tool-1.0/tool-helper": "helper 1.0",
+		"tool-1.0/README.md":   "readme",
+	})
+	zipped := zipArchive(t, map[string]string{"tool": "tool 1.0"})
+	plain := []byte("tool 1.0")
+	base := serve(t, map[string][]byte{
+		"/1.0/tool_" + platform + ".tar.gz": archive,
+		"/1.0/tool_" + platform + ".zip":    zipped,
+		"/1.0/tool":                         plain,
+		"/1.0/SHA256SUMS":                   []byte(sha(zipped) + "  tool_" + platform + ".zip\n" + sha(plain) + " *tool\n"),
+	})
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "tool"), plain, 0o644)
+	os.WriteFile(filepath.Join(dir, "tool.sha256"), []byte(sha(plain)+"\n"), 0o644)
+
+	testCases := []struct {
+		name     string
+		binary   data.Binary
+		expected []string
+	}{
+		{"Tarball With Checksum", data.Binary{
+			URL:       base + "/{{.version}}/tool_{{.os}}_{{.arch}}.tar.gz",
+			Version:   "1.0",
+			Checksums: map[string]string{platform: sha(archive)},
+		}, []string{"tool"}},
+		{"Tarball Patterns", data.Binary{
+			URL:       base + "/{{.version}}/tool_{{.os}}_{{.arch}}.tar.gz",
+			Version:   "1.0",
+			Checksums: map[string]string{platform: sha(archive)},
+			Files:     []string{"tool-1.0/tool", "tool-*"},
+		}, []string{"tool", "tool-helper"}},
+		{"Zip With Checksums File", data.Binary{
+			URL:          base + "/{{.version}}/tool_{{.os}}_{{.arch}}.zip",
+			Version:      "1.0",
+			ChecksumsURL: base + "/{{.version}}/SHA256SUMS",
+		}, []string{"tool"}},
+		{"Plain Binary", data.Binary{
+			URL:          base + "/{{.version}}/tool",
+			Version:      "1.0",
+			ChecksumsURL: base + "/{{.version}}/SHA256SUMS",
+		}, []string{"tool"}},
+		{"File URL", data.Binary{
+			URL:          "file://" + filepath.ToSlash(dir) + "/tool",
+			Version:      "1.0",
+			ChecksumsURL: "file://" + filepath.ToSlash(dir) + "/tool.sha256",
+		}, []string{"tool"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, prefix := setup(t, &data.Software{Name: "tool", Binary: &tc.binary})
+			provider := NewBinaryProvider()
+
+			result := output.NewResult("tool", "install")
+			if err := provider.Execute(output.WithResult(ctx, result), ActionInstall, "tool"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Get("version") != "1.0" {
+				t.Errorf("Expected version 1.0, got %q", result.Get("version"))
+			}
+			for _, name := range tc.expected {
+				info, err := os.Stat(filepath.Join(prefix, "bin", name))
+				if err != nil {
+					t.Fatalf("Expected %s to be installed: %v", name, err)
+				}
+				if info.Mode().Perm()&0o111 == 0 {
+					t.Errorf("Expected %s to be executable, got mode %v", name, info.Mode())
+				}
+			}
+			if entries, _ := os.ReadDir(filepath.Join(prefix, "bin")); len(entries) != len(tc.expected) {
+				t.Errorf("Expected only %v to be installed, got %v", tc.expected, entries)
+			}
+
+			status := output.NewResult("tool", "status")
+			if err := provider.Execute(output.WithResult(ctx, status), ActionStatus, "tool"); err != nil {
+				t.Fatalf("Unexpected status error: %v", err)
+			}
+			if status.Get("version") != "1.0" {
+				t.Errorf("Expected status version 1.0, got %q", status.Get("version"))
+			}
+
+			if err := provider.Execute(ctx, ActionUninstall, "tool"); err != nil {
+				t.Fatalf("Unexpected uninstall error: %v", err)
+			}
+			if entries, _ := os.ReadDir(filepath.Join(prefix, "bin")); len(entries) != 0 {
+				t.Errorf("Expected uninstall to remove every file, got %v", entries)
+			}
+			var notFound *errs.SoftwareNotFoundError
+			if err := provider.Execute(ctx, ActionStatus, "tool"); !errors.As(err, &notFound) {
+				t.Errorf("Expected the software not to be found after uninstall, got %v", err)
+			}
+		})
+	}
+}
+
+// TestBinaryVerification tests that artifacts that fail verification, or
+// cannot be verified, are not installed
+func TestBinaryVerification(t *testing.T) {
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+	plain := []byte("tool 1.0")
+	base := serve(t, map[string][]byte{
+		"/1.0/tool":         plain,
+		"/1.0/tool.minisig": []byte("signature"),
+		"/1.0/SHA256SUMS":   []byte(sha([]byte("other")) + "  tool\n"),
+	})
+
+	testCases := []struct {
+		name    string
+		binary  data.Binary
+		version string
+		err     string
+	}{
+		{"Checksum Mismatch", data.Binary{
+			URL: base + "/{{.version}}/tool", Version: "1.0",
+			Checksums: map[string]string{platform: sha([]byte("other"))},
+		}, "", "SHA-256 mismatch"},
+		{"Checksums File Mismatch", data.Binary{
+			URL: base + "/{{.version}}/tool", Version: "1.0", ChecksumsURL: base + "/{{.version}}/SHA256SUMS",
+		}, "", "SHA-256 mismatch"},
+		{"No Checksum For Requested Version", data.Binary{
+			URL: base + "/{{.version}}/tool", Version: "1.0",
+			Checksums: map[string]string{platform: sha(plain)},
+		}, "2.0", "no SHA-256 checksum"},
+		{"Missing Artifact", data.Binary{
+			URL: base + "/{{.version}}/missing", Version: "1.0",
+			Checksums: map[string]string{platform: sha(plain)},
+		}, "", "404 Not Found"},
+		{"Signature Without Key", data.Binary{
+			URL: base + "/{{.version}}/tool", Version: "1.0",
+			Checksums: map[string]string{platform: sha(plain)},
+			Signature: &data.Signature{URL: base + "/{{.version}}/tool.minisig"},
+		}, "", "neither a minisign nor a GPG key"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, prefix := setup(t, &data.Software{Name: "tool", Binary: &tc.binary})
+			if tc.version != "" {
+				ctx = definition.WithVariables(ctx, map[string]string{"version": tc.version})
+			}
+			err := NewBinaryProvider().Execute(ctx, ActionInstall, "tool")
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Expected an error containing %q, got %v", tc.err, err)
+			}
+			if _, err := os.Stat(filepath.Join(prefix, "bin", "tool")); err == nil {
+				t.Error("Expected nothing to be installed")
+			}
+		})
+	}
+}
+
+// TestBinarySignature tests that signatures are checked with minisign and GPG
+func TestBinarySignature(t *testing.T) {
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+	plain := []byte("tool 1.0")
+	sums := []byte(sha(plain) + "  tool\n")
+	base := serve(t, map[string][]byte{
+		"/tool":           plain,
+		"/tool.minisig":   []byte("signature"),
+		"/SHA256SUMS":     sums,
+		"/SHA256SUMS.sig": []byte("signature"),
+		"/key.asc":        []byte("key"),
+	})
+
+	testCases := []struct {
+		name     string
+		binary   data.Binary
+		expected []string
+	}{
+		{"Minisign Artifact", data.Binary{
+			URL: base + "/tool", Version: "1.0",
+			Checksums: map[string]string{platform: sha(plain)},
+			Signature: &data.Signature{URL: base + "/tool.minisig", Minisign: "RWQkey"},
+		}, []string{"minisign -V -P RWQkey -m tool -x tool.minisig"}},
+		{"GPG Checksums", data.Binary{
+			URL: base + "/tool", Version: "1.0", ChecksumsURL: base + "/SHA256SUMS",
+			Signature: &data.Signature{URL: base + "/SHA256SUMS.sig", GPGKey: base + "/key.asc", Checksums: true},
+		}, []string{
+			"gpg --batch --no-default-keyring --keyring keyring.gpg --import key.asc",
+			"gpg --batch --no-default-keyring --keyring keyring.gpg --verify SHA256SUMS.sig SHA256SUMS",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, _ := setup(t, &data.Software{Name: "tool", Binary: &tc.binary})
+			recorder := runner.NewRecorder()
+			if err := NewBinaryProvider().Execute(runner.WithRunner(ctx, recorder), ActionInstall, "tool"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// The commands refer to files of a temporary directory
+			var commands []string
+			for _, cmd := range recorder.Commands() {
+				args := cmd.Argv()
+				for i, arg := range args {
+					if filepath.IsAbs(arg) {
+						args[i] = filepath.Base(arg)
+					}
+				}
+				commands = append(commands, strings.Join(args, " "))
+			}
+			if strings.Join(commands, "\n") != strings.Join(tc.expected, "\n") {
+				t.Errorf("Expected %q, got %q", tc.expected, commands)
+			}
+		})
+	}
+
+	t.Run("Failing Signature", func(t *testing.T) {
+		ctx, prefix := setup(t, &data.Software{Name: "tool", Binary: &testCases[0].binary})
+		recorder := runner.NewRecorder()
+		recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+			return &runner.Result{Command: cmd, ExitCode: 1}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 1}
+		}
+		err := NewBinaryProvider().Execute(runner.WithRunner(ctx, recorder), ActionInstall, "tool")
+		if err == nil || !strings.Contains(err.Error(), "verifying the signature") {
+			t.Errorf("Expected a signature error, got %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(prefix, "bin", "tool")); err == nil {
+			t.Error("Expected nothing to be installed")
+		}
+	})
+}
+
+// TestBinaryUpgrade tests that upgrades replace the files of the previous
+// version and leave files sai did not install alone
+func TestBinaryUpgrade(t *testing.T) {
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+	v1 := tarGz(t, map[string]string{"tool": "tool 1.0", "tool-old": "old"})
+	v2 := tarGz(t, map[string]string{"tool": "tool 2.0"})
+	base := serve(t, map[string][]byte{"/1.0/tool.tar.gz": v1, "/2.0/tool.tar.gz": v2})
+	binary := &data.Binary{
+		URL:       base + "/{{.version}}/tool.tar.gz",
+		Version:   "1.0",
+		Checksums: map[string]string{platform: sha(v1)},
+		Files:     []string{"tool*"},
+	}
+	ctx, prefix := setup(t, &data.Software{Name: "tool", Binary: binary})
+	provider := NewBinaryProvider()
+
+	if err := provider.Execute(ctx, ActionInstall, "tool"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	binary.Version, binary.Checksums[platform] = "2.0", sha(v2)
+	if err := provider.Execute(ctx, ActionUpgrade, "tool"); err != nil {
+		t.Fatalf("Unexpected upgrade error: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(prefix, "bin", "tool")); string(content) != "tool 2.0" {
+		t.Errorf("Expected version 2.0 to be installed, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(prefix, "bin", "tool-old")); err == nil {
+		t.Error("Expected the files no longer shipped to be removed")
+	}
+	if receipt, _ := LoadReceipt("tool"); receipt == nil || receipt.Version != "2.0" || len(receipt.Files) != 1 {
+		t.Errorf("Expected a receipt of version 2.0 with one file, got %+v", receipt)
+	}
+
+	// A file installed by someone else is not overwritten
+	other, _ := setup(t, &data.Software{Name: "tool", Binary: binary})
+	otherPrefix := definition.VariablesFromContext(other)["prefix"]
+	os.MkdirAll(filepath.Join(otherPrefix, "bin"), 0o755)
+	os.WriteFile(filepath.Join(otherPrefix, "bin", "tool"), []byte("mine"), 0o755)
+	if err := provider.Execute(other, ActionInstall, "tool"); err == nil || !strings.Contains(err.Error(), "not installed by sai") {
+		t.Errorf("Expected an error about the existing file, got %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(otherPrefix, "bin", "tool")); string(content) != "mine" {
+		t.Errorf("Expected the existing file to be kept, got %q", content)
+	}
+}
+
+// TestBinaryDryRun tests that dry runs plan the install without downloading
+func TestBinaryDryRun(t *testing.T) {
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	binary := &data.Binary{
+		URL:          "https://example.com/v{{.version}}/tool_{{.os}}_{{.arch}}.tar.gz",
+		Version:      "1.0",
+		ChecksumsURL: "https://example.com/v{{.version}}/SHA256SUMS",
+		Signature:    &data.Signature{URL: "https://example.com/v{{.version}}/SHA256SUMS.minisig", Minisign: "RWQkey", Checksums: true},
+	}
+	ctx, prefix := setup(t, &data.Software{Name: "tool", Binary: binary})
+	recorder := runner.NewRecorder()
+	result := output.NewResult("tool", "install")
+	ctx = output.WithResult(runner.WithRunner(ctx, recorder), result)
+
+	if err := NewBinaryProvider().Execute(ctx, ActionInstall, "tool"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{
+		"download https://example.com/v1.0/tool_" + runtime.GOOS + "_" + runtime.GOARCH + ".tar.gz",
+		"download https://example.com/v1.0/SHA256SUMS",
+		"verify the SHA-256 of tool_" + runtime.GOOS + "_" + runtime.GOARCH + ".tar.gz against SHA256SUMS",
+		"download https://example.com/v1.0/SHA256SUMS.minisig",
+		"minisign -V -P RWQkey -m SHA256SUMS -x SHA256SUMS.minisig",
+		"install tool into " + filepath.Join(prefix, "bin"),
+	}
+	var plan []string
+	for _, step := range result.Plan {
+		plan = append(plan, step.String())
+	}
+	if strings.Join(plan, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected plan %q, got %q", expected, plan)
+	}
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run in dry run mode, got: %v", commands)
+	}
+	if _, err := os.Stat(filepath.Join(prefix, "bin")); err == nil {
+		t.Error("Expected nothing to be installed in dry run mode")
+	}
+}
+
+// stageInstall is a runner response installing the files of a build into
+// the DESTDIR of the install step
+func stageInstall(prefix string, files map[string]string) func(runner.Command) (*runner.Result, error) {
+	return func(cmd runner.Command) (*runner.Result, error) {
+		for _, env := range cmd.Env {
+			destdir, ok := strings.CutPrefix(env, "DESTDIR=")
+			if !ok {
+				continue
+			}
+			for name, content := range files {
+				path := filepath.Join(destdir, prefix, name)
+				os.MkdirAll(filepath.Dir(path), 0o755)
+				os.WriteFile(path, []byte(content), 0o755)
+			}
+		}
+		return &runner.Result{Command: cmd, Stdout: "building\n"}, nil
+	}
+}
+
+// TestSourceBuild tests building sources with each build system, then
+// querying and uninstalling the installed files
+func TestSourceBuild(t *testing.T) {
+	sources := map[string]map[string]string{
+		"autotools": {"pkg-1.0/configure": "#!/bin/sh", "pkg-1.0/Makefile.in": ""},
+		"cmake":     {"pkg-1.0/CMakeLists.txt": "project(pkg)"},
+		"meson":     {"pkg-1.0/meson.build": "project('pkg')"},
+		"make":      {"pkg-1.0/Makefile": "all:"},
+	}
+	files := map[string][]byte{}
+	for name, content := range sources {
+		files["/"+name+"/pkg-1.0.tar.gz"] = tarGz(t, content)
+	}
+	base := serve(t, files)
+
+	testCases := []struct {
+		name     string
+		sources  string
+		source   data.Source
+		expected []string
+	}{
+		{"Autotools", "autotools", data.Source{ConfigureArgs: []string{"--without-docs"}}, []string{
+			"./configure --prefix=$prefix --without-docs",
+			"make -j$jobs",
+			"DESTDIR=$destdir make install DESTDIR=$destdir",
+		}},
+		{"CMake", "cmake", data.Source{}, []string{
+			"cmake -S . -B build -DCMAKE_BUILD_TYPE=Release -DCMAKE_INSTALL_PREFIX=$prefix",
+			"cmake --build build --parallel $jobs",
+			"DESTDIR=$destdir cmake --install build",
+		}},
+		{"Meson", "meson", data.Source{}, []string{
+			"meson setup build --buildtype=release --prefix=$prefix",
+			"meson compile -C build",
+			"DESTDIR=$destdir meson install -C build --destdir $destdir",
+		}},
+		{"Make", "make", data.Source{}, []string{
+			"make -j$jobs PREFIX=$prefix",
+			"DESTDIR=$destdir make install PREFIX=$prefix DESTDIR=$destdir",
+		}},
+		{"Explicit Build System", "make", data.Source{BuildSystem: "autotools"}, []string{
+			"./configure --prefix=$prefix",
+			"make -j$jobs",
+			"DESTDIR=$destdir make install DESTDIR=$destdir",
+		}},
+		{"Custom Steps", "make", data.Source{Steps: [][]string{
+			{"./bootstrap", "{{.software}}-{{.version}}"},
+			{"make", "install", "prefix={{.prefix}}"},
+		}}, []string{
+			"DESTDIR=$destdir ./bootstrap pkg-1.0",
+			"DESTDIR=$destdir make install prefix=$prefix",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.source.URL = base + "/" + tc.sources + "/pkg-{{.version}}.tar.gz"
+			tc.source.Version = "1.0"
+			tc.source.SHA256 = sha(files["/"+tc.sources+"/pkg-1.0.tar.gz"])
+			ctx, prefix := setup(t, &data.Software{Name: "pkg", Source: &tc.source})
+			buildDir := filepath.Join(BuildDir(), "pkg-1.0")
+			recorder := runner.NewRecorder()
+			recorder.Respond = stageInstall(prefix, map[string]string{"bin/pkg": "pkg 1.0", "share/pkg/data": "data"})
+			ctx = runner.WithRunner(ctx, recorder)
+			provider := NewSourceProvider()
+
+			result := output.NewResult("pkg", "install")
+			if err := provider.Execute(output.WithResult(ctx, result), ActionInstall, "pkg"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Get("version") != "1.0" {
+				t.Errorf("Expected version 1.0, got %q", result.Get("version"))
+			}
+
+			vars := strings.NewReplacer("$prefix", prefix, "$destdir", filepath.Join(buildDir, "destdir"),
+				"$jobs", strconv.Itoa(runtime.NumCPU()))
+			var commands, expected []string
+			for _, cmd := range recorder.Commands() {
+				commands = append(commands, strings.TrimSpace(strings.Join(cmd.Env, " ")+" "+strings.Join(cmd.Argv(), " ")))
+				if cmd.Dir != filepath.Join(buildDir, "src", "pkg-1.0") {
+					t.Errorf("Expected %s to run in the top-level directory of the sources, got %s", cmd, cmd.Dir)
+				}
+			}
+			for _, line := range tc.expected {
+				expected = append(expected, vars.Replace(line))
+			}
+			if strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+				t.Errorf("Expected commands %q, got %q", expected, commands)
+			}
+
+			if content, _ := os.ReadFile(filepath.Join(prefix, "share", "pkg", "data")); string(content) != "data" {
+				t.Errorf("Expected the staged files to be installed under the prefix, got %q", content)
+			}
+			if _, err := os.Stat(buildDir); err == nil {
+				t.Error("Expected the build directory to be removed after a successful build")
+			}
+			if log, _ := os.ReadFile(filepath.Join(LogDir(), "pkg-1.0.log")); !strings.Contains(string(log), "building") {
+				t.Errorf("Expected the build log to be kept, got %q", log)
+			}
+
+			if err := provider.Execute(ctx, ActionStatus, "pkg"); err != nil {
+				t.Fatalf("Unexpected status error: %v", err)
+			}
+			if err := provider.Execute(ctx, ActionUninstall, "pkg"); err != nil {
+				t.Fatalf("Unexpected uninstall error: %v", err)
+			}
+			if entries, _ := os.ReadDir(prefix); len(entries) != 0 {
+				t.Errorf("Expected uninstall to remove every file and directory, got %v", entries)
+			}
+		})
+	}
+}
+
+// TestSourceFailure tests that failed builds keep the build directory and
+// the log, and install nothing
+func TestSourceFailure(t *testing.T) {
+	tarball := tarGz(t, map[string]string{"pkg-1.0/Makefile": "all:"})
+	empty := tarGz(t, map[string]string{"pkg-1.0/README": "readme"})
+	base := serve(t, map[string][]byte{"/pkg-1.0.tar.gz": tarball, "/empty-1.0.tar.gz": empty})
+
+	testCases := []struct {
+		name    string
+		source  data.Source
+		respond func(runner.Command) (*runner.Result, error)
+		err     string
+	}{
+		{"Failing Step", data.Source{URL: base + "/pkg-{{.version}}.tar.gz", SHA256: sha(tarball)},
+			func(cmd runner.Command) (*runner.Result, error) {
+				return &runner.Result{Command: cmd, ExitCode: 2, Stderr: "error: missing header\n"},
+					&errs.CommandFailedError{Command: cmd.String(), ExitCode: 2}
+			}, "building pkg failed, see"},
+		{"Nothing Installed", data.Source{URL: base + "/pkg-{{.version}}.tar.gz", SHA256: sha(tarball)},
+			nil, "installed no files"},
+		{"Unknown Build System", data.Source{URL: base + "/empty-{{.version}}.tar.gz", SHA256: sha(empty)},
+			nil, "no configure script"},
+		{"Unsupported Build System", data.Source{URL: base + "/pkg-{{.version}}.tar.gz", SHA256: sha(tarball), BuildSystem: "bazel"},
+			nil, "unsupported build system"},
+		{"Checksum Mismatch", data.Source{URL: base + "/pkg-{{.version}}.tar.gz", SHA256: sha(empty)},
+			nil, "SHA-256 mismatch"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.source.Version = "1.0"
+			ctx, prefix := setup(t, &data.Software{Name: "pkg", Source: &tc.source})
+			recorder := runner.NewRecorder()
+			recorder.Respond = tc.respond
+			err := NewSourceProvider().Execute(runner.WithRunner(ctx, recorder), ActionInstall, "pkg")
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("Expected an error containing %q, got %v", tc.err, err)
+			}
+			if entries, _ := os.ReadDir(prefix); len(entries) != 0 {
+				t.Errorf("Expected nothing to be installed, got %v", entries)
+			}
+			if receipt, _ := LoadReceipt("pkg"); receipt != nil {
+				t.Errorf("Expected no receipt, got %+v", receipt)
+			}
+			if _, err := os.Stat(filepath.Join(BuildDir(), "pkg-1.0")); err != nil {
+				t.Errorf("Expected the build directory to be kept: %v", err)
+			}
+		})
+	}
+
+	t.Run("Log", func(t *testing.T) {
+		source := data.Source{URL: base + "/pkg-{{.version}}.tar.gz", Version: "1.0", SHA256: sha(tarball)}
+		ctx, _ := setup(t, &data.Software{Name: "pkg", Source: &source})
+		recorder := runner.NewRecorder()
+		recorder.Respond = testCases[0].respond
+		NewSourceProvider().Execute(runner.WithRunner(ctx, recorder), ActionInstall, "pkg")
+		log, _ := os.ReadFile(filepath.Join(LogDir(), "pkg-1.0.log"))
+		if !strings.Contains(string(log), "$ make -j") || !strings.Contains(string(log), "error: missing header") {
+			t.Errorf("Expected the log to hold the command and its output, got %q", log)
+		}
+	})
+}
+
+// TestSourceDryRun tests that dry runs plan the build without downloading
+func TestSourceDryRun(t *testing.T) {
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	source := &data.Source{
+		URL:         "https://example.com/pkg-{{.version}}.tar.gz",
+		Version:     "1.0",
+		SHA256:      sha([]byte("pkg")),
+		BuildSystem: "meson",
+	}
+	ctx, prefix := setup(t, &data.Software{Name: "pkg", Source: source})
+	recorder := runner.NewRecorder()
+	result := output.NewResult("pkg", "install")
+	ctx = output.WithResult(runner.WithRunner(ctx, recorder), result)
+
+	if err := NewSourceProvider().Execute(ctx, ActionInstall, "pkg"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	buildDir := filepath.Join(BuildDir(), "pkg-1.0")
+	destdir := filepath.Join(buildDir, "destdir")
+	expected := []string{
+		"download https://example.com/pkg-1.0.tar.gz",
+		"verify the SHA-256 of pkg-1.0.tar.gz is " + sha([]byte("pkg")),
+		"unpack pkg-1.0.tar.gz into " + filepath.Join(buildDir, "src"),
+		"meson setup build --buildtype=release --prefix=" + prefix,
+		"meson compile -C build",
+		"DESTDIR=" + destdir + " meson install -C build --destdir " + destdir,
+		"install the files of " + destdir + " under " + prefix,
+	}
+	var plan []string
+	for _, step := range result.Plan {
+		plan = append(plan, step.String())
+	}
+	if strings.Join(plan, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected plan %q, got %q", expected, plan)
+	}
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run in dry run mode, got: %v", commands)
+	}
+	if _, err := os.Stat(buildDir); err == nil {
+		t.Error("Expected no build directory in dry run mode")
+	}
+}
+
+// TestUnpackContained tests that unpacking an archive never writes outside of
+// its directory, whether through the names of its entries or through links
+func TestUnpackContained(t *testing.T) {
+	type entry struct {
+		name, link, content string
+	}
+	testCases := []struct {
+		name    string
+		entries []entry
+		fails   bool
+	}{
+		{"Regular Layout", []entry{{"pkg/", "", ""}, {"pkg/configure", "", "#!/bin/sh\n"}, {"pkg/link", "configure", ""}, {"pkg/sub/up", "../configure", ""}}, false},
+		{"Dot Dot Entry", []entry{{"pkg/../../victim", "", "pwned\n"}}, true},
+		{"Absolute Link", []entry{{"pkg/link", "/tmp/victim", ""}}, true},
+		{"Escaping Link", []entry{{"pkg/link", "../../victim", ""}}, true},
+		{"Link Through Link", []entry{{"pkg/sub/root", "..", ""}, {"pkg/sub/escape", "root/../../victim", ""}}, true},
+		{"Write Through Link Directory", []entry{{"pkg/sub/out", "../../../..", ""}, {"pkg/sub/out/victim", "", "pwned\n"}}, true},
+		{"Outside Link Then File", []entry{{"pkg/link", "../../../victim", ""}, {"pkg/link", "", "pwned\n"}}, true},
+		{"Link Then File", []entry{{"pkg/configure", "", "#!/bin/sh\n"}, {"pkg/link", "configure", ""}, {"pkg/link", "", "replaced\n"}}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outside := t.TempDir()
+			victim := filepath.Join(outside, "victim")
+			if err := os.WriteFile(victim, []byte("safe\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join(outside, "build", "src")
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, e := range tc.entries {
+				header := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.content)), Typeflag: tar.TypeReg}
+				switch {
+				case strings.HasSuffix(e.name, "/"):
+					header.Typeflag, header.Mode = tar.TypeDir, 0o755
+				case e.link != "":
+					header.Typeflag, header.Linkname = tar.TypeSymlink, e.link
+				}
+				if err := tw.WriteHeader(header); err != nil {
+					t.Fatal(err)
+				}
+				tw.Write([]byte(e.content))
+			}
+			tw.Close()
+			archive := filepath.Join(outside, "pkg.tar")
+			if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := unpack(archive, formatTar, dir)
+			if tc.fails && err == nil {
+				t.Error("Expected the archive to be refused")
+			}
+			if !tc.fails && err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if content, _ := os.ReadFile(victim); string(content) != "safe\n" {
+				t.Errorf("Expected the file outside of the archive to be untouched, got %q", content)
+			}
+			if content, _ := os.ReadFile(filepath.Join(dir, "pkg", "configure")); !tc.fails && string(content) != "#!/bin/sh\n" {
+				t.Errorf("Expected the configure script to be extracted, got %q", content)
+			}
+		})
+	}
+}
+
+// TestEntryPath tests where archive entries are extracted
+func TestEntryPath(t *testing.T) {
+	outside := t.TempDir()
+	dir := filepath.Join(outside, "src")
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A link left by an earlier entry, or by a build step
+	if err := os.Symlink(outside, filepath.Join(dir, "pkg", "out")); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		entry    string
+		expected string
+		fails    bool
+	}{
+		{"Regular", "pkg/configure", filepath.Join(dir, "pkg", "configure"), false},
+		{"Leading Dot", "./pkg/configure", filepath.Join(dir, "pkg", "configure"), false},
+		{"Absolute", "/pkg/configure", filepath.Join(dir, "pkg", "configure"), false},
+		{"Root", "./", "", false},
+		{"Dot Dot", "pkg/../../victim", "", true},
+		{"Backslash Dot Dot", "pkg\\..\\..\\victim", "", true},
+		{"Through Link Directory", "pkg/out/victim", "", true},
+		{"Deep Through Link Directory", "pkg/out/sub/victim", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dest, err := entryPath(dir, tc.entry)
+			if tc.fails {
+				if err == nil {
+					t.Errorf("Expected %s to be refused, got %s", tc.entry, dest)
+				}
+				return
+			}
+			if err != nil || dest != tc.expected {
+				t.Errorf("Expected %q, got %q, %v", tc.expected, dest, err)
+			}
+		})
+	}
+}
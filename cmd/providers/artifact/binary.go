@@ -0,0 +1,171 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+)
+
+// BinaryProvider installs the release artifacts described by the binary
+// section of the saidata into the bin directory of the prefix
+type BinaryProvider struct {
+	BaseProvider
+}
+
+// Execute performs an action on the software. Install and upgrade download,
+// verify and unpack the artifact; uninstall removes the files the receipt of
+// the software lists.
+func (p *BinaryProvider) Execute(ctx context.Context, action, software string) error {
+	if !IsValidAction(action) {
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	switch action {
+	case ActionStatus:
+		return p.status(ctx, software)
+	case ActionUninstall:
+		return p.uninstall(ctx, software)
+	}
+
+	sw := data.FromContext(ctx)
+	if sw == nil || sw.Binary == nil || sw.Binary.URL == "" {
+		return &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+	}
+	src, err := p.source(ctx, software, sw.Binary)
+	if err != nil {
+		return err
+	}
+	if action == ActionInfo {
+		p.info(software, src)
+		return nil
+	}
+	return p.install(ctx, action, software, sw.Binary, src)
+}
+
+// platformVars returns the os and arch placeholders of the URL templates,
+// renamed as the project publishes its artifacts
+func platformVars(b *data.Binary) map[string]string {
+	vars := map[string]string{"os": runtime.GOOS, "arch": runtime.GOARCH}
+	if name, ok := b.OSNames[runtime.GOOS]; ok {
+		vars["os"] = name
+	}
+	if name, ok := b.ArchNames[runtime.GOARCH]; ok {
+		vars["arch"] = name
+	}
+	return vars
+}
+
+// source renders the URLs of the artifact of the platform
+func (p *BinaryProvider) source(ctx context.Context, software string, b *data.Binary) (*Source, error) {
+	return p.resolve(ctx, software, spec{
+		Section:      "binary",
+		URL:          b.URL,
+		ChecksumsURL: b.ChecksumsURL,
+		Version:      b.Version,
+		Checksum:     b.Checksums[runtime.GOOS+"_"+runtime.GOARCH],
+		Signature:    b.Signature,
+	}, platformVars(b))
+}
+
+// files returns the patterns of the files to install from an archive
+func files(software string, b *data.Binary) []string {
+	if len(b.Files) > 0 {
+		return b.Files
+	}
+	return []string{software}
+}
+
+// install downloads, verifies and unpacks the artifact into the bin
+// directory of the prefix, replacing the files of the previous version
+func (p *BinaryProvider) install(ctx context.Context, action, software string, b *data.Binary, src *Source) error {
+	binDir := filepath.Join(p.Prefix(ctx), "bin")
+	patterns := files(software, b)
+	if p.IsDryRun() {
+		p.Plan(ctx, fmt.Sprintf("download %s", src.URL), false, false)
+		p.planVerification(ctx, src)
+		p.Plan(ctx, fmt.Sprintf("install %s into %s", strings.Join(patterns, ", "), binDir), p.NeedsRoot(ctx), false)
+		return nil
+	}
+
+	previous, err := p.previous(software)
+	if err != nil {
+		return err
+	}
+	if action == ActionUpgrade && previous != nil && previous.Version == src.Version {
+		fmt.Printf("%s %s is already installed\n", software, src.Version)
+		output.FromContext(ctx).Set("version", src.Version)
+		return nil
+	}
+
+	tmp, err := os.MkdirTemp("", "sai-binary-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	fmt.Printf("Downloading %s\n", src.URL)
+	artifact, sum, err := download(ctx, src.URL, tmp)
+	if err != nil {
+		return err
+	}
+	if err := p.verify(ctx, src, artifact, sum, tmp); err != nil {
+		return err
+	}
+
+	unpacked := filepath.Join(tmp, "unpacked")
+	if err := os.Mkdir(unpacked, 0o755); err != nil {
+		return err
+	}
+	var names []string
+	if format := archiveFormat(filepath.Base(artifact)); format == formatPlain {
+		name := path.Base(patterns[0])
+		if err := os.Rename(artifact, filepath.Join(unpacked, name)); err != nil {
+			return err
+		}
+		names = []string{name}
+	} else if names, err = extract(artifact, format, patterns, unpacked); err != nil {
+		return err
+	}
+
+	receipt := &Receipt{
+		Software:    software,
+		Provider:    p.Name,
+		Version:     src.Version,
+		URL:         src.URL,
+		SHA256:      sum,
+		Prefix:      p.Prefix(ctx),
+		InstalledAt: time.Now().UTC(),
+	}
+	var files []staged
+	for _, name := range names {
+		files = append(files, staged{Src: filepath.Join(unpacked, name), Dest: filepath.Join(binDir, name), Mode: 0o755})
+		receipt.Files = append(receipt.Files, filepath.Join(binDir, name))
+	}
+	created, err := installFiles(files, previous)
+	if previous != nil {
+		created = union(previous.Dirs, created)
+	}
+	receipt.Dirs = created
+	if err != nil {
+		return err
+	}
+	if err := removeStale(previous, receipt); err != nil {
+		return err
+	}
+	if err := receipt.Save(); err != nil {
+		return err
+	}
+
+	output.FromContext(ctx).Set("version", src.Version)
+	fmt.Printf("Installed %s into %s\n", strings.Join(names, ", "), binDir)
+	return nil
+}
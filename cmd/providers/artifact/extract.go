@@ -0,0 +1,369 @@
+package artifact
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Archive formats, told apart by the extension of the artifact
+const (
+	formatTarGz = "tar.gz"
+	formatTar   = "tar"
+	formatZip   = "zip"
+	formatPlain = ""
+)
+
+// archiveFormat returns the archive format of an artifact, or formatPlain
+// for a single file
+func archiveFormat(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return formatTarGz
+	case strings.HasSuffix(lower, ".tar"):
+		return formatTar
+	case strings.HasSuffix(lower, ".zip"):
+		return formatZip
+	default:
+		return formatPlain
+	}
+}
+
+// matchFile reports whether an archive entry matches one of the patterns,
+// either by its full path or by its base name
+func matchFile(patterns []string, name string) bool {
+	name = strings.TrimPrefix(path.Clean(name), "./")
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// extract writes the regular files of an archive matching the patterns into
+// the directory, flattened to their base names, and returns their names
+func extract(archive, format string, patterns []string, dir string) ([]string, error) {
+	var names []string
+	write := func(name string, r io.Reader) error {
+		base := path.Base(name)
+		for _, existing := range names {
+			if existing == base {
+				return fmt.Errorf("%s matches several files named %s", filepath.Base(archive), base)
+			}
+		}
+		file, err := os.OpenFile(filepath.Join(dir, base), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, r); err != nil {
+			file.Close()
+			return err
+		}
+		names = append(names, base)
+		return file.Close()
+	}
+
+	var err error
+	switch format {
+	case formatTarGz, formatTar:
+		err = extractTar(archive, format == formatTarGz, patterns, write)
+	case formatZip:
+		err = extractZip(archive, patterns, write)
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extracting %s: %w", filepath.Base(archive), err)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no file matching %s in %s", strings.Join(patterns, ", "), filepath.Base(archive))
+	}
+	return names, nil
+}
+
+// extractTar passes the matching regular files of a tar archive to write
+func extractTar(archive string, gzipped bool, patterns []string, write func(string, io.Reader) error) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if gzipped {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || !matchFile(patterns, header.Name) {
+			continue
+		}
+		if err := write(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip passes the matching regular files of a zip archive to write
+func extractZip(archive string, patterns []string, write func(string, io.Reader) error) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, entry := range zr.File {
+		if !entry.Mode().IsRegular() || !matchFile(patterns, entry.Name) {
+			continue
+		}
+		r, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		err = write(entry.Name, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unpack extracts a whole archive into the directory, keeping its layout,
+// and returns the directory holding the sources: the single top-level
+// directory of the archive when it has one
+func unpack(archive, format, dir string) (string, error) {
+	var err error
+	switch format {
+	case formatTarGz, formatTar:
+		err = unpackTar(archive, format == formatTarGz, dir)
+	case formatZip:
+		err = unpackZip(archive, dir)
+	default:
+		return "", fmt.Errorf("%s is not an archive", filepath.Base(archive))
+	}
+	if err != nil {
+		return "", fmt.Errorf("unpacking %s: %w", filepath.Base(archive), err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
+// entryPath returns where an archive entry is extracted, refusing entries
+// that would land outside of the directory, either through their name or
+// through a symbolic link extracted before them
+func entryPath(dir, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("entry %s points outside of the archive", name)
+		}
+	}
+	clean := path.Clean("/" + name)
+	if clean == "/" {
+		return "", nil
+	}
+	dest := filepath.Join(dir, filepath.FromSlash(clean))
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	for parent := filepath.Dir(dest); parent != dir; parent = filepath.Dir(parent) {
+		resolved, err := filepath.EvalSymlinks(parent)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if rel, err := filepath.Rel(root, resolved); err != nil || strings.HasPrefix(rel, "..") {
+			return "", fmt.Errorf("entry %s points outside of the archive", name)
+		}
+		break
+	}
+	return dest, nil
+}
+
+// checkLink refuses symbolic links whose target is absolute or lies outside
+// of the directory. Only leading .. are accepted in targets, and they are
+// resolved from the actual parent of the link, so that no chain of links
+// escapes the directory either.
+func checkLink(dir, dest, target string) error {
+	parts := strings.Split(strings.ReplaceAll(target, "\\", "/"), "/")
+	up := 0
+	for i, part := range parts {
+		if part != ".." {
+			continue
+		}
+		if i != up {
+			return fmt.Errorf("link %s to %s points outside of the archive", filepath.Base(dest), target)
+		}
+		up++
+	}
+	if path.IsAbs(target) || filepath.IsAbs(target) {
+		return fmt.Errorf("link %s to %s points outside of the archive", filepath.Base(dest), target)
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(dest))
+	if err != nil {
+		return err
+	}
+	resolved := filepath.Join(append([]string{parent}, parts[:up]...)...)
+	if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("link %s to %s points outside of the archive", filepath.Base(dest), target)
+	}
+	return nil
+}
+
+// unpackTar extracts the directories, regular files and symbolic links of a
+// tar archive
+func unpackTar(archive string, gzipped bool, dir string) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if gzipped {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		dest, err := entryPath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		if dest == "" {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(dest, 0o755)
+		case tar.TypeReg:
+			err = writeEntry(dest, tr, os.FileMode(header.Mode).Perm())
+		case tar.TypeSymlink:
+			err = writeLink(dir, dest, header.Linkname)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// unpackZip extracts the directories and regular files of a zip archive
+func unpackZip(archive, dir string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, entry := range zr.File {
+		dest, err := entryPath(dir, entry.Name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case dest == "":
+		case entry.Mode().IsDir():
+			err = os.MkdirAll(dest, 0o755)
+		case entry.Mode().IsRegular():
+			var r io.ReadCloser
+			if r, err = entry.Open(); err == nil {
+				err = writeEntry(dest, r, entry.Mode().Perm())
+				r.Close()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceEntry prepares the destination of an archive entry, removing an
+// entry of the same name extracted before it rather than writing through it
+func replaceEntry(dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dest); err == nil {
+		return os.Remove(dest)
+	}
+	return nil
+}
+
+// writeLink creates a symbolic link of an archive extracted into dir
+func writeLink(dir, dest, target string) error {
+	if err := replaceEntry(dest); err != nil {
+		return err
+	}
+	if err := checkLink(dir, dest, target); err != nil {
+		return err
+	}
+	return os.Symlink(target, dest)
+}
+
+// writeEntry writes the content of an archive entry to a new file, never
+// following a link of the same name
+func writeEntry(dest string, r io.Reader, mode os.FileMode) error {
+	if err := replaceEntry(dest); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode|0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
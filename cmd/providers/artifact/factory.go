@@ -0,0 +1,22 @@
+package artifact
+
+// NewBinaryProvider creates a new release binary provider
+func NewBinaryProvider() *BinaryProvider {
+	return &BinaryProvider{BaseProvider: BaseProvider{Name: "binary"}}
+}
+
+// NewSourceProvider creates a new source build provider
+func NewSourceProvider() *SourceProvider {
+	return &SourceProvider{BaseProvider: BaseProvider{Name: "source"}}
+}
+
+// NewProvider creates the artifact provider with the given name
+func NewProvider(name string) Provider {
+	switch name {
+	case "source":
+		return NewSourceProvider()
+	default:
+		// Return binary provider as default
+		return NewBinaryProvider()
+	}
+}
@@ -0,0 +1,100 @@
+package artifact
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// client downloads artifacts over HTTP(S) and from file:// URLs, which serve
+// mirrors and offline installs
+var client = func() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	return &http.Client{Transport: transport}
+}()
+
+// renderURL renders a URL template with the given variables
+func renderURL(text string, vars map[string]string) (string, error) {
+	tmpl, err := template.New("url").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL template %q: %w", text, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("invalid URL template %q: %w", text, err)
+	}
+	return buf.String(), nil
+}
+
+// fileName returns the last element of the path of a URL
+func fileName(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return path.Base(u.Path)
+	}
+	return path.Base(rawURL)
+}
+
+// download saves the content of a URL into a file of the directory, named
+// after the URL, and returns its path and SHA-256
+func download(ctx context.Context, rawURL, dir string) (string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("downloading %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("downloading %s: %s", rawURL, resp.Status)
+	}
+
+	dest := filepath.Join(dir, fileName(rawURL))
+	file, err := os.Create(dest)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), resp.Body); err != nil {
+		return "", "", fmt.Errorf("downloading %s: %w", rawURL, err)
+	}
+	return dest, hex.EncodeToString(hash.Sum(nil)), file.Close()
+}
+
+// findChecksum looks the checksum of a file up in the content of a checksums
+// file in the sha256sum format. A file holding a single checksum, as
+// published next to some artifacts, applies to any file.
+func findChecksum(content []byte, name string) string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 1 && len(lines) == 1 {
+			return strings.ToLower(fields[0])
+		}
+		// Binary mode entries are prefixed with an asterisk
+		if len(fields) == 2 && path.Base(strings.TrimPrefix(fields[1], "*")) == name {
+			return strings.ToLower(fields[0])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,288 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// spec describes an artifact of the saidata before its URLs are rendered
+type spec struct {
+	// Section is the saidata section describing the artifact
+	Section      string
+	URL          string
+	ChecksumsURL string
+	// Version is the version of the saidata, whose artifact has Checksum
+	Version   string
+	Checksum  string
+	Signature *data.Signature
+}
+
+// resolve renders the URLs of the requested version of an artifact, or of
+// the version of the saidata when none is requested, with the variables
+func (p *BaseProvider) resolve(ctx context.Context, software string, s spec, vars map[string]string) (*Source, error) {
+	vars["software"] = software
+	vars["version"] = definition.VariablesFromContext(ctx)["version"]
+	if vars["version"] == "" {
+		vars["version"] = s.Version
+	}
+	if vars["version"] == "" {
+		return nil, fmt.Errorf("no version of %s to install: set %s.version in its saidata or request one", software, s.Section)
+	}
+
+	src := &Source{Version: vars["version"], Signature: s.Signature}
+	var err error
+	if src.URL, err = renderURL(s.URL, vars); err != nil {
+		return nil, err
+	}
+	if s.ChecksumsURL != "" {
+		if src.ChecksumsURL, err = renderURL(s.ChecksumsURL, vars); err != nil {
+			return nil, err
+		}
+	}
+	// The checksum of the saidata is the one of the artifact of its version
+	if src.Version == s.Version {
+		src.Checksum = s.Checksum
+	}
+	if sig := s.Signature; sig != nil {
+		if sig.Minisign == "" && sig.GPGKey == "" {
+			return nil, fmt.Errorf("the signature of %s has neither a minisign nor a GPG key", software)
+		}
+		// A signed checksums file is trusted over the checksum of the saidata
+		if sig.Checksums {
+			if src.ChecksumsURL == "" {
+				return nil, fmt.Errorf("the signature of %s covers a checksums file, but %s.checksums_url is not set", software, s.Section)
+			}
+			src.Checksum = ""
+		}
+		if src.SignatureURL, err = renderURL(sig.URL, vars); err != nil {
+			return nil, err
+		}
+	}
+	if !src.Verified() {
+		return nil, fmt.Errorf("no SHA-256 checksum of %s version %s: add it to the saidata or set %s.checksums_url",
+			software, src.Version, s.Section)
+	}
+	return src, nil
+}
+
+// previous returns the receipt of the software, failing when another
+// artifact provider installed it
+func (p *BaseProvider) previous(software string) (*Receipt, error) {
+	receipt, err := LoadReceipt(software)
+	if err != nil {
+		return nil, err
+	}
+	if receipt != nil && receipt.Provider != p.Name {
+		return nil, fmt.Errorf("%s was installed by the %s provider", software, receipt.Provider)
+	}
+	return receipt, nil
+}
+
+// staged is a file ready to be installed
+type staged struct {
+	Src  string
+	Dest string
+	Mode os.FileMode
+}
+
+// installFiles copies the staged files to their destination and returns the
+// directories it created. Files that exist without belonging to the previous
+// install are left alone.
+func installFiles(files []staged, previous *Receipt) ([]string, error) {
+	for _, file := range files {
+		if _, err := os.Lstat(file.Dest); err == nil && !previous.Owns(file.Dest) {
+			return nil, fmt.Errorf("%s already exists and was not installed by sai", file.Dest)
+		}
+	}
+	var created []string
+	for _, file := range files {
+		dirs, err := mkdirs(filepath.Dir(file.Dest))
+		created = append(created, dirs...)
+		if err != nil {
+			return created, err
+		}
+		if file.Mode&os.ModeSymlink != 0 {
+			err = copySymlink(file.Src, file.Dest)
+		} else {
+			err = copyFile(file.Src, file.Dest, file.Mode.Perm())
+		}
+		if err != nil {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// mkdirs creates a directory and its missing parents, returning the
+// directories it created
+func mkdirs(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Lstat(d); err == nil || d == filepath.Dir(d) {
+			break
+		}
+		missing = append([]string{d}, missing...)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// copyFile copies a file through a temporary file of the destination
+// directory, so that a running executable is replaced rather than rewritten
+func copyFile(src, dest string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(out.Name())
+		return err
+	}
+	if err := os.Chmod(out.Name(), mode); err != nil {
+		os.Remove(out.Name())
+		return err
+	}
+	return os.Rename(out.Name(), dest)
+}
+
+// copySymlink recreates a symbolic link, replacing the destination
+func copySymlink(src, dest string) error {
+	target, err := os.Readlink(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dest); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.Symlink(target, dest)
+}
+
+// removeFiles removes files, then the directories that are left empty
+func removeFiles(files, dirs []string, verbose bool) error {
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if verbose {
+			fmt.Printf("Removed %s\n", file)
+		}
+	}
+	// Deepest directories first; those still holding files are kept
+	sorted := append([]string(nil), dirs...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, dir := range sorted {
+		os.Remove(dir)
+	}
+	return nil
+}
+
+// removeStale removes the files of the previous install the new one no
+// longer ships
+func removeStale(previous, current *Receipt) error {
+	if previous == nil {
+		return nil
+	}
+	var stale []string
+	for _, file := range previous.Files {
+		if !current.Owns(file) {
+			stale = append(stale, file)
+		}
+	}
+	return removeFiles(stale, nil, false)
+}
+
+// uninstall removes the files listed in the receipt of the software, and the
+// directories created to hold them
+func (p *BaseProvider) uninstall(ctx context.Context, software string) error {
+	receipt, err := LoadReceipt(software)
+	if err != nil {
+		return err
+	}
+	if receipt == nil || receipt.Provider != p.Name {
+		return &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+	}
+	if p.IsDryRun() {
+		for _, file := range receipt.Files {
+			p.Plan(ctx, fmt.Sprintf("remove %s", file), p.NeedsRoot(ctx), true)
+		}
+		return nil
+	}
+	if err := removeFiles(receipt.Files, receipt.Dirs, !runner.IsQuiet(ctx)); err != nil {
+		return err
+	}
+	return removeReceipt(software)
+}
+
+// status reports the version recorded in the receipt of the software. Files
+// that were removed behind the back of sai make it not installed.
+func (p *BaseProvider) status(ctx context.Context, software string) error {
+	receipt, err := LoadReceipt(software)
+	if err != nil {
+		return err
+	}
+	if receipt == nil || receipt.Provider != p.Name {
+		return &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+	}
+	for _, file := range receipt.Files {
+		if _, err := os.Lstat(file); err != nil {
+			return &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+		}
+	}
+	output.FromContext(ctx).Set("version", receipt.Version)
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("%s %s is installed in %s (%d files)\n", software, receipt.Version, receipt.Prefix, len(receipt.Files))
+	}
+	return nil
+}
+
+// info prints where the artifact of the software comes from
+func (p *BaseProvider) info(software string, src *Source) {
+	fmt.Printf("%s %s\n", software, src.Version)
+	fmt.Printf("  URL:       %s\n", src.URL)
+	if src.Checksum != "" {
+		fmt.Printf("  SHA-256:   %s\n", src.Checksum)
+	} else {
+		fmt.Printf("  Checksums: %s\n", src.ChecksumsURL)
+	}
+	if src.Signature != nil {
+		fmt.Printf("  Signature: %s\n", src.SignatureURL)
+	}
+}
+
+// union returns the values of both lists, without duplicates
+func union(a, b []string) []string {
+	list := append([]string(nil), a...)
+	for _, value := range b {
+		found := false
+		for _, existing := range list {
+			found = found || existing == value
+		}
+		if !found {
+			list = append(list, value)
+		}
+	}
+	return list
+}
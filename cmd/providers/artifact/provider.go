@@ -0,0 +1,147 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// Provider interface defines methods for artifact provider implementations
+type Provider interface {
+	Execute(ctx context.Context, action, software string) error
+	GetInstaller() string
+	IsDryRun() bool
+}
+
+// Supported artifact actions
+const (
+	ActionInstall   = "install"
+	ActionUninstall = "uninstall"
+	ActionStatus    = "status"
+	ActionUpgrade   = "upgrade"
+	ActionInfo      = "info"
+)
+
+// AllActions contains all supported artifact actions
+var AllActions = []string{
+	ActionInstall,
+	ActionUninstall,
+	ActionStatus,
+	ActionUpgrade,
+	ActionInfo,
+}
+
+// IsValidAction checks if the given action is supported by artifact providers
+func IsValidAction(action string) bool {
+	for _, a := range AllActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Tools lists, for each artifact provider, the executables it needs. Release
+// binaries are downloaded and unpacked by sai itself; sources need one of
+// the build tools.
+var Tools = map[string][]string{
+	"binary": nil,
+	"source": {"make", "cmake", "meson"},
+}
+
+// Global variable to track dry run mode
+var isDryRunMode = false
+
+// SetDryRun sets the dry run mode for artifact providers
+func SetDryRun(enabled bool) {
+	isDryRunMode = enabled
+}
+
+// BaseProvider holds what artifact providers share: the dry run mode, the
+// install prefix and the execution plan
+type BaseProvider struct {
+	Name string
+}
+
+// GetInstaller returns the artifact provider name
+func (p *BaseProvider) GetInstaller() string {
+	return p.Name
+}
+
+// IsDryRun checks if dry run mode is enabled
+func (p *BaseProvider) IsDryRun() bool {
+	return isDryRunMode
+}
+
+// Prefix returns the directory artifacts are installed under: the prefix
+// variable when given, /usr/local for root or with the system variable, and
+// ~/.local otherwise
+func (p *BaseProvider) Prefix(ctx context.Context) string {
+	vars := definition.VariablesFromContext(ctx)
+	if prefix := vars["prefix"]; prefix != "" {
+		return prefix
+	}
+	if vars["system"] == "true" || os.Geteuid() == 0 {
+		return "/usr/local"
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local")
+	}
+	return "/usr/local"
+}
+
+// NeedsRoot reports whether installing into the prefix needs administrator
+// privileges, which is the case outside of the home directory of the user
+func (p *BaseProvider) NeedsRoot(ctx context.Context) bool {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return true
+	}
+	rel, err := filepath.Rel(home, p.Prefix(ctx))
+	return err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Plan adds an operation sai performs by itself, such as a download, to the
+// execution plan of the result attached to the context
+func (p *BaseProvider) Plan(ctx context.Context, operation string, needsRoot, destructive bool) {
+	output.FromContext(ctx).AddStep(output.Step{Command: operation, NeedsRoot: needsRoot, Destructive: destructive})
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("[DRY RUN] Would %s\n", operation)
+	}
+}
+
+// PlanCommand adds a command to the execution plan of the result attached to
+// the context
+func (p *BaseProvider) PlanCommand(ctx context.Context, cmd runner.Command) {
+	step := output.NewStep(cmd, false, false)
+	output.FromContext(ctx).AddStep(step)
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("[DRY RUN] Would run: %s\n", step)
+	}
+}
+
+// Run runs a command through the runner attached to the context, or adds it
+// to the plan in dry run mode
+func (p *BaseProvider) Run(ctx context.Context, cmd runner.Command) error {
+	if p.IsDryRun() {
+		p.PlanCommand(ctx, cmd)
+		return nil
+	}
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("Running: %s\n", cmd.String())
+	}
+	_, err := runner.Run(ctx, cmd)
+	if errors.Is(err, exec.ErrNotFound) {
+		return &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found in PATH", cmd.Name)}
+	}
+	return err
+}
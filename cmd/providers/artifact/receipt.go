@@ -0,0 +1,89 @@
+package artifact
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sai/pkg/state"
+)
+
+// Receipt records what an artifact provider installed, so that the software
+// can be queried and uninstalled without leaving files behind. Dirs are the
+// directories created to hold the files.
+type Receipt struct {
+	Software    string    `json:"software"`
+	Provider    string    `json:"provider"`
+	Version     string    `json:"version"`
+	URL         string    `json:"url"`
+	SHA256      string    `json:"sha256,omitempty"`
+	Prefix      string    `json:"prefix"`
+	Files       []string  `json:"files"`
+	Dirs        []string  `json:"dirs,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// ReceiptDir returns the directory holding the receipts, in the state
+// directory of sai
+func ReceiptDir() string {
+	return filepath.Join(state.Dir(), "artifacts")
+}
+
+// receiptPath returns the path of the receipt of a piece of software
+func receiptPath(software string) string {
+	return filepath.Join(ReceiptDir(), software+".json")
+}
+
+// LoadReceipt returns the receipt of a piece of software, or nil when no
+// artifact provider installed it
+func LoadReceipt(software string) (*Receipt, error) {
+	content, err := os.ReadFile(receiptPath(software))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var receipt Receipt
+	if err := json.Unmarshal(content, &receipt); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", receiptPath(software), err)
+	}
+	return &receipt, nil
+}
+
+// Save writes the receipt, replacing any previous one of the software
+func (r *Receipt) Save() error {
+	if err := os.MkdirAll(ReceiptDir(), 0o755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(receiptPath(r.Software), content, 0o644)
+}
+
+// Owns reports whether the receipt lists the file
+func (r *Receipt) Owns(path string) bool {
+	if r == nil {
+		return false
+	}
+	for _, file := range r.Files {
+		if file == path {
+			return true
+		}
+	}
+	return false
+}
+
+// removeReceipt deletes the receipt of a piece of software
+func removeReceipt(software string) error {
+	err := os.Remove(receiptPath(software))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,344 @@
+package artifact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+	"sai/pkg/state"
+)
+
+// buildSystem describes how a build system configures, builds and installs
+// the sources
+type buildSystem struct {
+	// Marker is the file of the sources identifying the build system
+	Marker    string
+	Configure []string
+	Build     [][]string
+	// Install runs with DESTDIR set to the staging directory
+	Install []string
+}
+
+// buildSystems are the supported build systems, in order of detection
+var buildSystems = []struct {
+	Name string
+	buildSystem
+}{
+	{"autotools", buildSystem{
+		Marker:    "configure",
+		Configure: []string{"./configure", "--prefix={{.prefix}}"},
+		Build:     [][]string{{"make", "-j{{.jobs}}"}},
+		Install:   []string{"make", "install", "DESTDIR={{.destdir}}"},
+	}},
+	{"cmake", buildSystem{
+		Marker:    "CMakeLists.txt",
+		Configure: []string{"cmake", "-S", ".", "-B", "build", "-DCMAKE_BUILD_TYPE=Release", "-DCMAKE_INSTALL_PREFIX={{.prefix}}"},
+		Build:     [][]string{{"cmake", "--build", "build", "--parallel", "{{.jobs}}"}},
+		Install:   []string{"cmake", "--install", "build"},
+	}},
+	{"meson", buildSystem{
+		Marker:    "meson.build",
+		Configure: []string{"meson", "setup", "build", "--buildtype=release", "--prefix={{.prefix}}"},
+		Build:     [][]string{{"meson", "compile", "-C", "build"}},
+		Install:   []string{"meson", "install", "-C", "build", "--destdir", "{{.destdir}}"},
+	}},
+	{"make", buildSystem{
+		Marker:  "Makefile",
+		Build:   [][]string{{"make", "-j{{.jobs}}", "PREFIX={{.prefix}}"}},
+		Install: []string{"make", "install", "PREFIX={{.prefix}}", "DESTDIR={{.destdir}}"},
+	}},
+}
+
+// findBuildSystem returns the build system with the given name, or the one
+// of the sources in the directory when the name is empty
+func findBuildSystem(name, dir string) (string, *buildSystem, error) {
+	for _, bs := range buildSystems {
+		if name == bs.Name {
+			return bs.Name, &bs.buildSystem, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, bs.Marker)); name == "" && err == nil {
+			return bs.Name, &bs.buildSystem, nil
+		}
+	}
+	if name != "" {
+		return "", nil, fmt.Errorf("unsupported build system %q (supported: autotools, cmake, meson, make)", name)
+	}
+	return "", nil, fmt.Errorf("no configure script, CMakeLists.txt, meson.build or Makefile in the sources")
+}
+
+// BuildDir returns the directory holding the scratch build directories, in
+// the state directory of sai
+func BuildDir() string {
+	return filepath.Join(state.Dir(), "build")
+}
+
+// LogDir returns the directory holding the build logs, in the state
+// directory of sai
+func LogDir() string {
+	return filepath.Join(state.Dir(), "logs")
+}
+
+// SourceProvider builds software from the source tarball described by the
+// source section of the saidata and installs the result under the prefix
+type SourceProvider struct {
+	BaseProvider
+}
+
+// Execute performs an action on the software. Install and upgrade fetch,
+// verify, build and install the sources; uninstall removes the files the
+// receipt of the software lists.
+func (p *SourceProvider) Execute(ctx context.Context, action, software string) error {
+	if !IsValidAction(action) {
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	switch action {
+	case ActionStatus:
+		return p.status(ctx, software)
+	case ActionUninstall:
+		return p.uninstall(ctx, software)
+	}
+
+	sw := data.FromContext(ctx)
+	if sw == nil || sw.Source == nil || sw.Source.URL == "" {
+		return &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+	}
+	recipe := sw.Source
+	src, err := p.resolve(ctx, software, spec{
+		Section:      "source",
+		URL:          recipe.URL,
+		ChecksumsURL: recipe.ChecksumsURL,
+		Version:      recipe.Version,
+		Checksum:     recipe.SHA256,
+		Signature:    recipe.Signature,
+	}, map[string]string{})
+	if err != nil {
+		return err
+	}
+	if action == ActionInfo {
+		p.info(software, src)
+		return nil
+	}
+	return p.install(ctx, action, software, recipe, src)
+}
+
+// steps returns the commands building the sources in the directory and
+// installing them into the staging directory
+func (p *SourceProvider) steps(recipe *data.Source, dir string, vars map[string]string) ([]runner.Command, error) {
+	var argvs [][]string
+	installStep := -1
+	if len(recipe.Steps) > 0 {
+		argvs = recipe.Steps
+	} else {
+		_, bs, err := findBuildSystem(recipe.BuildSystem, dir)
+		if err != nil {
+			return nil, err
+		}
+		if bs.Configure != nil {
+			argvs = append(argvs, append(append([]string(nil), bs.Configure...), recipe.ConfigureArgs...))
+		}
+		argvs = append(argvs, bs.Build...)
+		argvs = append(argvs, bs.Install)
+		installStep = len(argvs) - 1
+	}
+
+	var commands []runner.Command
+	for i, argv := range argvs {
+		var rendered []string
+		for _, arg := range argv {
+			value, err := renderURL(arg, vars)
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, value)
+		}
+		if len(rendered) == 0 {
+			return nil, fmt.Errorf("empty build step")
+		}
+		cmd := runner.NewCommand(rendered[0], rendered[1:]...)
+		cmd.Dir = dir
+		if i == installStep || installStep < 0 {
+			cmd.Env = []string{"DESTDIR=" + vars["destdir"]}
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+// install fetches, verifies and builds the sources in a scratch directory,
+// installs them into a staging directory and copies the staged files under
+// the prefix, replacing the files of the previous version
+func (p *SourceProvider) install(ctx context.Context, action, software string, recipe *data.Source, src *Source) error {
+	prefix := p.Prefix(ctx)
+	buildDir := filepath.Join(BuildDir(), software+"-"+src.Version)
+	sourceDir := filepath.Join(buildDir, "src")
+	vars := map[string]string{
+		"software": software,
+		"version":  src.Version,
+		"prefix":   prefix,
+		"destdir":  filepath.Join(buildDir, "destdir"),
+		"jobs":     strconv.Itoa(runtime.NumCPU()),
+	}
+
+	if p.IsDryRun() {
+		p.Plan(ctx, fmt.Sprintf("download %s", src.URL), false, false)
+		p.planVerification(ctx, src)
+		p.Plan(ctx, fmt.Sprintf("unpack %s into %s", fileName(src.URL), sourceDir), false, false)
+		if recipe.Steps == nil && recipe.BuildSystem == "" {
+			p.Plan(ctx, fmt.Sprintf("build %s with the build system of its sources", software), false, false)
+		} else {
+			commands, err := p.steps(recipe, sourceDir, vars)
+			if err != nil {
+				return err
+			}
+			for _, cmd := range commands {
+				p.PlanCommand(ctx, cmd)
+			}
+		}
+		p.Plan(ctx, fmt.Sprintf("install the files of %s under %s", vars["destdir"], prefix), p.NeedsRoot(ctx), false)
+		return nil
+	}
+
+	previous, err := p.previous(software)
+	if err != nil {
+		return err
+	}
+	if action == ActionUpgrade && previous != nil && previous.Version == src.Version {
+		fmt.Printf("%s %s is already installed\n", software, src.Version)
+		output.FromContext(ctx).Set("version", src.Version)
+		return nil
+	}
+
+	// Start from a clean scratch directory, kept on failure for inspection
+	if err := os.RemoveAll(buildDir); err != nil {
+		return err
+	}
+	for _, dir := range []string{sourceDir, vars["destdir"], LogDir()} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	logPath := filepath.Join(LogDir(), software+"-"+src.Version+".log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	fmt.Printf("Downloading %s\n", src.URL)
+	tarball, sum, err := download(ctx, src.URL, buildDir)
+	if err != nil {
+		return err
+	}
+	if err := p.verify(ctx, src, tarball, sum, buildDir); err != nil {
+		return err
+	}
+	dir, err := unpack(tarball, archiveFormat(filepath.Base(tarball)), sourceDir)
+	if err != nil {
+		return err
+	}
+	commands, err := p.steps(recipe, dir, vars)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Building %s %s in %s, logging to %s\n", software, src.Version, buildDir, logPath)
+	for _, cmd := range commands {
+		if err := p.runStep(ctx, cmd, logFile); err != nil {
+			return fmt.Errorf("building %s failed, see %s: %w", software, logPath, err)
+		}
+	}
+
+	files, err := stagedFiles(vars["destdir"])
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("building %s installed no files into %s, see %s", software, vars["destdir"], logPath)
+	}
+	receipt := &Receipt{
+		Software:    software,
+		Provider:    p.Name,
+		Version:     src.Version,
+		URL:         src.URL,
+		SHA256:      sum,
+		Prefix:      prefix,
+		InstalledAt: time.Now().UTC(),
+	}
+	for _, file := range files {
+		receipt.Files = append(receipt.Files, file.Dest)
+	}
+	created, err := installFiles(files, previous)
+	if previous != nil {
+		created = union(previous.Dirs, created)
+	}
+	receipt.Dirs = created
+	if err != nil {
+		return err
+	}
+	if err := removeStale(previous, receipt); err != nil {
+		return err
+	}
+	if err := receipt.Save(); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(buildDir); err != nil {
+		return err
+	}
+
+	output.FromContext(ctx).Set("version", src.Version)
+	fmt.Printf("Installed %d files of %s %s under %s\n", len(files), software, src.Version, prefix)
+	return nil
+}
+
+// runStep runs a build command quietly, appending the command and its output
+// to the build log
+func (p *SourceProvider) runStep(ctx context.Context, cmd runner.Command, log io.Writer) error {
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("Running: %s\n", cmd.String())
+	}
+	cmd.Quiet = true
+	fmt.Fprintf(log, "$ %s\n", cmd.String())
+	result, err := runner.Run(ctx, cmd)
+	if result != nil {
+		io.WriteString(log, result.Stdout)
+		io.WriteString(log, result.Stderr)
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found in PATH", cmd.Name)}
+	}
+	return err
+}
+
+// stagedFiles lists the files and symbolic links installed into the staging
+// directory, with the path they are installed at
+func stagedFiles(destdir string) ([]staged, error) {
+	var files []staged
+	err := filepath.WalkDir(destdir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(destdir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, staged{Src: path, Dest: filepath.Join(string(filepath.Separator), rel), Mode: info.Mode()})
+		return nil
+	})
+	return files, err
+}
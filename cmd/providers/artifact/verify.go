@@ -0,0 +1,133 @@
+package artifact
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sai/pkg/data"
+	"sai/pkg/runner"
+)
+
+// Source describes where a release artifact comes from and how it is checked
+type Source struct {
+	Version string
+	URL     string
+	// Checksum is the expected SHA-256 of the artifact, when known in advance
+	Checksum string
+	// ChecksumsURL is the URL of a checksums file listing the artifact
+	ChecksumsURL string
+	Signature    *data.Signature
+	// SignatureURL is the rendered URL of the signature
+	SignatureURL string
+}
+
+// Verified reports whether the artifact can be checked against a checksum;
+// sai refuses to install artifacts it cannot check
+func (s *Source) Verified() bool {
+	return s.Checksum != "" || s.ChecksumsURL != ""
+}
+
+// planVerification adds the checks of the artifact to the execution plan
+func (p *BaseProvider) planVerification(ctx context.Context, src *Source) {
+	name := fileName(src.URL)
+	if src.Checksum != "" {
+		p.Plan(ctx, fmt.Sprintf("verify the SHA-256 of %s is %s", name, src.Checksum), false, false)
+	} else {
+		p.Plan(ctx, fmt.Sprintf("download %s", src.ChecksumsURL), false, false)
+		p.Plan(ctx, fmt.Sprintf("verify the SHA-256 of %s against %s", name, fileName(src.ChecksumsURL)), false, false)
+	}
+	if src.Signature != nil {
+		p.Plan(ctx, fmt.Sprintf("download %s", src.SignatureURL), false, false)
+		signed := name
+		if src.Signature.Checksums {
+			signed = fileName(src.ChecksumsURL)
+		}
+		for _, cmd := range signatureCommands(src.Signature, signed, fileName(src.SignatureURL), "") {
+			p.PlanCommand(ctx, cmd)
+		}
+	}
+}
+
+// verify checks the SHA-256 of the downloaded artifact, and its signature
+// when the source has one, downloading the checksums and the signature into
+// the directory
+func (p *BaseProvider) verify(ctx context.Context, src *Source, artifact, sum, dir string) error {
+	name := filepath.Base(artifact)
+	expected := strings.ToLower(src.Checksum)
+	var checksums string
+	switch {
+	case expected != "":
+	case src.ChecksumsURL != "":
+		var err error
+		if checksums, _, err = download(ctx, src.ChecksumsURL, dir); err != nil {
+			return err
+		}
+		content, err := os.ReadFile(checksums)
+		if err != nil {
+			return err
+		}
+		if expected = findChecksum(content, name); expected == "" {
+			return fmt.Errorf("no checksum of %s in %s", name, src.ChecksumsURL)
+		}
+	default:
+		return fmt.Errorf("no SHA-256 checksum of %s version %s to verify it against", name, src.Version)
+	}
+	if sum != expected {
+		return fmt.Errorf("SHA-256 mismatch for %s: expected %s, got %s", name, expected, sum)
+	}
+
+	if src.Signature == nil {
+		return nil
+	}
+	signed := artifact
+	if src.Signature.Checksums {
+		signed = checksums
+	}
+	signature, _, err := download(ctx, src.SignatureURL, dir)
+	if err != nil {
+		return err
+	}
+	if key := src.Signature.GPGKey; key != "" && src.Signature.Minisign == "" {
+		if err := writeGPGKey(ctx, key, dir); err != nil {
+			return err
+		}
+	}
+	for _, cmd := range signatureCommands(src.Signature, signed, signature, dir) {
+		cmd.Quiet = true
+		if err := p.Run(ctx, cmd); err != nil {
+			return fmt.Errorf("verifying the signature of %s: %w", filepath.Base(signed), err)
+		}
+	}
+	return nil
+}
+
+// signatureCommands returns the commands checking the detached signature of
+// a file with minisign, or with GPG against a keyring of the directory
+// holding only the key of the signer
+func signatureCommands(sig *data.Signature, file, signature, dir string) []runner.Command {
+	if sig.Minisign != "" {
+		return []runner.Command{runner.NewCommand("minisign", "-V", "-P", sig.Minisign, "-m", file, "-x", signature)}
+	}
+	keyring := filepath.Join(dir, "keyring.gpg")
+	return []runner.Command{
+		runner.NewCommand("gpg", "--batch", "--no-default-keyring", "--keyring", keyring, "--import", filepath.Join(dir, "key.asc")),
+		runner.NewCommand("gpg", "--batch", "--no-default-keyring", "--keyring", keyring, "--verify", signature, file),
+	}
+}
+
+// writeGPGKey saves the GPG key of the signer into key.asc in the directory,
+// downloading it when the saidata gives its URL
+func writeGPGKey(ctx context.Context, key, dir string) error {
+	dest := filepath.Join(dir, "key.asc")
+	if strings.Contains(key, "BEGIN PGP PUBLIC KEY BLOCK") {
+		return os.WriteFile(dest, []byte(key), 0o644)
+	}
+	downloaded, _, err := download(ctx, key, dir)
+	if err != nil {
+		return err
+	}
+	return os.Rename(downloaded, dest)
+}
@@ -1,127 +0,0 @@
-package cloud
-
-import (
-	"fmt"
-	"os/exec"
-)
-
-// AWSProvider handles AWS cloud operations
-type AWSProvider struct {
-	BaseCloudProvider
-	Profile string
-}
-
-// Execute runs AWS CLI commands
-func (p *AWSProvider) Execute(action, resource string) error {
-	// Validate action
-	if !IsValidCloudAction(action) {
-		return fmt.Errorf("unsupported action '%s' for AWS provider", action)
-	}
-
-	// Check if in dry run mode
-	if p.IsDryRun() {
-		fmt.Printf("[DRY RUN] Would execute %s %s with AWS provider\n", action, resource)
-		return nil
-	}
-
-	fmt.Printf("Executing %s %s with AWS provider\n", action, resource)
-
-	// Set default region if not specified
-	region := p.Region
-	if region == "" {
-		region = "us-east-1" // Default AWS region
-	}
-
-	// Parse resource type and name
-	resourceType, resourceName := parseCloudResource(resource)
-
-	var cmd *exec.Cmd
-	switch action {
-	case ActionStart:
-		switch resourceType {
-		case "ec2":
-			cmd = exec.Command("aws", "ec2", "start-instances", "--instance-ids", resourceName, "--region", region)
-		case "rds":
-			cmd = exec.Command("aws", "rds", "start-db-instance", "--db-instance-identifier", resourceName, "--region", region)
-		default:
-			fmt.Printf("Resource type %s not supported for AWS start action\n", resourceType)
-			return nil
-		}
-	case ActionStop:
-		switch resourceType {
-		case "ec2":
-			cmd = exec.Command("aws", "ec2", "stop-instances", "--instance-ids", resourceName, "--region", region)
-		case "rds":
-			cmd = exec.Command("aws", "rds", "stop-db-instance", "--db-instance-identifier", resourceName, "--region", region)
-		default:
-			fmt.Printf("Resource type %s not supported for AWS stop action\n", resourceType)
-			return nil
-		}
-	case ActionStatus:
-		switch resourceType {
-		case "ec2":
-			cmd = exec.Command("aws", "ec2", "describe-instances", "--instance-ids", resourceName, "--region", region)
-		case "rds":
-			cmd = exec.Command("aws", "rds", "describe-db-instances", "--db-instance-identifier", resourceName, "--region", region)
-		case "s3":
-			cmd = exec.Command("aws", "s3", "ls", resourceName, "--region", region)
-		default:
-			fmt.Printf("Resource type %s not supported for AWS status action\n", resourceType)
-			return nil
-		}
-	case ActionCreate:
-		switch resourceType {
-		case "ec2":
-			cmd = exec.Command("aws", "ec2", "run-instances", "--image-id", "ami-12345678", "--count", "1", "--instance-type", "t2.micro", "--region", region)
-		case "s3":
-			cmd = exec.Command("aws", "s3", "mb", fmt.Sprintf("s3://%s", resourceName), "--region", region)
-		default:
-			fmt.Printf("Resource type %s not supported for AWS create action\n", resourceType)
-			return nil
-		}
-	case ActionDelete:
-		switch resourceType {
-		case "ec2":
-			cmd = exec.Command("aws", "ec2", "terminate-instances", "--instance-ids", resourceName, "--region", region)
-		case "s3":
-			cmd = exec.Command("aws", "s3", "rb", fmt.Sprintf("s3://%s", resourceName), "--force", "--region", region)
-		default:
-			fmt.Printf("Resource type %s not supported for AWS delete action\n", resourceType)
-			return nil
-		}
-	case ActionList:
-		switch resourceType {
-		case "ec2":
-			cmd = exec.Command("aws", "ec2", "describe-instances", "--region", region)
-		case "s3":
-			cmd = exec.Command("aws", "s3", "ls", "--region", region)
-		case "rds":
-			cmd = exec.Command("aws", "rds", "describe-db-instances", "--region", region)
-		default:
-			cmd = exec.Command("aws", resourceType, "help")
-		}
-	default:
-		fmt.Printf("Action %s not implemented for AWS\n", action)
-		return nil
-	}
-
-	// Add profile if specified
-	if p.Profile != "" {
-		args := append([]string{"--profile", p.Profile}, cmd.Args[1:]...)
-		cmd = exec.Command(cmd.Args[0], args...)
-	}
-
-	// Would actually run the command in a real implementation
-	fmt.Printf("Would run: %s\n", cmd.String())
-	return nil
-}
-
-// NewAWSProvider creates a new AWS provider
-func NewAWSProvider() *AWSProvider {
-	return &AWSProvider{
-		BaseCloudProvider: BaseCloudProvider{
-			Name:   "aws",
-			Region: "us-east-1",
-		},
-	}
-}
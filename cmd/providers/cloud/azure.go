@@ -1,125 +0,0 @@
-package cloud
-
-import (
-	"fmt"
-	"os/exec"
-)
-
-// AzureProvider handles Azure cloud operations
-type AzureProvider struct {
-	BaseCloudProvider
-	Subscription  string
-	ResourceGroup string
-}
-
-// Execute runs Azure CLI commands
-func (p *AzureProvider) Execute(action, resource string) error {
-	// Validate action
-	if !IsValidCloudAction(action) {
-		return fmt.Errorf("unsupported action '%s' for Azure provider", action)
-	}
-
-	// Check if in dry run mode
-	if p.IsDryRun() {
-		fmt.Printf("[DRY RUN] Would execute %s %s with Azure provider\n", action, resource)
-		return nil
-	}
-
-	fmt.Printf("Executing %s %s with Azure provider\n", action, resource)
-
-	// Set default region if not specified
-	region := p.Region
-	if region == "" {
-		region = "eastus" // Default Azure region
-	}
-
-	// Parse resource type and name
-	resourceType, resourceName := parseCloudResource(resource)
-
-	var cmd *exec.Cmd
-	switch action {
-	case "start":
-		switch resourceType {
-		case "vm":
-			cmd = exec.Command("az", "vm", "start", "--name", resourceName, "--resource-group", p.ResourceGroup)
-		case "webapp":
-			cmd = exec.Command("az", "webapp", "start", "--name", resourceName, "--resource-group", p.ResourceGroup)
-		default:
-			fmt.Printf("Resource type %s not supported for Azure start action\n", resourceType)
-			return nil
-		}
-	case "stop":
-		switch resourceType {
-		case "vm":
-			cmd = exec.Command("az", "vm", "stop", "--name", resourceName, "--resource-group", p.ResourceGroup)
-		case "webapp":
-			cmd = exec.Command("az", "webapp", "stop", "--name", resourceName, "--resource-group", p.ResourceGroup)
-		default:
-			fmt.Printf("Resource type %s not supported for Azure stop action\n", resourceType)
-			return nil
-		}
-	case "status":
-		switch resourceType {
-		case "vm":
-			cmd = exec.Command("az", "vm", "show", "--name", resourceName, "--resource-group", p.ResourceGroup)
-		case "webapp":
-			cmd = exec.Command("az", "webapp", "show", "--name", resourceName, "--resource-group", p.ResourceGroup)
-		default:
-			fmt.Printf("Resource type %s not supported for Azure status action\n", resourceType)
-			return nil
-		}
-	case "create":
-		switch resourceType {
-		case "vm":
-			cmd = exec.Command("az", "vm", "create", "--name", resourceName, "--resource-group", p.ResourceGroup, "--image", "UbuntuLTS", "--location", region)
-		case "webapp":
-			cmd = exec.Command("az", "webapp", "create", "--name", resourceName, "--resource-group", p.ResourceGroup, "--plan", "myAppServicePlan", "--location", region)
-		default:
-			fmt.Printf("Resource type %s not supported for Azure create action\n", resourceType)
-			return nil
-		}
-	case "delete":
-		switch resourceType {
-		case "vm":
-			cmd = exec.Command("az", "vm", "delete", "--name", resourceName, "--resource-group", p.ResourceGroup, "--yes")
-		case "webapp":
-			cmd = exec.Command("az", "webapp", "delete", "--name", resourceName, "--resource-group", p.ResourceGroup)
-		default:
-			fmt.Printf("Resource type %s not supported for Azure delete action\n", resourceType)
-			return nil
-		}
-	case "list":
-		switch resourceType {
-		case "vm":
-			cmd = exec.Command("az", "vm", "list", "--resource-group", p.ResourceGroup)
-		case "webapp":
-			cmd = exec.Command("az", "webapp", "list", "--resource-group", p.ResourceGroup)
-		default:
-			cmd = exec.Command("az", resourceType, "--help")
-		}
-	default:
-		fmt.Printf("Action %s not implemented for Azure\n", action)
-		return nil
-	}
-
-	// Add subscription if specified
-	if p.Subscription != "" {
-		args := append([]string{"--subscription", p.Subscription}, cmd.Args[1:]...)
-		cmd = exec.Command(cmd.Args[0], args...)
-	}
-
-	// Would actually run the command in a real implementation
-	fmt.Printf("Would run: %s\n", cmd.String())
-	return nil
-}
-
-// NewAzureProvider creates a new Azure provider
-func NewAzureProvider() *AzureProvider {
-	return &AzureProvider{
-		BaseCloudProvider: BaseCloudProvider{
-			Name:   "azure",
-			Region: "eastus",
-		},
-		ResourceGroup: "myResourceGroup",
-	}
-}
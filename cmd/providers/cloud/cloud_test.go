@@ -1,7 +1,13 @@
 package cloud
 
 import (
+	"context"
+	"errors"
 	"testing"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
 )
 
 // TestCloudProviders is a placeholder test for the cloud providers package
@@ -15,6 +21,10 @@ func TestDryRunModeCloudProviders(t *testing.T) {
 	SetDryRun(true)
 	defer SetDryRun(false) // Reset after test
 
+	// Record commands instead of running them
+	recorder := runner.NewRecorder()
+	ctx := runner.WithRunner(context.Background(), recorder)
+
 	// Test with different cloud providers
 	cloudProviders := []Provider{
 		NewAWSProvider(),
@@ -46,12 +56,18 @@ func TestDryRunModeCloudProviders(t *testing.T) {
 
 		for _, action := range cloudActions {
 			t.Run(platform+"_"+action, func(t *testing.T) {
-				// Execute should not perform real operations in dry run mode
-				err := provider.Execute(action, testResource)
+				// Execute should plan the command instead of running it in dry run mode
+				result := output.NewResult(testResource, action)
+				err := provider.Execute(output.WithResult(ctx, result), action, testResource)
 
-				if err != nil {
-					t.Errorf("Expected no error in dry run mode for %s %s, got: %v",
-						platform, action, err)
+				var unsupported *errs.UnsupportedActionError
+				switch {
+				case errors.As(err, &unsupported):
+					// The provider has no command for this action
+				case err != nil:
+					t.Errorf("Expected no error in dry run mode for %s %s, got: %v", platform, action, err)
+				case len(result.Plan) != 1:
+					t.Errorf("Expected one planned step for %s %s, got: %v", platform, action, result.Plan)
 				}
 			})
 		}
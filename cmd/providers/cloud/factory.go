@@ -1,16 +1,33 @@
 package cloud
 
-// NewProvider creates the appropriate cloud provider based on the name
+import "sai/cmd/providers/definition"
+
+// NewCloudProvider creates a cloud provider from its definition
+func NewCloudProvider(d *definition.Definition) *BaseCloudProvider {
+	return &BaseCloudProvider{Name: d.Name, Definition: d}
+}
+
+// NewAWSProvider creates a new AWS provider
+func NewAWSProvider() *BaseCloudProvider {
+	return NewCloudProvider(definition.Builtin("aws"))
+}
+
+// NewAzureProvider creates a new Azure provider
+func NewAzureProvider() *BaseCloudProvider {
+	return NewCloudProvider(definition.Builtin("azure"))
+}
+
+// NewGCPProvider creates a new GCP provider
+func NewGCPProvider() *BaseCloudProvider {
+	return NewCloudProvider(definition.Builtin("gcp"))
+}
+
+// NewProvider creates the cloud provider with the given name, custom
+// definitions included
 func NewProvider(name string) Provider {
-	switch name {
-	case "aws":
-		return NewAWSProvider()
-	case "azure":
-		return NewAzureProvider()
-	case "gcp":
-		return NewGCPProvider()
-	default:
-		// Return AWS provider as default
-		return NewAWSProvider()
+	if d, err := definition.Get(name); err == nil && d != nil && d.Type == "cloud" {
+		return NewCloudProvider(d)
 	}
+	// Return AWS provider as default
+	return NewAWSProvider()
 }
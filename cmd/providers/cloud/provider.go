@@ -1,8 +1,15 @@
 package cloud
 
+import (
+	"context"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/errs"
+)
+
 // Provider interface defines methods for cloud provider implementations
 type Provider interface {
-	Execute(action, resource string) error
+	Execute(ctx context.Context, action, resource string) error
 	GetCloudPlatform() string
 	SetRegion(region string)
 	GetRegion() string
@@ -49,10 +56,12 @@ func SetDryRun(enabled bool) {
 	isDryRunMode = enabled
 }
 
-// BaseCloudProvider common functionality for cloud providers
+// BaseCloudProvider runs cloud actions described by a provider definition
 type BaseCloudProvider struct {
-	Name   string
-	Region string
+	Name string
+	// Region overrides the default region of the definition when set
+	Region     string
+	Definition *definition.Definition
 }
 
 // GetCloudPlatform returns the cloud platform name
@@ -67,6 +76,9 @@ func (p *BaseCloudProvider) SetRegion(region string) {
 
 // GetRegion gets the current region
 func (p *BaseCloudProvider) GetRegion() string {
+	if p.Region == "" {
+		return p.Definition.Variables["region"]
+	}
 	return p.Region
 }
 
@@ -74,3 +86,22 @@ func (p *BaseCloudProvider) GetRegion() string {
 func (p *BaseCloudProvider) IsDryRun() bool {
 	return isDryRunMode
 }
+
+// Execute runs the command the definition maps the action and resource type to
+func (p *BaseCloudProvider) Execute(ctx context.Context, action, resource string) error {
+	// Validate action
+	if !IsValidCloudAction(action) {
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	if p.Region != "" {
+		ctx = definition.WithVariables(ctx, map[string]string{"region": p.Region})
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Definition.Plan(ctx, action, resource)
+	}
+
+	return p.Definition.Execute(ctx, action, resource)
+}
@@ -0,0 +1,330 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+	"sai/pkg/state"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeTools lists the compose implementations in order of preference, as
+// the command running them. Docker is expected to have the compose plugin.
+var composeTools = [][]string{
+	{"docker", "compose"},
+	{"podman-compose"},
+	{"docker-compose"},
+}
+
+// composeFileNames are the names compose looks for in a project directory
+var composeFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// ComposeDir returns the directory holding the compose files generated from
+// the saidata, one directory per project, in the state directory of sai.
+// Compose files placed there by hand are used for software without saidata.
+func ComposeDir() string {
+	return filepath.Join(state.Dir(), "compose")
+}
+
+// ComposeProvider manages multi-container software as a compose project,
+// with docker compose, podman-compose or docker-compose
+type ComposeProvider struct {
+	Name string
+}
+
+// GetContainerTool returns the compose provider name
+func (p *ComposeProvider) GetContainerTool() string {
+	return p.Name
+}
+
+// IsDryRun checks if dry run mode is enabled
+func (p *ComposeProvider) IsDryRun() bool {
+	return isDryRunMode
+}
+
+// FindCompose returns the command running the first usable compose
+// implementation and the path of its binary. Docker only counts when its
+// compose plugin answers, as the docker binary is found without it. Nothing
+// runs in dry run mode, where docker is assumed to have the plugin.
+func FindCompose(ctx context.Context) ([]string, string, error) {
+	for _, tool := range composeTools {
+		path, err := runner.LookPath(ctx, tool[0])
+		if err != nil {
+			continue
+		}
+		if len(tool) > 1 && !isDryRunMode {
+			cmd := runner.NewCommand(tool[0], append(tool[1:], "version")...)
+			cmd.Quiet = true
+			if _, err := runner.Run(ctx, cmd); err != nil {
+				continue
+			}
+		}
+		return tool, path, nil
+	}
+	return nil, "", &errs.ProviderUnavailableError{
+		Provider: "compose",
+		Reason:   "none of docker compose, podman-compose and docker-compose is installed",
+	}
+}
+
+// project is a compose project of a piece of software
+type project struct {
+	Name    string
+	File    string
+	EnvFile string
+	// Tool is the command running the compose implementation
+	Tool []string
+	// Generated is set for compose files sai writes from the saidata
+	Generated *composeFile
+}
+
+// composeFile is the part of the compose format sai generates
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+	Volumes  map[string]struct{}       `yaml:"volumes,omitempty"`
+}
+
+// composeService is a service of a generated compose file
+type composeService struct {
+	Image         string            `yaml:"image"`
+	ContainerName string            `yaml:"container_name,omitempty"`
+	Restart       string            `yaml:"restart"`
+	Ports         []string          `yaml:"ports,omitempty"`
+	Volumes       []string          `yaml:"volumes,omitempty"`
+	Environment   map[string]string `yaml:"environment,omitempty"`
+}
+
+// expandHome replaces a leading ~ by the home directory of the user
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// findComposeFile returns the compose file of a directory, or the path
+// itself when it is not a directory
+func findComposeFile(path string) string {
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return path
+	}
+	for _, name := range composeFileNames {
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return filepath.Join(path, name)
+		}
+	}
+	return filepath.Join(path, composeFileNames[0])
+}
+
+// generate returns the compose file running the services of the saidata
+func generate(name string, services map[string]data.Container) *composeFile {
+	file := &composeFile{Name: name, Services: make(map[string]composeService)}
+	for service, c := range services {
+		file.Services[service] = composeService{
+			Image:         c.Reference(),
+			ContainerName: c.Name,
+			Restart:       "unless-stopped",
+			Ports:         c.Ports,
+			Volumes:       c.Volumes,
+			Environment:   c.Env,
+		}
+		// Named volumes, unlike host paths, are declared by the project
+		for _, volume := range c.Volumes {
+			source, _, mounted := strings.Cut(volume, ":")
+			if mounted && source != "" && !strings.ContainsAny(source[:1], "/.~$") {
+				if file.Volumes == nil {
+					file.Volumes = make(map[string]struct{})
+				}
+				file.Volumes[source] = struct{}{}
+			}
+		}
+	}
+	return file
+}
+
+// project locates the compose project of the software: the compose file of
+// the saidata, the one generated from its services, or a compose file in the
+// project directory of ComposeDir. The project and env-file variables
+// override the saidata.
+func (p *ComposeProvider) project(ctx context.Context, software string) (*project, error) {
+	var c data.Compose
+	if sw := data.FromContext(ctx); sw != nil && sw.Compose != nil {
+		c = *sw.Compose
+	}
+	vars := definition.VariablesFromContext(ctx)
+	proj := &project{Name: software, EnvFile: expandHome(c.EnvFile)}
+	if c.Project != "" {
+		proj.Name = c.Project
+	}
+	if vars["project"] != "" {
+		proj.Name = vars["project"]
+	}
+	if vars["env_file"] != "" {
+		proj.EnvFile = vars["env_file"]
+	}
+
+	dir := filepath.Join(ComposeDir(), proj.Name)
+	switch {
+	case c.File != "":
+		proj.File = findComposeFile(expandHome(c.File))
+	case len(c.Services) > 0:
+		proj.File = filepath.Join(dir, composeFileNames[0])
+		proj.Generated = generate(proj.Name, c.Services)
+	default:
+		proj.File = findComposeFile(dir)
+		if _, err := os.Stat(proj.File); err != nil {
+			return nil, &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+		}
+	}
+
+	tool, _, err := FindCompose(ctx)
+	if err != nil && !p.IsDryRun() {
+		return nil, err
+	}
+	if tool == nil {
+		// Dry runs plan the commands of the preferred implementation
+		tool = composeTools[0]
+	}
+	proj.Tool = tool
+	return proj, nil
+}
+
+// command returns the compose command running the subcommand on the project
+func (p *ComposeProvider) command(proj *project, args ...string) runner.Command {
+	argv := append(append([]string(nil), proj.Tool[1:]...), "-p", proj.Name, "-f", proj.File)
+	if proj.EnvFile != "" {
+		argv = append(argv, "--env-file", proj.EnvFile)
+	}
+	return runner.NewCommand(proj.Tool[0], append(argv, args...)...)
+}
+
+// Execute performs an action on the compose project of the software. Install
+// and start bring the project up, upgrade pulls its images first, and
+// uninstall takes it down together with its volumes.
+func (p *ComposeProvider) Execute(ctx context.Context, action, software string) error {
+	switch action {
+	case ActionInstall, ActionUpgrade, ActionStart, ActionStop, ActionRestart, ActionStatus, ActionLogs, ActionUninstall:
+	default:
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+	if version := definition.VariablesFromContext(ctx)["version"]; version != "" && (action == ActionInstall || action == ActionUpgrade) {
+		// The images of a project are pinned in its compose file
+		return &errs.VersionPinningError{Provider: p.Name, Version: version}
+	}
+	proj, err := p.project(ctx, software)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case ActionInstall, ActionStart:
+		if err := p.write(ctx, proj); err != nil {
+			return err
+		}
+		return p.run(ctx, p.command(proj, "up", "-d"), false)
+	case ActionUpgrade:
+		if err := p.write(ctx, proj); err != nil {
+			return err
+		}
+		if err := p.run(ctx, p.command(proj, "pull"), false); err != nil {
+			return err
+		}
+		return p.run(ctx, p.command(proj, "up", "-d"), false)
+	case ActionStop, ActionRestart, ActionLogs:
+		return p.run(ctx, p.command(proj, action), false)
+	case ActionStatus:
+		return p.status(ctx, proj, software)
+	default:
+		if err := p.run(ctx, p.command(proj, "down", "-v"), true); err != nil {
+			return err
+		}
+		if proj.Generated != nil && !p.IsDryRun() {
+			return os.RemoveAll(filepath.Dir(proj.File))
+		}
+		return nil
+	}
+}
+
+// write writes the compose file generated from the saidata
+func (p *ComposeProvider) write(ctx context.Context, proj *project) error {
+	if proj.Generated == nil {
+		return nil
+	}
+	if p.IsDryRun() {
+		output.FromContext(ctx).AddStep(output.Step{Command: "write " + proj.File})
+		if !runner.IsQuiet(ctx) {
+			fmt.Printf("[DRY RUN] Would write %s\n", proj.File)
+		}
+		return nil
+	}
+	content, err := yaml.Marshal(proj.Generated)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(proj.File), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(proj.File, content, 0o644)
+}
+
+// status lists the containers of the project, failing when it has none
+func (p *ComposeProvider) status(ctx context.Context, proj *project, software string) error {
+	if p.IsDryRun() {
+		return p.run(ctx, p.command(proj, "ps"), false)
+	}
+	args := []string{"ps", "--quiet"}
+	if len(proj.Tool) > 1 {
+		// docker compose only lists the running containers by default
+		args = append(args, "--all")
+	}
+	cmd := p.command(proj, args...)
+	cmd.Quiet = true
+	result, err := runner.Run(ctx, cmd)
+	if errors.Is(err, exec.ErrNotFound) {
+		return &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found in PATH", cmd.Name)}
+	}
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(result.Stdout) == "" {
+		return &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+	}
+	if runner.IsQuiet(ctx) {
+		return nil
+	}
+	return p.run(ctx, p.command(proj, "ps"), false)
+}
+
+// run runs a compose command, or adds it to the plan in dry run mode
+func (p *ComposeProvider) run(ctx context.Context, cmd runner.Command, destructive bool) error {
+	if p.IsDryRun() {
+		step := output.NewStep(cmd, false, destructive)
+		output.FromContext(ctx).AddStep(step)
+		if !runner.IsQuiet(ctx) {
+			fmt.Printf("[DRY RUN] Would run: %s\n", step)
+		}
+		return nil
+	}
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("Running: %s\n", cmd.String())
+	}
+	_, err := runner.Run(ctx, cmd)
+	if errors.Is(err, exec.ErrNotFound) {
+		return &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found in PATH", cmd.Name)}
+	}
+	return err
+}
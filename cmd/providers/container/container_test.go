@@ -1,7 +1,21 @@
 package container
 
 import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+
+	"gopkg.in/yaml.v3"
 )
 
 // TestContainerProviders is a placeholder test for the container providers package
@@ -15,6 +29,10 @@ func TestDryRunModeContainerProviders(t *testing.T) {
 	SetDryRun(true)
 	defer SetDryRun(false) // Reset after test
 
+	// Record commands instead of running them
+	recorder := runner.NewRecorder()
+	ctx := runner.WithRunner(context.Background(), recorder)
+
 	// Test with different container providers
 	containerProviders := []Provider{
 		NewKubectlProvider(),
@@ -45,14 +63,420 @@ func TestDryRunModeContainerProviders(t *testing.T) {
 
 		for _, action := range containerActions {
 			t.Run(tool+"_"+action, func(t *testing.T) {
-				// Execute should not perform real operations in dry run mode
-				err := provider.Execute(action, testResource)
+				// Execute should plan the command instead of running it in dry run mode
+				result := output.NewResult(testResource, action)
+				err := provider.Execute(output.WithResult(ctx, result), action, testResource)
 
-				if err != nil {
-					t.Errorf("Expected no error in dry run mode for %s %s, got: %v",
-						tool, action, err)
+				var unsupported *errs.UnsupportedActionError
+				switch {
+				case errors.As(err, &unsupported):
+					// The provider has no command for this action
+				case err != nil:
+					t.Errorf("Expected no error in dry run mode for %s %s, got: %v", tool, action, err)
+				case len(result.Plan) != 1:
+					t.Errorf("Expected one planned step for %s %s, got: %v", tool, action, result.Plan)
 				}
 			})
 		}
 	}
 }
+
+// TestRuntimeProvider tests the commands docker and podman run for each action
+func TestRuntimeProvider(t *testing.T) {
+	sw := &data.Software{Name: "nginx", Container: &data.Container{
+		Image:   "docker.io/library/nginx",
+		Tag:     "stable",
+		Ports:   []string{"8080:80"},
+		Volumes: []string{"nginx-html:/usr/share/nginx/html"},
+		Env:     map[string]string{"TZ": "UTC", "NGINX_PORT": "80"},
+	}}
+	run := "run --detach --name nginx --restart unless-stopped --publish 8080:80 " +
+		"--volume nginx-html:/usr/share/nginx/html --env NGINX_PORT=80 --env TZ=UTC "
+
+	testCases := []struct {
+		name     string
+		provider string
+		action   string
+		version  string
+		missing  []string
+		exists   bool
+		expected []string
+	}{
+		{"Install", "docker", ActionInstall, "", nil, false, []string{
+			"docker container inspect --format {{.State.Status}} {{.Config.Image}} nginx",
+			"docker pull docker.io/library/nginx:stable",
+			"docker " + run + "docker.io/library/nginx:stable",
+		}},
+		{"Install Existing", "docker", ActionInstall, "", nil, true, []string{
+			"docker container inspect --format {{.State.Status}} {{.Config.Image}} nginx",
+		}},
+		{"Install Version", "podman", ActionInstall, "1.27", nil, false, []string{
+			"podman container inspect --format {{.State.Status}} {{.Config.Image}} nginx",
+			"podman pull docker.io/library/nginx:1.27",
+			"podman " + run + "docker.io/library/nginx:1.27",
+		}},
+		{"Upgrade", "podman", ActionUpgrade, "", nil, true, []string{
+			"podman container inspect --format {{.State.Status}} {{.Config.Image}} nginx",
+			"podman pull docker.io/library/nginx:stable",
+			"podman rm --force nginx",
+			"podman " + run + "docker.io/library/nginx:stable",
+		}},
+		{"Start", "docker", ActionStart, "", nil, true, []string{"docker start nginx"}},
+		{"Stop", "docker", ActionStop, "", nil, true, []string{"docker stop nginx"}},
+		{"Restart", "docker", ActionRestart, "", nil, true, []string{"docker restart nginx"}},
+		{"Logs", "docker", ActionLogs, "", nil, true, []string{"docker logs nginx"}},
+		{"Uninstall", "docker", ActionUninstall, "", nil, true, []string{"docker rm --force nginx"}},
+		{"Podman Fallback", "docker", ActionStart, "", []string{"docker"}, true, []string{"podman start nginx"}},
+		{"Docker Fallback", "podman", ActionStop, "", []string{"podman"}, true, []string{"docker stop nginx"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			recorder.Missing = tc.missing
+			if tc.exists {
+				recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+					return &runner.Result{Command: cmd, Stdout: "running docker.io/library/nginx:stable\n"}, nil
+				}
+			}
+			ctx := runner.WithQuiet(runner.WithRunner(data.WithSoftware(context.Background(), sw), recorder))
+			if tc.version != "" {
+				ctx = definition.WithVariables(ctx, map[string]string{"version": tc.version})
+			}
+			if err := NewRuntimeProvider(tc.provider).Execute(ctx, tc.action, "nginx"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var commands []string
+			for _, cmd := range recorder.Commands() {
+				commands = append(commands, strings.Join(cmd.Argv(), " "))
+			}
+			if strings.Join(commands, "\n") != strings.Join(tc.expected, "\n") {
+				t.Errorf("Expected commands %q, got %q", tc.expected, commands)
+			}
+		})
+	}
+}
+
+// TestRuntimeStatus tests that the status of a container reports the tag of
+// its image as the installed version
+func TestRuntimeStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stdout   string
+		stderr   string
+		fail     bool
+		expected string
+		err      error
+	}{
+		{"Running", "running docker.io/library/redis:7\n", "", false, "7", nil},
+		{"Stopped Untagged", "exited docker.io/library/redis\n", "", false, "latest", nil},
+		{"Missing", "", "Error: No such container: redis\n", true, "", &errs.SoftwareNotFoundError{}},
+		{"Daemon Down", "", "Cannot connect to the Docker daemon\n", true, "", &errs.CommandFailedError{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+				result := &runner.Result{Command: cmd, Stdout: tc.stdout, Stderr: tc.stderr}
+				if tc.fail {
+					result.ExitCode = 1
+					return result, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 1}
+				}
+				return result, nil
+			}
+			result := output.NewResult("redis", "status")
+			ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(context.Background(), recorder)), result)
+
+			err := NewRuntimeProvider("docker").Execute(ctx, ActionStatus, "redis")
+			switch expected := tc.err.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			case *errs.SoftwareNotFoundError:
+				if !errors.As(err, &expected) {
+					t.Fatalf("Expected a software not found error, got %v", err)
+				}
+			case *errs.CommandFailedError:
+				if !errors.As(err, &expected) {
+					t.Fatalf("Expected a command failed error, got %v", err)
+				}
+			}
+			if result.Get("version") != tc.expected {
+				t.Errorf("Expected version %q, got %q", tc.expected, result.Get("version"))
+			}
+		})
+	}
+
+	var unavailable *errs.ProviderUnavailableError
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		return nil, &exec.Error{Name: cmd.Name, Err: exec.ErrNotFound}
+	}
+	ctx := runner.WithQuiet(runner.WithRunner(context.Background(), recorder))
+	if err := NewRuntimeProvider("docker").Execute(ctx, ActionStatus, "redis"); !errors.As(err, &unavailable) {
+		t.Errorf("Expected a provider unavailable error without a runtime, got %v", err)
+	}
+}
+
+// TestRuntimeDryRun tests that dry runs plan the runtime commands without
+// inspecting the containers
+func TestRuntimeDryRun(t *testing.T) {
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	sw := &data.Software{Name: "redis", Container: &data.Container{Image: "docker.io/library/redis", Tag: "7", Name: "cache"}}
+	recorder := runner.NewRecorder()
+	result := output.NewResult("redis", "upgrade")
+	ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(data.WithSoftware(context.Background(), sw), recorder)), result)
+
+	if err := NewRuntimeProvider("podman").Execute(ctx, ActionUpgrade, "redis"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{
+		"podman pull docker.io/library/redis:7",
+		"podman rm --force cache",
+		"podman run --detach --name cache --restart unless-stopped docker.io/library/redis:7",
+	}
+	var plan []string
+	for _, step := range result.Plan {
+		plan = append(plan, step.Command)
+	}
+	if strings.Join(plan, "\n") != strings.Join(expected, "\n") || !result.Plan[1].Destructive {
+		t.Errorf("Expected plan %q with a destructive removal, got %+v", expected, result.Plan)
+	}
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run in dry run mode, got: %v", commands)
+	}
+
+	var notFound *errs.SoftwareNotFoundError
+	if err := NewRuntimeProvider("podman").Execute(runner.WithQuiet(context.Background()), ActionInstall, "redis"); !errors.As(err, &notFound) {
+		t.Errorf("Expected software without a container image not to be found, got %v", err)
+	}
+}
+
+// TestComposeProvider tests the compose commands of each action and the
+// compose file generated from the services of the saidata
+func TestComposeProvider(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SAI_STATE_DIR", dir)
+	file := filepath.Join(dir, "compose", "monitoring", "compose.yaml")
+
+	sw := &data.Software{Name: "grafana", Compose: &data.Compose{
+		Project: "monitoring",
+		Services: map[string]data.Container{
+			"grafana":    {Image: "docker.io/grafana/grafana", Tag: "11.1.0", Ports: []string{"3000:3000"}, Volumes: []string{"grafana-data:/var/lib/grafana"}},
+			"prometheus": {Image: "docker.io/prom/prometheus", Volumes: []string{"./prometheus.yml:/etc/prometheus/prometheus.yml"}},
+		},
+	}}
+	base := "docker compose -p monitoring -f " + file
+
+	probe := "docker compose version"
+
+	testCases := []struct {
+		name     string
+		action   string
+		vars     map[string]string
+		missing  []string
+		expected []string
+	}{
+		{"Install", ActionInstall, nil, nil, []string{probe, base + " up -d"}},
+		{"Start", ActionStart, nil, nil, []string{probe, base + " up -d"}},
+		{"Stop", ActionStop, nil, nil, []string{probe, base + " stop"}},
+		{"Restart", ActionRestart, nil, nil, []string{probe, base + " restart"}},
+		{"Logs", ActionLogs, nil, nil, []string{probe, base + " logs"}},
+		{"Status", ActionStatus, nil, nil, []string{probe, base + " ps --quiet --all"}},
+		{"Upgrade", ActionUpgrade, nil, nil, []string{probe, base + " pull", base + " up -d"}},
+		{"Env File", ActionStop, map[string]string{"env_file": "/etc/monitoring.env"}, nil, []string{probe, base + " --env-file /etc/monitoring.env stop"}},
+		{"Project", ActionInstall, map[string]string{"project": "staging"}, nil, []string{
+			probe, "docker compose -p staging -f " + filepath.Join(dir, "compose", "staging", "compose.yaml") + " up -d",
+		}},
+		{"Podman Compose", ActionStatus, nil, []string{"docker"}, []string{"podman-compose -p monitoring -f " + file + " ps --quiet"}},
+		{"Docker Without Compose Plugin", ActionStop, nil, nil, []string{probe, "podman-compose -p monitoring -f " + file + " stop"}},
+		{"Docker Compose Standalone", ActionStop, nil, []string{"docker", "podman-compose"}, []string{"docker-compose -p monitoring -f " + file + " stop"}},
+		{"Uninstall", ActionUninstall, nil, nil, []string{probe, base + " down -v"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			recorder.Missing = tc.missing
+			recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+				if tc.name == "Docker Without Compose Plugin" && strings.Join(cmd.Argv(), " ") == probe {
+					result := &runner.Result{Command: cmd, ExitCode: 1, Stderr: "docker: 'compose' is not a docker command.\n"}
+					return result, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 1}
+				}
+				return &runner.Result{Command: cmd, Stdout: "3f1c2a\n"}, nil
+			}
+			ctx := runner.WithQuiet(runner.WithRunner(data.WithSoftware(context.Background(), sw), recorder))
+			if tc.vars != nil {
+				ctx = definition.WithVariables(ctx, tc.vars)
+			}
+			if err := NewComposeProvider().Execute(ctx, tc.action, "grafana"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var commands []string
+			for _, cmd := range recorder.Commands() {
+				commands = append(commands, strings.Join(cmd.Argv(), " "))
+			}
+			if strings.Join(commands, "\n") != strings.Join(tc.expected, "\n") {
+				t.Errorf("Expected commands %q, got %q", tc.expected, commands)
+			}
+		})
+	}
+
+	// The uninstall case removed the project directory
+	if _, err := os.Stat(filepath.Dir(file)); !os.IsNotExist(err) {
+		t.Errorf("Expected the generated project to be removed on uninstall, got %v", err)
+	}
+
+	recorder := runner.NewRecorder()
+	ctx := runner.WithQuiet(runner.WithRunner(data.WithSoftware(context.Background(), sw), recorder))
+	if err := NewComposeProvider().Execute(ctx, ActionInstall, "grafana"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Expected a generated compose file: %v", err)
+	}
+	var generated struct {
+		Name     string
+		Services map[string]struct {
+			Image   string
+			Restart string
+			Ports   []string
+		}
+		Volumes map[string]any
+	}
+	if err := yaml.Unmarshal(content, &generated); err != nil {
+		t.Fatalf("Invalid compose file: %v", err)
+	}
+	grafana := generated.Services["grafana"]
+	if generated.Name != "monitoring" || grafana.Image != "docker.io/grafana/grafana:11.1.0" || grafana.Restart != "unless-stopped" ||
+		len(grafana.Ports) != 1 || generated.Services["prometheus"].Image != "docker.io/prom/prometheus" {
+		t.Errorf("Unexpected compose file:\n%s", content)
+	}
+	if _, ok := generated.Volumes["grafana-data"]; !ok || len(generated.Volumes) != 1 {
+		t.Errorf("Expected only the named volume to be declared, got %v", generated.Volumes)
+	}
+}
+
+// TestComposeFile tests locating the compose file of the saidata or of the
+// compose directory, and the status of projects without containers
+func TestComposeFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SAI_STATE_DIR", dir)
+	project := filepath.Join(dir, "projects", "wiki")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "docker-compose.yml"), []byte("services: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := runner.NewRecorder()
+	sw := &data.Software{Name: "wiki", Compose: &data.Compose{File: project, EnvFile: "/srv/wiki/.env"}}
+	ctx := runner.WithQuiet(runner.WithRunner(data.WithSoftware(context.Background(), sw), recorder))
+	if err := NewComposeProvider().Execute(ctx, ActionUninstall, "wiki"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "docker compose -p wiki -f " + filepath.Join(project, "docker-compose.yml") + " --env-file /srv/wiki/.env down -v"
+	if commands := recorder.Commands(); len(commands) != 2 || strings.Join(commands[1].Argv(), " ") != expected {
+		t.Errorf("Expected %q, got %v", expected, commands)
+	}
+	if _, err := os.Stat(project); err != nil {
+		t.Errorf("Expected a compose file of the saidata to be kept on uninstall, got %v", err)
+	}
+
+	var notFound *errs.SoftwareNotFoundError
+	ctx = runner.WithQuiet(runner.WithRunner(context.Background(), recorder))
+	if err := NewComposeProvider().Execute(ctx, ActionStart, "wiki"); !errors.As(err, &notFound) {
+		t.Errorf("Expected software without a compose file not to be found, got %v", err)
+	}
+	if err := NewComposeProvider().Execute(ctx, ActionInstall, "wiki"); !errors.As(err, &notFound) {
+		t.Errorf("Expected software without a compose file not to be found, got %v", err)
+	}
+
+	// Compose files placed in the compose directory serve software without saidata
+	if err := os.MkdirAll(filepath.Join(ComposeDir(), "wiki"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ComposeDir(), "wiki", "compose.yml"), []byte("services: {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recorder.Reset()
+	if err := NewComposeProvider().Execute(ctx, ActionStatus, "wiki"); !errors.As(err, &notFound) {
+		t.Errorf("Expected a project without containers not to be found, got %v", err)
+	}
+	expected = "docker compose -p wiki -f " + filepath.Join(ComposeDir(), "wiki", "compose.yml") + " ps --quiet --all"
+	if commands := recorder.Commands(); len(commands) != 2 || strings.Join(commands[1].Argv(), " ") != expected {
+		t.Errorf("Expected %q, got %v", expected, commands)
+	}
+
+	var unavailable *errs.ProviderUnavailableError
+	missing := runner.NewRecorder()
+	missing.Missing = []string{"docker", "podman-compose", "docker-compose"}
+	ctx = runner.WithQuiet(runner.WithRunner(context.Background(), missing))
+	if err := NewComposeProvider().Execute(ctx, ActionStart, "wiki"); !errors.As(err, &unavailable) {
+		t.Errorf("Expected a provider unavailable error without compose, got %v", err)
+	}
+
+	var pinning *errs.VersionPinningError
+	ctx = definition.WithVariables(ctx, map[string]string{"version": "1.0"})
+	if err := NewComposeProvider().Execute(ctx, ActionInstall, "wiki"); !errors.As(err, &pinning) {
+		t.Errorf("Expected a version pinning error, got %v", err)
+	}
+}
+
+// TestComposeDryRun tests that dry runs plan writing the compose file and the
+// compose commands without writing or running anything
+func TestComposeDryRun(t *testing.T) {
+	SetDryRun(true)
+	defer SetDryRun(false)
+	dir := t.TempDir()
+	t.Setenv("SAI_STATE_DIR", dir)
+	file := filepath.Join(dir, "compose", "redis", "compose.yaml")
+
+	sw := &data.Software{Name: "redis", Compose: &data.Compose{Services: map[string]data.Container{"redis": {Image: "docker.io/library/redis", Tag: "7"}}}}
+	recorder := runner.NewRecorder()
+	testCases := []struct {
+		action   string
+		expected []string
+	}{
+		{ActionUpgrade, []string{"write " + file, "docker compose -p redis -f " + file + " pull", "docker compose -p redis -f " + file + " up -d"}},
+		{ActionStatus, []string{"docker compose -p redis -f " + file + " ps"}},
+		{ActionUninstall, []string{"docker compose -p redis -f " + file + " down -v"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.action, func(t *testing.T) {
+			result := output.NewResult("redis", tc.action)
+			ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(data.WithSoftware(context.Background(), sw), recorder)), result)
+			if err := NewComposeProvider().Execute(ctx, tc.action, "redis"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var plan []string
+			for _, step := range result.Plan {
+				plan = append(plan, step.Command)
+			}
+			if strings.Join(plan, "\n") != strings.Join(tc.expected, "\n") {
+				t.Errorf("Expected plan %q, got %q", tc.expected, plan)
+			}
+			if tc.action == ActionUninstall && !result.Plan[0].Destructive {
+				t.Errorf("Expected the removal of the project to be destructive")
+			}
+		})
+	}
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run in dry run mode, got: %v", commands)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("Expected no compose file to be written in dry run mode, got %v", err)
+	}
+
+	var notFound *errs.SoftwareNotFoundError
+	ctx := runner.WithQuiet(runner.WithRunner(context.Background(), recorder))
+	if err := NewComposeProvider().Execute(ctx, ActionStart, "wiki"); !errors.As(err, &notFound) {
+		t.Errorf("Expected software without a compose file not to be found in dry run mode, got %v", err)
+	}
+}
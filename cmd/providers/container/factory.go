@@ -1,14 +1,44 @@
 package container
 
-// NewProvider creates the appropriate container provider based on the name
+import "sai/cmd/providers/definition"
+
+// NewContainerProvider creates a container provider from its definition
+func NewContainerProvider(d *definition.Definition) *BaseContainerProvider {
+	return &BaseContainerProvider{Name: d.Name, Definition: d}
+}
+
+// NewHelmProvider creates a new Helm provider
+func NewHelmProvider() *BaseContainerProvider {
+	return NewContainerProvider(definition.Builtin("helm"))
+}
+
+// NewKubectlProvider creates a new Kubectl provider
+func NewKubectlProvider() *BaseContainerProvider {
+	return NewContainerProvider(definition.Builtin("kubectl"))
+}
+
+// NewRuntimeProvider creates a new docker or podman provider
+func NewRuntimeProvider(name string) *RuntimeProvider {
+	return &RuntimeProvider{Name: name}
+}
+
+// NewComposeProvider creates a new compose provider
+func NewComposeProvider() *ComposeProvider {
+	return &ComposeProvider{Name: "compose"}
+}
+
+// NewProvider creates the container provider with the given name, custom
+// definitions included
 func NewProvider(name string) Provider {
-	switch name {
-	case "helm":
-		return NewHelmProvider()
-	case "kubectl":
-		return NewKubectlProvider()
-	default:
-		// Return Kubectl provider as default
-		return NewKubectlProvider()
+	if name == "compose" {
+		return NewComposeProvider()
+	}
+	if _, ok := Runtimes[name]; ok {
+		return NewRuntimeProvider(name)
+	}
+	if d, err := definition.Get(name); err == nil && d != nil && d.Type == "container" {
+		return NewContainerProvider(d)
 	}
+	// Return Kubectl provider as default
+	return NewKubectlProvider()
 }
@@ -1,8 +1,15 @@
 package container
 
+import (
+	"context"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/errs"
+)
+
 // Provider interface defines methods for container provider implementations
 type Provider interface {
-	Execute(action, resource string) error
+	Execute(ctx context.Context, action, resource string) error
 	GetContainerTool() string
 	IsDryRun() bool
 }
@@ -59,9 +66,10 @@ func SetDryRun(enabled bool) {
 	isDryRunMode = enabled
 }
 
-// BaseContainerProvider common functionality for container providers
+// BaseContainerProvider runs container actions described by a provider definition
 type BaseContainerProvider struct {
-	Name string
+	Name       string
+	Definition *definition.Definition
 }
 
 // GetContainerTool returns the container orchestration tool name
@@ -73,3 +81,18 @@ func (p *BaseContainerProvider) GetContainerTool() string {
 func (p *BaseContainerProvider) IsDryRun() bool {
 	return isDryRunMode
 }
+
+// Execute runs the command the definition maps the action to
+func (p *BaseContainerProvider) Execute(ctx context.Context, action, resource string) error {
+	// Validate action
+	if !IsValidContainerAction(action) {
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Definition.Plan(ctx, action, resource)
+	}
+
+	return p.Definition.Execute(ctx, action, resource)
+}
@@ -0,0 +1,225 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// Runtimes lists, for each container runtime provider, the runtimes it can
+// drive in order of preference. Podman accepts the commands of docker, so
+// either provider falls back to the other runtime when its own is missing.
+var Runtimes = map[string][]string{
+	"docker": {"docker", "podman"},
+	"podman": {"podman", "docker"},
+}
+
+// RuntimeProvider runs the container image of the saidata as a named
+// container on the local host, with docker or podman
+type RuntimeProvider struct {
+	Name string
+}
+
+// GetContainerTool returns the container runtime name
+func (p *RuntimeProvider) GetContainerTool() string {
+	return p.Name
+}
+
+// IsDryRun checks if dry run mode is enabled
+func (p *RuntimeProvider) IsDryRun() bool {
+	return isDryRunMode
+}
+
+// runtime returns the first runtime of the provider found in the PATH, or
+// the runtime named after the provider when none is found
+func (p *RuntimeProvider) runtime(ctx context.Context) string {
+	for _, rt := range Runtimes[p.Name] {
+		if _, err := runner.LookPath(ctx, rt); err == nil {
+			if rt != p.Name && !runner.IsQuiet(ctx) {
+				fmt.Printf("%s not found, using %s\n", p.Name, rt)
+			}
+			return rt
+		}
+	}
+	return p.Name
+}
+
+// containerName returns the name of the container of the software
+func containerName(sw *data.Software, software string) string {
+	if sw != nil && sw.Container != nil && sw.Container.Name != "" {
+		return sw.Container.Name
+	}
+	return software
+}
+
+// Execute performs an action on the container of the software. Install
+// pulls the image and creates the container, upgrade pulls the image again
+// and recreates the container, uninstall removes the container but keeps
+// its named volumes.
+func (p *RuntimeProvider) Execute(ctx context.Context, action, software string) error {
+	sw := data.FromContext(ctx)
+	rt := p.runtime(ctx)
+	name := containerName(sw, software)
+
+	switch action {
+	case ActionInstall, ActionUpgrade:
+		return p.install(ctx, rt, action, name, software, sw)
+	case ActionStart, ActionStop, ActionRestart:
+		_, err := p.run(ctx, runner.NewCommand(rt, action, name), false)
+		return err
+	case ActionLogs:
+		_, err := p.run(ctx, runner.NewCommand(rt, "logs", name), false)
+		return err
+	case ActionStatus:
+		return p.status(ctx, rt, name)
+	case ActionUninstall:
+		_, err := p.run(ctx, runner.NewCommand(rt, "rm", "--force", name), true)
+		return err
+	default:
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+}
+
+// runArgs returns the arguments creating the container of the image
+func runArgs(c *data.Container, name, image string) []string {
+	args := []string{"run", "--detach", "--name", name, "--restart", "unless-stopped"}
+	for _, port := range c.Ports {
+		args = append(args, "--publish", port)
+	}
+	for _, volume := range c.Volumes {
+		args = append(args, "--volume", volume)
+	}
+	keys := make([]string, 0, len(c.Env))
+	for key := range c.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--env", key+"="+c.Env[key])
+	}
+	return append(args, image)
+}
+
+// install pulls the image and creates the container. An existing container
+// is kept on install and replaced on upgrade.
+func (p *RuntimeProvider) install(ctx context.Context, rt, action, name, software string, sw *data.Software) error {
+	if sw == nil || sw.Container == nil || sw.Container.Image == "" {
+		return &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+	}
+	c := *sw.Container
+	if version := definition.VariablesFromContext(ctx)["version"]; version != "" {
+		c.Tag = version
+	}
+	image := c.Reference()
+
+	exists := false
+	if !p.IsDryRun() {
+		inspected, err := p.inspect(ctx, rt, name)
+		if err != nil {
+			return err
+		}
+		exists = inspected != ""
+	}
+	if exists && action == ActionInstall {
+		fmt.Printf("Container %s already exists\n", name)
+		return nil
+	}
+
+	if _, err := p.run(ctx, runner.NewCommand(rt, "pull", image), false); err != nil {
+		return err
+	}
+	if exists || (p.IsDryRun() && action == ActionUpgrade) {
+		if _, err := p.run(ctx, runner.NewCommand(rt, "rm", "--force", name), true); err != nil {
+			return err
+		}
+	}
+	if _, err := p.run(ctx, runner.NewCommand(rt, runArgs(&c, name, image)...), false); err != nil {
+		return err
+	}
+	if !p.IsDryRun() {
+		output.FromContext(ctx).Set("version", imageTag(image))
+	}
+	return nil
+}
+
+// inspect returns the state and image of the container, or an empty string
+// when it does not exist
+func (p *RuntimeProvider) inspect(ctx context.Context, rt, name string) (string, error) {
+	cmd := runner.NewCommand(rt, "container", "inspect", "--format", "{{.State.Status}} {{.Config.Image}}", name)
+	cmd.Quiet = true
+	result, err := runner.Run(ctx, cmd)
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found in PATH", rt)}
+	}
+	if err != nil {
+		if result != nil && strings.Contains(strings.ToLower(result.Stderr), "no such") {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+// status reports whether the container exists, its state and the tag of its
+// image as the installed version
+func (p *RuntimeProvider) status(ctx context.Context, rt, name string) error {
+	if p.IsDryRun() {
+		_, err := p.run(ctx, runner.NewCommand(rt, "container", "inspect", name), false)
+		return err
+	}
+	inspected, err := p.inspect(ctx, rt, name)
+	if err != nil {
+		return err
+	}
+	state, image, _ := strings.Cut(inspected, " ")
+	if state == "" {
+		return &errs.SoftwareNotFoundError{Software: name, Provider: p.Name}
+	}
+	output.FromContext(ctx).Set("version", imageTag(image))
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("Container %s is %s (%s)\n", name, state, image)
+	}
+	return nil
+}
+
+// imageTag returns the tag of an image reference, latest when it has none
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
+
+// run runs a runtime command, or adds it to the plan in dry run mode
+func (p *RuntimeProvider) run(ctx context.Context, cmd runner.Command, destructive bool) (*runner.Result, error) {
+	if p.IsDryRun() {
+		step := output.NewStep(cmd, false, destructive)
+		output.FromContext(ctx).AddStep(step)
+		if !runner.IsQuiet(ctx) {
+			fmt.Printf("[DRY RUN] Would run: %s\n", step)
+		}
+		return nil, nil
+	}
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("Running: %s\n", cmd.String())
+	}
+	result, err := runner.Run(ctx, cmd)
+	if errors.Is(err, exec.ErrNotFound) {
+		return result, &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found in PATH", cmd.Name)}
+	}
+	// The container is the last argument of the commands acting on it
+	var commandErr *errs.CommandFailedError
+	if errors.As(err, &commandErr) && result != nil && strings.Contains(strings.ToLower(result.Stderr), "no such container") {
+		return result, fmt.Errorf("%w: %w", &errs.SoftwareNotFoundError{Software: cmd.Args[len(cmd.Args)-1], Provider: p.Name}, err)
+	}
+	return result, err
+}
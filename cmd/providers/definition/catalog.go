@@ -0,0 +1,194 @@
+package definition
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Catalog is a collection of provider definitions indexed by name
+type Catalog struct {
+	definitions map[string]*Definition
+}
+
+// NewCatalog creates an empty catalog
+func NewCatalog() *Catalog {
+	return &Catalog{definitions: make(map[string]*Definition)}
+}
+
+// Add adds a definition to the catalog, replacing any definition with the same name
+func (c *Catalog) Add(d *Definition) {
+	c.definitions[d.Name] = d
+}
+
+// Get returns the definition with the given name
+func (c *Catalog) Get(name string) (*Definition, bool) {
+	d, ok := c.definitions[name]
+	return d, ok
+}
+
+// All returns all definitions sorted by name
+func (c *Catalog) All() []*Definition {
+	all := make([]*Definition, 0, len(c.definitions))
+	for _, d := range c.definitions {
+		all = append(all, d)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// LoadFile loads a provider definition from a YAML file
+func (c *Catalog) LoadFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return c.load(path, content)
+}
+
+// LoadDir loads every YAML file in a directory
+func (c *Catalog) LoadDir(dir string) error {
+	return c.loadFS(os.DirFS(dir), dir)
+}
+
+// loadFS loads every YAML file at the root of a filesystem
+func (c *Catalog) loadFS(fsys fs.FS, label string) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isDefinitionFile(entry.Name()) {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return err
+		}
+		if err := c.load(filepath.Join(label, entry.Name()), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load decodes a definition file and adds it to the catalog
+func (c *Catalog) load(path string, content []byte) error {
+	d, err := Parse(content)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	c.Add(d)
+	return nil
+}
+
+// isDefinitionFile reports whether the file name has a YAML extension
+func isDefinitionFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+//go:embed builtin/*.yaml
+var builtinDefinitions embed.FS
+
+// SearchPath returns the directories searched for custom provider
+// definitions, in load order. Later directories override definitions from
+// earlier ones.
+func SearchPath() []string {
+	dirs := []string{"/etc/sai/providers"}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "sai", "providers"))
+	}
+	if env := os.Getenv("SAI_PROVIDER_PATH"); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	return dirs
+}
+
+// Built-in and global catalogs
+var (
+	builtin     = NewCatalog()
+	builtinOnce sync.Once
+
+	catalog     = NewCatalog()
+	catalogOnce sync.Once
+	catalogErr  error
+)
+
+// loadBuiltin loads the embedded definitions, which are part of the binary
+// and therefore always valid
+func loadBuiltin() {
+	builtinOnce.Do(func() {
+		sub, err := fs.Sub(builtinDefinitions, "builtin")
+		if err == nil {
+			err = builtin.loadFS(sub, "builtin")
+		}
+		if err != nil {
+			panic(fmt.Sprintf("invalid built-in provider definitions: %v", err))
+		}
+	})
+}
+
+// loadCatalog loads the built-in definitions and the search path into the
+// global catalog
+func loadCatalog() error {
+	catalogOnce.Do(func() {
+		loadBuiltin()
+		for _, d := range builtin.All() {
+			catalog.Add(d)
+		}
+		for _, dir := range SearchPath() {
+			if _, statErr := os.Stat(dir); statErr != nil {
+				continue
+			}
+			if err := catalog.LoadDir(dir); err != nil {
+				catalogErr = err
+				return
+			}
+		}
+	})
+	return catalogErr
+}
+
+// Builtin returns the built-in definition with the given name, ignoring
+// custom definitions. It panics for unknown names.
+func Builtin(name string) *Definition {
+	loadBuiltin()
+	d, ok := builtin.Get(name)
+	if !ok {
+		panic(fmt.Sprintf("no built-in provider definition %q", name))
+	}
+	return d
+}
+
+// Builtins returns all built-in definitions sorted by name
+func Builtins() []*Definition {
+	loadBuiltin()
+	return builtin.All()
+}
+
+// Get returns the definition with the given name from the global catalog.
+// The definition is nil when there is none.
+func Get(name string) (*Definition, error) {
+	if err := loadCatalog(); err != nil {
+		return nil, err
+	}
+	d, _ := catalog.Get(name)
+	return d, nil
+}
+
+// All returns all definitions in the global catalog
+func All() ([]*Definition, error) {
+	if err := loadCatalog(); err != nil {
+		return nil, err
+	}
+	return catalog.All(), nil
+}
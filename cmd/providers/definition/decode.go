@@ -0,0 +1,95 @@
+package definition
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// decoders decode the output of a successful query into result keys, for
+// outputs that regular expressions cannot handle, such as the JSON listing of
+// a whole profile. They report whether the target was found in the output.
+var decoders = map[string]func(stdout, target string) (map[string]string, bool, error){
+	"nix-profile": decodeNixProfile,
+}
+
+// nixElement is an element of the JSON listing of a Nix profile
+type nixElement struct {
+	AttrPath   string   `json:"attrPath"`
+	StorePaths []string `json:"storePaths"`
+}
+
+// decodeNixProfile finds the package in the output of nix profile list
+// --json, whose elements are a list before Nix 2.20 and a map keyed by
+// their names since, and reports the version of its store path
+func decodeNixProfile(stdout, target string) (map[string]string, bool, error) {
+	var profile struct {
+		Elements json.RawMessage `json:"elements"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &profile); err != nil {
+		return nil, false, fmt.Errorf("decoding the nix profile: %w", err)
+	}
+
+	var elements []nixElement
+	var named map[string]nixElement
+	if err := json.Unmarshal(profile.Elements, &named); err == nil {
+		if element, ok := named[target]; ok {
+			return nixValues(element), true, nil
+		}
+		for _, element := range named {
+			elements = append(elements, element)
+		}
+	} else if err := json.Unmarshal(profile.Elements, &elements); err != nil {
+		return nil, false, fmt.Errorf("decoding the nix profile: %w", err)
+	}
+
+	// Elements are otherwise matched by the last part of their attribute path
+	for _, element := range elements {
+		if _, attr, _ := cutLast(element.AttrPath, "."); attr == target {
+			return nixValues(element), true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// nixValues returns the result keys of a profile element
+func nixValues(element nixElement) map[string]string {
+	values := make(map[string]string)
+	if len(element.StorePaths) > 0 {
+		if version := nixStoreVersion(element.StorePaths[0]); version != "" {
+			values["version"] = version
+		}
+	}
+	return values
+}
+
+// nixStoreVersion returns the version of a store path such as
+// /nix/store/<hash>-jq-1.7.1-bin: like Nix, the version starts at the first
+// dash followed by a digit, and the name of the output is dropped
+func nixStoreVersion(storePath string) string {
+	_, name, ok := strings.Cut(path.Base(storePath), "-")
+	if !ok {
+		return ""
+	}
+	for i := 0; i < len(name)-1; i++ {
+		if name[i] != '-' || !unicode.IsDigit(rune(name[i+1])) {
+			continue
+		}
+		version := name[i+1:]
+		for _, output := range []string{"-bin", "-out", "-lib", "-dev", "-man", "-doc"} {
+			version = strings.TrimSuffix(version, output)
+		}
+		return version
+	}
+	return ""
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}
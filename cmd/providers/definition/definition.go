@@ -0,0 +1,531 @@
+// Package definition describes providers as data: for every action a
+// command template whose arguments are rendered from variables such as the
+// package name, version, namespace or region, plus optional parsers that
+// extract details from the output of the command.
+//
+// The built-in providers are defined by the YAML files embedded from the
+// builtin directory. Custom definitions are loaded from the search path and
+// override built-in definitions with the same name.
+package definition
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Provider types a definition may declare
+var Types = []string{"os", "container", "cloud", "language"}
+
+// Definition describes a provider
+type Definition struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is the provider type: os, container, cloud or language
+	Type string `yaml:"type" json:"type"`
+	// DisplayName is used in messages, defaulting to the name
+	DisplayName string `yaml:"display_name,omitempty" json:"display_name,omitempty"`
+	// Binaries lists the executables backing the provider; the first one
+	// found is used to tell whether the provider is available
+	Binaries []string `yaml:"binaries" json:"binaries"`
+	// VersionArgs are the arguments printing the version of the binary
+	VersionArgs []string `yaml:"version_args,omitempty" json:"version_args,omitempty"`
+	// NeedsRoot is set for providers whose changes require administrator privileges
+	NeedsRoot bool `yaml:"needs_root,omitempty" json:"needs_root,omitempty"`
+	// Variables are the default values of template variables
+	Variables map[string]string `yaml:"variables,omitempty" json:"variables,omitempty"`
+	// GlobalArgs are inserted right after the program name of every command
+	// running the given program, before the action arguments
+	GlobalArgs map[string][]string `yaml:"global_args,omitempty" json:"global_args,omitempty"`
+	// NotFound lists output fragments printed when the requested software
+	// does not exist or is not installed
+	NotFound []string `yaml:"not_found,omitempty" json:"not_found,omitempty"`
+	// Actions maps action names to their command templates
+	Actions map[string]*Action `yaml:"actions" json:"actions"`
+}
+
+// Action describes the command run for an action. It has either a single
+// argv template or a list of variants, the first matching one being used.
+type Action struct {
+	Argv     []string  `yaml:"argv,omitempty" json:"argv,omitempty"`
+	Variants []Variant `yaml:"variants,omitempty" json:"variants,omitempty"`
+	// Env lists KEY=value templates added to the environment of the command
+	Env []string `yaml:"env,omitempty" json:"env,omitempty"`
+	// Destructive is set for actions removing software or resources
+	Destructive bool `yaml:"destructive,omitempty" json:"destructive,omitempty"`
+	// NeedsRoot overrides the needs_root of the provider, for queries that
+	// need no privileges even when the changes of the provider do
+	NeedsRoot *bool `yaml:"needs_root,omitempty" json:"needs_root,omitempty"`
+	// Parse maps result keys to regular expressions applied to the output
+	// of a successful command; the first group of a match is the value.
+	// Patterns may refer to the target as {{.package}}, quoted.
+	Parse map[string]string `yaml:"parse,omitempty" json:"parse,omitempty"`
+	// SuccessCodes lists the exit codes besides 0 that mean success, such
+	// as 100 for dnf check-update when updates are available
+	SuccessCodes []int `yaml:"success_codes,omitempty" json:"success_codes,omitempty"`
+	// RequireOutput is set for queries that succeed silently when the
+	// software is missing; no output then means it was not found
+	RequireOutput bool `yaml:"require_output,omitempty" json:"require_output,omitempty"`
+	// RequireMatch is set for queries listing everything installed; no
+	// parser matching the output then means the target was not found
+	RequireMatch bool `yaml:"require_match,omitempty" json:"require_match,omitempty"`
+	// Decode names a built-in decoder of the output, for queries whose
+	// output the parsers cannot handle; the target not being found in the
+	// output means it is not installed
+	Decode string `yaml:"decode,omitempty" json:"decode,omitempty"`
+
+	parsers map[string]*template.Template
+	pins    bool
+}
+
+// succeedsWith reports whether a non-zero exit code means success
+func (a *Action) succeedsWith(code int) bool {
+	for _, c := range a.SuccessCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Variant is an argv template used when all its conditions hold
+type Variant struct {
+	// When maps variable names to the values they must have
+	When map[string]string `yaml:"when,omitempty" json:"when,omitempty"`
+	Argv []string          `yaml:"argv" json:"argv"`
+}
+
+// Title returns the name of the provider used in messages
+func (d *Definition) Title() string {
+	if d.DisplayName != "" {
+		return d.DisplayName
+	}
+	return d.Name
+}
+
+// Supports reports whether the definition has a template for the action
+func (d *Definition) Supports(action string) bool {
+	_, ok := d.Actions[action]
+	return ok
+}
+
+// Parse decodes a YAML definition and validates it
+func Parse(content []byte) (*Definition, error) {
+	d := &Definition{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(d); err != nil {
+		return nil, err
+	}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// validate checks the definition and compiles its templates and parsers
+func (d *Definition) validate() error {
+	if d.Name == "" {
+		return errors.New("provider definition without a name")
+	}
+	if !isType(d.Type) {
+		return fmt.Errorf("provider %s: unsupported type %q (supported: %s)", d.Name, d.Type, strings.Join(Types, ", "))
+	}
+	if len(d.Binaries) == 0 {
+		return fmt.Errorf("provider %s: no binaries", d.Name)
+	}
+	if len(d.Actions) == 0 {
+		return fmt.Errorf("provider %s: no actions", d.Name)
+	}
+	for _, args := range d.GlobalArgs {
+		if err := checkTemplates(args); err != nil {
+			return fmt.Errorf("provider %s: global args: %w", d.Name, err)
+		}
+	}
+	for name, action := range d.Actions {
+		if action == nil || (len(action.Argv) == 0) == (len(action.Variants) == 0) {
+			return fmt.Errorf("provider %s: action %s needs either argv or variants", d.Name, name)
+		}
+		for _, env := range action.Env {
+			if !strings.Contains(env, "=") {
+				return fmt.Errorf("provider %s: action %s: environment entry %q is not KEY=value", d.Name, name, env)
+			}
+		}
+		argvs := [][]string{action.Argv, action.Env}
+		for _, variant := range action.Variants {
+			argvs = append(argvs, variant.Argv)
+		}
+		for _, argv := range argvs {
+			if err := checkTemplates(argv); err != nil {
+				return fmt.Errorf("provider %s: action %s: %w", d.Name, name, err)
+			}
+			action.pins = action.pins || usesVersion(argv)
+		}
+		if _, ok := decoders[action.Decode]; action.Decode != "" && !ok {
+			return fmt.Errorf("provider %s: action %s: unknown decoder %q", d.Name, name, action.Decode)
+		}
+		if action.RequireMatch && len(action.Parse) == 0 {
+			return fmt.Errorf("provider %s: action %s requires a match but has no parsers", d.Name, name)
+		}
+		action.parsers = make(map[string]*template.Template, len(action.Parse))
+		for key, pattern := range action.Parse {
+			tmpl, err := newTemplate(pattern)
+			if err != nil {
+				return fmt.Errorf("provider %s: action %s: parser %s: %w", d.Name, name, key, err)
+			}
+			re, err := parser(tmpl, "target")
+			if err != nil {
+				return fmt.Errorf("provider %s: action %s: parser %s: %w", d.Name, name, key, err)
+			}
+			if re.NumSubexp() < 1 {
+				return fmt.Errorf("provider %s: action %s: parser %s has no group", d.Name, name, key)
+			}
+			action.parsers[key] = tmpl
+		}
+	}
+	return nil
+}
+
+// parser renders the pattern of a parser for the target and compiles it
+func parser(tmpl *template.Template, target string) (*regexp.Regexp, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, map[string]string{"package": regexp.QuoteMeta(target)}); err != nil {
+		return nil, err
+	}
+	return regexp.Compile(buf.String())
+}
+
+// usesVersion reports whether any argument template refers to the version
+func usesVersion(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, ".version") {
+			return true
+		}
+	}
+	return false
+}
+
+// Pins reports whether the action can install a specific version, that is
+// whether its templates use the version variable
+func (d *Definition) Pins(action string) bool {
+	a, ok := d.Actions[action]
+	return ok && a.pins
+}
+
+// isType reports whether t is a supported provider type
+func isType(t string) bool {
+	for _, supported := range Types {
+		if supported == t {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTemplates parses every argument template
+func checkTemplates(args []string) error {
+	for _, arg := range args {
+		if _, err := newTemplate(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateFuncs are the functions available to argument templates
+var templateFuncs = template.FuncMap{
+	"majorMinor": majorMinor,
+	"goBinary":   goBinary,
+}
+
+// majorMinor shortens a version to its first two components: 1.24.0 becomes 1.24
+func majorMinor(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// goBinary returns the name of the binary go install builds from a package
+// path: its last element, skipping a major version suffix such as /v2
+func goBinary(pkg string) string {
+	elements := strings.Split(strings.TrimSuffix(pkg, "/"), "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elements[len(elements)-2]
+	}
+	return name
+}
+
+// newTemplate parses an argument template; missing variables render empty
+func newTemplate(text string) (*template.Template, error) {
+	return template.New("arg").Option("missingkey=zero").Funcs(templateFuncs).Parse(text)
+}
+
+// render renders argument templates, dropping arguments that render empty so
+// that optional flags can be written as {{if .profile}}--profile{{end}}
+func render(args []string, vars map[string]string) ([]string, error) {
+	rendered := make([]string, 0, len(args))
+	for _, arg := range args {
+		tmpl, err := newTemplate(arg)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, vars); err != nil {
+			return nil, err
+		}
+		if buf.Len() > 0 {
+			rendered = append(rendered, buf.String())
+		}
+	}
+	return rendered, nil
+}
+
+type variablesKey struct{}
+
+// WithVariables returns a context carrying template variables that take
+// precedence over the defaults of the definitions
+func WithVariables(ctx context.Context, vars map[string]string) context.Context {
+	merged := make(map[string]string)
+	for key, value := range VariablesFromContext(ctx) {
+		merged[key] = value
+	}
+	for key, value := range vars {
+		merged[key] = value
+	}
+	return context.WithValue(ctx, variablesKey{}, merged)
+}
+
+// VariablesFromContext returns the template variables attached to the context
+func VariablesFromContext(ctx context.Context) map[string]string {
+	vars, _ := ctx.Value(variablesKey{}).(map[string]string)
+	return vars
+}
+
+// Vars returns the variables available to the templates of an action
+// on the target: the defaults of the definition, the target itself, the
+// Helm chart, snap, flatpak and nix settings of the software and the
+// variables attached to the context, in increasing order of precedence.
+//
+// The target is available as package and resource; a target of the form
+// type/name is also split into resource_type and resource_name. The home
+// directory of the user, for per-user installs, is available as home.
+func (d *Definition) Vars(ctx context.Context, target string) map[string]string {
+	vars := make(map[string]string)
+	for key, value := range d.Variables {
+		vars[key] = value
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		vars["home"] = home
+	}
+
+	vars["package"] = target
+	vars["resource"] = target
+	vars["resource_type"], vars["resource_name"], _ = strings.Cut(target, "/")
+
+	if sw := data.FromContext(ctx); sw != nil {
+		vars["software"] = sw.Name
+		if sw.Helm != nil {
+			vars["chart"] = sw.Helm.Reference()
+			if sw.Helm.RepoURL != "" {
+				vars["chart"] = sw.Helm.Chart
+				vars["chart_repo"] = sw.Helm.RepoURL
+			}
+			vars["chart_namespace"] = sw.Helm.Namespace
+		}
+		if sw.Snap != nil {
+			setNonEmpty(vars, "channel", sw.Snap.Channel)
+			setNonEmpty(vars, "confinement", sw.Snap.Confinement)
+		}
+		if sw.Flatpak != nil {
+			setNonEmpty(vars, "remote", sw.Flatpak.Remote)
+			setNonEmpty(vars, "branch", sw.Flatpak.Branch)
+		}
+		if sw.Nix != nil {
+			setNonEmpty(vars, "flake", sw.Nix.Flake)
+			setNonEmpty(vars, "revision", sw.Nix.Revision)
+		}
+	}
+
+	for key, value := range VariablesFromContext(ctx) {
+		vars[key] = value
+	}
+	return vars
+}
+
+// setNonEmpty sets a variable unless the value is empty, keeping the default
+// of the definition
+func setNonEmpty(vars map[string]string, key, value string) {
+	if value != "" {
+		vars[key] = value
+	}
+}
+
+// Command renders the command of an action with the given variables
+func (d *Definition) Command(action string, vars map[string]string) (runner.Command, error) {
+	a, ok := d.Actions[action]
+	if !ok {
+		return runner.Command{}, &errs.UnsupportedActionError{Action: action, Provider: d.Name}
+	}
+
+	argv := a.Argv
+	if len(argv) == 0 {
+		for _, variant := range a.Variants {
+			if matches(variant.When, vars) {
+				argv = variant.Argv
+				break
+			}
+		}
+	}
+	if len(argv) == 0 {
+		return runner.Command{}, &errs.UnsupportedActionError{Action: action, Provider: d.Name, Resource: vars["resource_type"]}
+	}
+
+	rendered, err := render(argv, vars)
+	if err != nil {
+		return runner.Command{}, fmt.Errorf("provider %s: action %s: %w", d.Name, action, err)
+	}
+	if len(rendered) == 0 {
+		return runner.Command{}, fmt.Errorf("provider %s: action %s renders an empty command", d.Name, action)
+	}
+
+	cmd := runner.NewCommand(rendered[0], rendered[1:]...)
+	if global, ok := d.GlobalArgs[cmd.Name]; ok {
+		args, err := render(global, vars)
+		if err != nil {
+			return runner.Command{}, fmt.Errorf("provider %s: global args: %w", d.Name, err)
+		}
+		cmd.Args = append(args, cmd.Args...)
+	}
+	if cmd.Env, err = render(a.Env, vars); err != nil {
+		return runner.Command{}, fmt.Errorf("provider %s: action %s: env: %w", d.Name, action, err)
+	}
+	if len(cmd.Env) == 0 {
+		cmd.Env = nil
+	}
+	return cmd, nil
+}
+
+// matches reports whether every condition holds for the variables
+func matches(when, vars map[string]string) bool {
+	for key, value := range when {
+		if vars[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Execute renders and runs the command of an action on the target through
+// the runner attached to the context, translating well-known failures into
+// typed errors
+func (d *Definition) Execute(ctx context.Context, action, target string) error {
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("Executing %s %s with %s provider\n", action, target, d.Title())
+	}
+	cmd, err := d.Command(action, d.Vars(ctx, target))
+	if err != nil {
+		return err
+	}
+	return d.Run(ctx, action, cmd, target)
+}
+
+// needsRoot reports whether the command of an action requires administrator
+// privileges
+func (d *Definition) needsRoot(action string) bool {
+	if a, ok := d.Actions[action]; ok && a.NeedsRoot != nil {
+		return *a.NeedsRoot
+	}
+	return d.NeedsRoot
+}
+
+// Plan renders the command of an action on the target and adds it to the
+// execution plan of the result attached to the context, without running it
+func (d *Definition) Plan(ctx context.Context, action, target string) error {
+	cmd, err := d.Command(action, d.Vars(ctx, target))
+	if err != nil {
+		return err
+	}
+	step := output.NewStep(cmd, d.needsRoot(action), d.Actions[action].Destructive)
+	output.FromContext(ctx).AddStep(step)
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("[DRY RUN] Would run: %s\n", step)
+	}
+	return nil
+}
+
+// Run runs a rendered command of an action and applies the parsers of the
+// action to its output
+func (d *Definition) Run(ctx context.Context, action string, cmd runner.Command, target string) error {
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("Running: %s\n", cmd.String())
+	}
+	result, err := runner.Run(ctx, cmd)
+	a := d.Actions[action]
+	var commandErr *errs.CommandFailedError
+	if errors.As(err, &commandErr) && a != nil && a.succeedsWith(commandErr.ExitCode) {
+		err = nil
+	}
+	if err == nil {
+		if a != nil && result != nil {
+			if a.RequireOutput && strings.TrimSpace(result.Stdout) == "" {
+				return &errs.SoftwareNotFoundError{Software: target, Provider: d.Name}
+			}
+			matched := false
+			for key, tmpl := range a.parsers {
+				re, err := parser(tmpl, target)
+				if err != nil {
+					return fmt.Errorf("provider %s: action %s: parser %s: %w", d.Name, action, key, err)
+				}
+				if m := re.FindStringSubmatch(result.Stdout); m != nil {
+					output.FromContext(ctx).Set(key, m[1])
+					matched = true
+				}
+			}
+			if a.RequireMatch && !matched {
+				return &errs.SoftwareNotFoundError{Software: target, Provider: d.Name}
+			}
+			if a.Decode != "" {
+				values, found, err := decoders[a.Decode](result.Stdout, target)
+				if err != nil {
+					return err
+				}
+				if !found {
+					return &errs.SoftwareNotFoundError{Software: target, Provider: d.Name}
+				}
+				for key, value := range values {
+					output.FromContext(ctx).Set(key, value)
+				}
+			}
+		}
+		return nil
+	}
+
+	if errors.Is(err, exec.ErrNotFound) {
+		return &errs.ProviderUnavailableError{Provider: d.Name, Reason: fmt.Sprintf("%s not found in PATH", cmd.Name)}
+	}
+
+	if commandErr != nil && result != nil {
+		out := result.Stdout + result.Stderr
+		for _, pattern := range d.NotFound {
+			if strings.Contains(out, pattern) {
+				return fmt.Errorf("%w: %w", &errs.SoftwareNotFoundError{Software: target, Provider: d.Name}, err)
+			}
+		}
+	}
+	return err
+}
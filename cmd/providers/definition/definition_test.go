@@ -0,0 +1,511 @@
+package definition
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// TestParse tests the validation of provider definitions
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "Valid definition",
+			content: "name: acme\ntype: os\nbinaries: [acme]\nactions:\n  install:\n    argv: [acme, add, '{{.package}}']\n",
+		},
+		{
+			name:    "Missing name",
+			content: "type: os\nbinaries: [acme]\nactions:\n  install:\n    argv: [acme]\n",
+			wantErr: "without a name",
+		},
+		{
+			name:    "Unsupported type",
+			content: "name: acme\ntype: vm\nbinaries: [acme]\nactions:\n  install:\n    argv: [acme]\n",
+			wantErr: "unsupported type",
+		},
+		{
+			name:    "No actions",
+			content: "name: acme\ntype: os\nbinaries: [acme]\n",
+			wantErr: "no actions",
+		},
+		{
+			name:    "Argv and variants",
+			content: "name: acme\ntype: os\nbinaries: [acme]\nactions:\n  install:\n    argv: [acme]\n    variants:\n      - argv: [acme]\n",
+			wantErr: "either argv or variants",
+		},
+		{
+			name:    "Invalid template",
+			content: "name: acme\ntype: os\nbinaries: [acme]\nactions:\n  install:\n    argv: [acme, '{{.package']\n",
+			wantErr: "action install",
+		},
+		{
+			name:    "Parser without group",
+			content: "name: acme\ntype: os\nbinaries: [acme]\nactions:\n  status:\n    argv: [acme]\n    parse:\n      version: 'Version'\n",
+			wantErr: "has no group",
+		},
+		{
+			name:    "Unknown decoder",
+			content: "name: acme\ntype: os\nbinaries: [acme]\nactions:\n  status:\n    argv: [acme]\n    decode: acme-json\n",
+			wantErr: "unknown decoder",
+		},
+		{
+			name:    "Required match without parsers",
+			content: "name: acme\ntype: os\nbinaries: [acme]\nactions:\n  status:\n    argv: [acme, list]\n    require_match: true\n",
+			wantErr: "has no parsers",
+		},
+		{
+			name:    "Unknown field",
+			content: "name: acme\ntype: os\nbinaries: [acme]\ncommands: {}\nactions:\n  install:\n    argv: [acme]\n",
+			wantErr: "commands",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestBuiltinCommands tests the commands rendered from the built-in definitions
+func TestBuiltinCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		action   string
+		target   string
+		vars     map[string]string
+		want     string
+	}{
+		{"APT install", "apt", "install", "nginx", nil, "apt-get install -y nginx"},
+		{"Brew list", "brew", "list", "", nil, "brew list"},
+		{"Kubectl default namespace", "kubectl", "install", "app.yaml", nil, "kubectl apply -f app.yaml -n default"},
+		{"Kubectl namespace", "kubectl", "delete", "app.yaml", map[string]string{"namespace": "web"}, "kubectl delete -f app.yaml -n web"},
+		{"Helm without chart", "helm", "status", "nginx", nil, "helm status nginx"},
+		{"AWS default region", "aws", "start", "ec2/i-123", nil, "aws ec2 start-instances --instance-ids i-123 --region us-east-1"},
+		{"AWS profile", "aws", "stop", "rds/db1", map[string]string{"profile": "prod", "region": "eu-west-1"},
+			"aws --profile prod rds stop-db-instance --db-instance-identifier db1 --region eu-west-1"},
+		{"GCP storage ignores project", "gcp", "create", "storage/assets", map[string]string{"project": "p1"}, "gsutil mb gs://assets"},
+		{"GCP project", "gcp", "stop", "sql/db1", map[string]string{"project": "p1"}, "gcloud --project p1 sql instances stop db1"},
+		{"Azure delete", "azure", "delete", "vm/web1", nil, "az vm delete --name web1 --resource-group myResourceGroup --yes"},
+		{"APT pinned install", "apt", "install", "nginx", map[string]string{"version": "1.24.0"}, "apt-get install -y nginx=1.24.0"},
+		{"APT pinned upgrade", "apt", "upgrade", "nginx", map[string]string{"version": "1.24.0"},
+			"apt-get install -y --allow-downgrades nginx=1.24.0"},
+		{"APT upgrade", "apt", "upgrade", "nginx", nil, "apt-get upgrade -y nginx"},
+		{"Zypper pinned install", "zypper", "install", "nginx", map[string]string{"version": "1.24.0"}, "zypper install -y nginx-1.24.0"},
+		{"Brew pinned install", "brew", "install", "postgresql", map[string]string{"version": "16.2.0"}, "brew install postgresql@16.2"},
+		{"Winget pinned install", "winget", "install", "Git.Git", map[string]string{"version": "2.44.0"},
+			"winget install Git.Git --version 2.44.0"},
+		{"DNF pinned install", "dnf", "install", "nginx", map[string]string{"version": "1.24.0"}, "dnf install -y nginx-1.24.0"},
+		{"DNF upgrade", "dnf", "upgrade", "nginx", nil, "dnf upgrade -y nginx"},
+		{"DNF status", "dnf", "status", "nginx", nil, "dnf repoquery --installed --queryformat '%{name} %{version}-%{release}\n' nginx"},
+		{"YUM pinned upgrade", "yum", "upgrade", "nginx", map[string]string{"version": "1.20.1"}, "yum update-to -y nginx-1.20.1"},
+		{"Helm pinned install", "helm", "install", "nginx", map[string]string{"version": "15.0.0"}, "helm install nginx --version 15.0.0"},
+		{"APK pinned install", "apk", "install", "nginx", map[string]string{"version": "1.24.0-r15"}, "apk add nginx=1.24.0-r15"},
+		{"APK upgrade", "apk", "upgrade", "nginx", nil, "apk upgrade nginx"},
+		{"APK uninstall", "apk", "uninstall", "nginx", nil, "apk del nginx"},
+		{"Nix install", "nix", "install", "jq", nil, "nix --extra-experimental-features 'nix-command flakes' profile install nixpkgs#jq"},
+		{"Nix search", "nix", "search", "jq", nil, "nix --extra-experimental-features 'nix-command flakes' search nixpkgs jq"},
+		{"Snap refresh on channel", "snap", "upgrade", "code", map[string]string{"channel": "latest/beta"}, "snap refresh code --channel=latest/beta"},
+		{"Snap devmode", "snap", "install", "hello", map[string]string{"confinement": "devmode"}, "snap install hello --devmode"},
+		{"Snap strict", "snap", "install", "hello", map[string]string{"confinement": "strict"}, "snap install hello"},
+		{"Flatpak info", "flatpak", "info", "org.gimp.GIMP", nil, "flatpak remote-info flathub org.gimp.GIMP"},
+		{"Flatpak uninstall", "flatpak", "uninstall", "org.gimp.GIMP", nil, "flatpak uninstall -y org.gimp.GIMP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithVariables(context.Background(), tt.vars)
+			d := Builtin(tt.provider)
+			cmd, err := d.Command(tt.action, d.Vars(ctx, tt.target))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := cmd.String(); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestPins tests which built-in actions can install a specific version
+func TestPins(t *testing.T) {
+	tests := []struct {
+		provider string
+		action   string
+		want     bool
+	}{
+		{"apt", "install", true},
+		{"apt", "upgrade", true},
+		{"apt", "uninstall", false},
+		{"brew", "upgrade", true},
+		{"helm", "install", true},
+		{"dnf", "upgrade", true},
+		{"apk", "upgrade", true},
+		{"pacman", "install", false},
+		{"rpm", "install", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider+"_"+tt.action, func(t *testing.T) {
+			if got := Builtin(tt.provider).Pins(tt.action); got != tt.want {
+				t.Errorf("Expected Pins(%s) = %v, got %v", tt.action, tt.want, got)
+			}
+		})
+	}
+}
+
+// TestUnsupportedCommands tests actions and resource types without a template
+func TestUnsupportedCommands(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		action       string
+		target       string
+		wantResource string
+	}{
+		{"RPM upgrade", "rpm", "upgrade", "nginx", ""},
+		{"AWS start bucket", "aws", "start", "s3/assets", "s3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Builtin(tt.provider)
+			_, err := d.Command(tt.action, d.Vars(context.Background(), tt.target))
+			var unsupported *errs.UnsupportedActionError
+			if !errors.As(err, &unsupported) {
+				t.Fatalf("Expected an unsupported action error, got %v", err)
+			}
+			if unsupported.Resource != tt.wantResource {
+				t.Errorf("Expected resource %q, got %q", tt.wantResource, unsupported.Resource)
+			}
+		})
+	}
+}
+
+// TestExecute tests running a definition through the runner of the context
+func TestExecute(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Args[len(cmd.Args)-1] == "missing" {
+			result := &runner.Result{Command: cmd, Stderr: "dpkg-query: package 'missing' is not installed"}
+			return result, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 1}
+		}
+		return &runner.Result{Command: cmd, Stdout: "Package: nginx\nStatus: install ok installed\nVersion: 1.22.1-9\n"}, nil
+	}
+	d := Builtin("apt")
+
+	result := output.NewResult("nginx", "status")
+	ctx := output.WithResult(runner.WithRunner(context.Background(), recorder), result)
+	if err := d.Execute(ctx, "status", "nginx"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := result.Get("version"); got != "1.22.1-9" {
+		t.Errorf("Expected version 1.22.1-9, got %q", got)
+	}
+
+	err := d.Execute(ctx, "status", "missing")
+	var notFound *errs.SoftwareNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("Expected a software not found error, got %v", err)
+	}
+
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		return nil, &exec.Error{Name: cmd.Name, Err: exec.ErrNotFound}
+	}
+	err = d.Execute(ctx, "status", "nginx")
+	var unavailable *errs.ProviderUnavailableError
+	if !errors.As(err, &unavailable) {
+		t.Errorf("Expected a provider unavailable error, got %v", err)
+	}
+}
+
+// TestExitCodesAndEmptyOutput tests success exit codes and queries that
+// succeed without output for missing software
+func TestExitCodesAndEmptyOutput(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		switch {
+		case cmd.Args[0] == "check-update":
+			// Updates are available
+			return &runner.Result{Command: cmd, ExitCode: 100}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 100}
+		case cmd.Args[len(cmd.Args)-1] == "nginx":
+			return &runner.Result{Command: cmd, Stdout: "nginx 1.24.0-1.el9\n"}, nil
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	d := Builtin("dnf")
+	result := output.NewResult("nginx", "status")
+	ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(context.Background(), recorder)), result)
+
+	if err := d.Execute(ctx, "update", "nginx"); err != nil {
+		t.Errorf("Expected exit code 100 of check-update to succeed, got %v", err)
+	}
+	if err := d.Execute(ctx, "status", "nginx"); err != nil || result.Get("version") != "1.24.0-1.el9" {
+		t.Errorf("Expected version 1.24.0-1.el9, got %q (%v)", result.Get("version"), err)
+	}
+	var notFound *errs.SoftwareNotFoundError
+	if err := d.Execute(ctx, "status", "missing"); !errors.As(err, &notFound) {
+		t.Errorf("Expected a software not found error for an empty query, got %v", err)
+	}
+
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Command: cmd, ExitCode: 1}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 1}
+	}
+	var commandErr *errs.CommandFailedError
+	if err := d.Execute(ctx, "update", "nginx"); !errors.As(err, &commandErr) {
+		t.Errorf("Expected other exit codes to fail, got %v", err)
+	}
+}
+
+// TestAPKStatus tests the version parsed from apk list, package names with
+// dashes and digits included
+func TestAPKStatus(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Args[len(cmd.Args)-1] == "py3-yaml" {
+			return &runner.Result{Command: cmd, Stdout: "py3-yaml-6.0.1-r3 x86_64 {py3-yaml} (MIT) [installed]\n"}, nil
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	d := Builtin("apk")
+	result := output.NewResult("py3-yaml", "status")
+	ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(context.Background(), recorder)), result)
+
+	if err := d.Execute(ctx, "status", "py3-yaml"); err != nil || result.Get("version") != "6.0.1-r3" {
+		t.Errorf("Expected version 6.0.1-r3, got %q (%v)", result.Get("version"), err)
+	}
+	var notFound *errs.SoftwareNotFoundError
+	if err := d.Execute(ctx, "status", "missing"); !errors.As(err, &notFound) {
+		t.Errorf("Expected a software not found error for a package that is not installed, got %v", err)
+	}
+}
+
+// TestNixStatus tests finding a package in the JSON listing of a Nix
+// profile, in the formats of Nix before and since 2.20
+func TestNixStatus(t *testing.T) {
+	testCases := []struct {
+		name    string
+		listing string
+	}{
+		{"List", `{"elements":[{"active":true,"attrPath":"legacyPackages.x86_64-linux.ripgrep","storePaths":["/nix/store/0a1b2c-ripgrep-14.1.0"]},` +
+			`{"active":true,"attrPath":"legacyPackages.x86_64-linux.jq","storePaths":["/nix/store/3d4e5f-jq-1.7.1-bin"]}],"version":2}`},
+		{"Named", `{"elements":{"jq":{"active":true,"attrPath":"legacyPackages.x86_64-linux.jq","storePaths":["/nix/store/3d4e5f-jq-1.7.1-bin"]},` +
+			`"ripgrep":{"active":true,"attrPath":"legacyPackages.x86_64-linux.ripgrep","storePaths":["/nix/store/0a1b2c-ripgrep-14.1.0"]}},"version":3}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+				return &runner.Result{Command: cmd, Stdout: tc.listing}, nil
+			}
+			d := Builtin("nix")
+			result := output.NewResult("jq", "status")
+			ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(context.Background(), recorder)), result)
+
+			if err := d.Execute(ctx, "status", "jq"); err != nil || result.Get("version") != "1.7.1" {
+				t.Errorf("Expected version 1.7.1, got %q (%v)", result.Get("version"), err)
+			}
+			if commands := recorder.Commands(); len(commands) != 1 || !strings.HasSuffix(commands[0].String(), "profile list --json") {
+				t.Errorf("Expected the profile to be listed as JSON, got %v", commands)
+			}
+			var notFound *errs.SoftwareNotFoundError
+			if err := d.Execute(ctx, "status", "fd"); !errors.As(err, &notFound) {
+				t.Errorf("Expected a software not found error for a package that is not installed, got %v", err)
+			}
+		})
+	}
+}
+
+// TestCargoStatus tests finding an installed crate in the listing of every
+// installed crate
+func TestCargoStatus(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Command: cmd, Stdout: "cargo-edit v0.12.2:\n    cargo-add\nripgrep v14.1.0:\n    rg\n"}, nil
+	}
+	d := Builtin("cargo")
+	result := output.NewResult("ripgrep", "status")
+	ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(context.Background(), recorder)), result)
+
+	if err := d.Execute(ctx, "status", "ripgrep"); err != nil || result.Get("version") != "14.1.0" {
+		t.Errorf("Expected version 14.1.0, got %q (%v)", result.Get("version"), err)
+	}
+	if commands := recorder.Commands(); len(commands) != 1 || commands[0].String() != "cargo install --list" {
+		t.Errorf("Expected the installed crates to be listed, got %v", commands)
+	}
+	var notFound *errs.SoftwareNotFoundError
+	for _, crate := range []string{"edit", "rg"} {
+		if err := d.Execute(ctx, "status", crate); !errors.As(err, &notFound) {
+			t.Errorf("Expected a software not found error for %s, got %v", crate, err)
+		}
+	}
+}
+
+// TestSoftwareVariables tests the snap, flatpak and nix settings of the saidata
+// and their precedence over the defaults and below the context variables
+func TestSoftwareVariables(t *testing.T) {
+	sw := &data.Software{
+		Name:    "code",
+		Snap:    &data.Snap{Channel: "latest/stable", Confinement: "classic"},
+		Flatpak: &data.Flatpak{Branch: "stable"},
+		Nix:     &data.Nix{Flake: "github:NixOS/nixpkgs/nixos-24.05", Revision: "5ad9903c1612"},
+	}
+	ctx := data.WithSoftware(context.Background(), sw)
+
+	tests := []struct {
+		name     string
+		provider string
+		vars     map[string]string
+		want     string
+	}{
+		{"Snap saidata", "snap", nil, "snap install code --channel=latest/stable --classic"},
+		{"Snap flag wins", "snap", map[string]string{"channel": "latest/edge"}, "snap install code --channel=latest/edge --classic"},
+		{"Flatpak default remote", "flatpak", nil, "flatpak install -y flathub code//stable"},
+		{"Nix saidata revision", "nix", nil, "nix --extra-experimental-features 'nix-command flakes' profile install github:NixOS/nixpkgs/5ad9903c1612#code"},
+		{"Nix flake flag wins", "nix", map[string]string{"flake": "github:NixOS/nixpkgs/nixos-unstable", "revision": ""},
+			"nix --extra-experimental-features 'nix-command flakes' profile install github:NixOS/nixpkgs/nixos-unstable#code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Builtin(tt.provider)
+			cmd, err := d.Command("install", d.Vars(WithVariables(ctx, tt.vars), "code"))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := cmd.String(); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Command: cmd, Stdout: "Name  Version       Rev  Tracking       Publisher  Notes\njq    1.5+dfsg-1    6    latest/stable  mvo        -\n"}, nil
+	}
+	result := output.NewResult("jq", "status")
+	statusCtx := output.WithResult(runner.WithQuiet(runner.WithRunner(context.Background(), recorder)), result)
+	if err := Builtin("snap").Execute(statusCtx, "status", "jq"); err != nil || result.Get("version") != "1.5+dfsg-1" {
+		t.Errorf("Expected snap version 1.5+dfsg-1, got %q (%v)", result.Get("version"), err)
+	}
+}
+
+// TestCatalogOverride tests that custom definitions add and replace providers
+func TestCatalogOverride(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"apt.yaml":  "name: apt\ntype: os\nbinaries: [apt]\nactions:\n  install:\n    argv: [apt, install, --yes, '{{.package}}']\n",
+		"acme.yml":  "name: acme\ntype: cloud\nbinaries: [acme]\nactions:\n  list:\n    argv: [acme, ls]\n",
+		"notes.txt": "not a definition",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewCatalog()
+	for _, d := range Builtins() {
+		c.Add(d)
+	}
+	if err := c.LoadDir(dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	apt, _ := c.Get("apt")
+	cmd, err := apt.Command("install", apt.Vars(context.Background(), "nginx"))
+	if err != nil || cmd.String() != "apt install --yes nginx" {
+		t.Errorf("Expected the custom apt definition, got %q (%v)", cmd.String(), err)
+	}
+	if acme, ok := c.Get("acme"); !ok || acme.Type != "cloud" {
+		t.Errorf("Expected the acme definition, got %+v", acme)
+	}
+	if _, ok := c.Get("helm"); !ok {
+		t.Error("Expected the built-in helm definition to remain")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("name: broken\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewCatalog().LoadDir(dir); err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Errorf("Expected an error naming the broken file, got %v", err)
+	}
+}
+
+// TestPlan tests that planning renders the command without running it
+func TestPlan(t *testing.T) {
+	d, err := Parse([]byte(`name: acme
+type: os
+binaries: [acme]
+needs_root: true
+variables:
+  mirror: https://mirror.example.com
+actions:
+  uninstall:
+    argv: [acme, remove, "{{.package}}"]
+    env: ["ACME_MIRROR={{.mirror}}"]
+    destructive: true
+  status:
+    needs_root: false
+    argv: [acme, info, "{{.package}}"]
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	recorder := runner.NewRecorder()
+	result := output.NewResult("nginx", "uninstall")
+	ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(context.Background(), recorder)), result)
+	if err := d.Plan(ctx, "uninstall", "nginx"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Plan) != 1 {
+		t.Fatalf("Expected one planned step, got: %+v", result.Plan)
+	}
+	step := result.Plan[0]
+	if step.String() != "ACME_MIRROR=https://mirror.example.com acme remove nginx" || !step.NeedsRoot || !step.Destructive {
+		t.Errorf("Unexpected step: %+v", step)
+	}
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run, got: %v", commands)
+	}
+
+	// Read-only actions may need no root from a provider whose changes do
+	if err := d.Plan(ctx, "status", "nginx"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Plan) != 2 || result.Plan[1].NeedsRoot {
+		t.Errorf("Expected the query to need no root, got: %+v", result.Plan)
+	}
+	if err := Builtin("apt").Plan(ctx, "status", "nginx"); err != nil || result.Plan[2].NeedsRoot {
+		t.Errorf("Expected dpkg -s to need no root, got: %+v (%v)", result.Plan, err)
+	}
+	if _, err := Parse([]byte("name: acme\ntype: os\nbinaries: [acme]\nactions:\n  install:\n    argv: [acme]\n    env: [ACME]\n")); err == nil {
+		t.Error("Expected an error for an environment entry without a value")
+	}
+}
@@ -0,0 +1,22 @@
+package providers
+
+import (
+	"context"
+	languageprovider "sai/cmd/providers/language"
+)
+
+// languageProviderAdapter adapts a language.Provider to providers.Provider
+type languageProviderAdapter struct {
+	provider languageprovider.Provider
+}
+
+// Execute implements the Provider interface
+func (a *languageProviderAdapter) Execute(ctx context.Context, action, software string) error {
+	return a.provider.Execute(ctx, action, software)
+}
+
+// NewLanguageProvider creates the appropriate language package manager provider based on the name
+func NewLanguageProvider(name string) Provider {
+	provider := languageprovider.NewProvider(name)
+	return &languageProviderAdapter{provider: provider}
+}
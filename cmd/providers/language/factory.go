@@ -0,0 +1,48 @@
+package language
+
+import "sai/cmd/providers/definition"
+
+// NewLanguageProvider creates a language package manager provider from its definition
+func NewLanguageProvider(d *definition.Definition) *BaseLanguageProvider {
+	return &BaseLanguageProvider{Name: d.Name, Definition: d}
+}
+
+// NewPipProvider creates a new pip provider
+func NewPipProvider() *BaseLanguageProvider {
+	return NewLanguageProvider(definition.Builtin("pip"))
+}
+
+// NewPipxProvider creates a new pipx provider
+func NewPipxProvider() *BaseLanguageProvider {
+	return NewLanguageProvider(definition.Builtin("pipx"))
+}
+
+// NewNPMProvider creates a new npm provider
+func NewNPMProvider() *BaseLanguageProvider {
+	return NewLanguageProvider(definition.Builtin("npm"))
+}
+
+// NewCargoProvider creates a new Cargo provider
+func NewCargoProvider() *BaseLanguageProvider {
+	return NewLanguageProvider(definition.Builtin("cargo"))
+}
+
+// NewGemProvider creates a new RubyGems provider
+func NewGemProvider() *BaseLanguageProvider {
+	return NewLanguageProvider(definition.Builtin("gem"))
+}
+
+// NewGoProvider creates a new go install provider
+func NewGoProvider() *BaseLanguageProvider {
+	return NewLanguageProvider(definition.Builtin("go"))
+}
+
+// NewProvider creates the language package manager provider with the given
+// name, custom definitions included
+func NewProvider(name string) Provider {
+	if d, err := definition.Get(name); err == nil && d != nil && d.Type == "language" {
+		return NewLanguageProvider(d)
+	}
+	// Return pipx provider as default
+	return NewPipxProvider()
+}
@@ -0,0 +1,137 @@
+package language
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// TestDryRunModeLanguageProviders tests language providers in dry run mode
+func TestDryRunModeLanguageProviders(t *testing.T) {
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	// Record commands instead of running them
+	recorder := runner.NewRecorder()
+	ctx := runner.WithRunner(context.Background(), recorder)
+
+	languageProviders := []Provider{
+		NewPipProvider(),
+		NewPipxProvider(),
+		NewNPMProvider(),
+		NewCargoProvider(),
+		NewGemProvider(),
+		NewGoProvider(),
+	}
+
+	for _, provider := range languageProviders {
+		for _, action := range AllActions {
+			t.Run(provider.GetPackageManager()+"_"+action, func(t *testing.T) {
+				// Execute should plan the command instead of running it in dry run mode
+				result := output.NewResult("tool", action)
+				err := provider.Execute(output.WithResult(ctx, result), action, "tool")
+
+				var unsupported *errs.UnsupportedActionError
+				switch {
+				case errors.As(err, &unsupported):
+					// The provider has no command for this action
+				case err != nil:
+					t.Errorf("Expected no error in dry run mode, got: %v", err)
+				case len(result.Plan) != 1:
+					t.Errorf("Expected one planned step, got: %v", result.Plan)
+				case result.Plan[0].NeedsRoot:
+					t.Errorf("Expected per-user installs not to need root: %v", result.Plan[0])
+				}
+			})
+		}
+	}
+
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run in dry run mode, got: %v", commands)
+	}
+}
+
+// TestLanguageCommands tests the commands of the language providers for
+// per-user and system-wide installs
+func TestLanguageCommands(t *testing.T) {
+	t.Setenv("HOME", "/home/dev")
+
+	tests := []struct {
+		name     string
+		provider Provider
+		action   string
+		target   string
+		vars     map[string]string
+		want     string
+	}{
+		{"pip user", NewPipProvider(), "install", "black", nil, "pip3 install --user black"},
+		{"pip system pinned", NewPipProvider(), "install", "black", map[string]string{"system": "true", "version": "24.2.0"}, "pip3 install black==24.2.0"},
+		{"pipx upgrade", NewPipxProvider(), "upgrade", "black", nil, "pipx upgrade black"},
+		{"pipx global", NewPipxProvider(), "install", "black", map[string]string{"system": "true"}, "pipx install --global black"},
+		{"npm user", NewNPMProvider(), "install", "prettier", nil, "npm install -g --prefix=/home/dev/.local prettier"},
+		{"npm system scoped", NewNPMProvider(), "install", "@angular/cli", map[string]string{"system": "true", "version": "17.3.0"}, "npm install -g @angular/cli@17.3.0"},
+		{"cargo pinned", NewCargoProvider(), "install", "ripgrep", map[string]string{"version": "14.1.0"}, "cargo install ripgrep --version=14.1.0"},
+		{"cargo system", NewCargoProvider(), "uninstall", "ripgrep", map[string]string{"system": "true"}, "cargo uninstall --root=/usr/local ripgrep"},
+		{"gem user", NewGemProvider(), "install", "rubocop", nil, "gem install --user-install rubocop"},
+		{"go latest", NewGoProvider(), "install", "golang.org/x/tools/gopls", nil, "GOBIN=/home/dev/go/bin go install golang.org/x/tools/gopls@latest"},
+		{"go pinned system", NewGoProvider(), "upgrade", "golang.org/x/tools/gopls", map[string]string{"system": "true", "version": "v0.15.2"},
+			"GOBIN=/usr/local/bin go install golang.org/x/tools/gopls@v0.15.2"},
+		{"go uninstall", NewGoProvider(), "uninstall", "github.com/golangci/golangci-lint/v2/cmd/golangci-lint", map[string]string{"gobin": "/opt/bin"},
+			"rm /opt/bin/golangci-lint"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			ctx := definition.WithVariables(runner.WithRunner(context.Background(), recorder), tt.vars)
+			if err := tt.provider.Execute(ctx, tt.action, tt.target); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			commands := recorder.Commands()
+			if len(commands) != 1 {
+				t.Fatalf("Expected one command, got %v", commands)
+			}
+			if got := strings.Join(append(commands[0].Env, commands[0].String()), " "); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestLanguageStatus tests the versions parsed from the status queries
+func TestLanguageStatus(t *testing.T) {
+	tests := []struct {
+		provider Provider
+		target   string
+		stdout   string
+		want     string
+	}{
+		{NewPipProvider(), "black", "Name: black\nVersion: 24.2.0\nSummary: The uncompromising code formatter.\n", "24.2.0"},
+		{NewNPMProvider(), "@angular/cli", "/home/dev/.local/lib\n└── @angular/cli@17.3.0\n\n", "17.3.0"},
+		{NewGemProvider(), "rubocop", "rubocop (1.60.2, 1.59.0)\n", "1.60.2"},
+		{NewGoProvider(), "golang.org/x/tools/gopls", "/home/dev/go/bin/gopls: go1.22.1\n\tpath\tgolang.org/x/tools/gopls\n\tmod\tgolang.org/x/tools/gopls\tv0.15.2\th1:4JKt4inO54YYSWxW+jZdrVMrl4VSxh/sfCm8tn1/0tE=\n", "v0.15.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider.GetPackageManager(), func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+				return &runner.Result{Command: cmd, Stdout: tt.stdout}, nil
+			}
+			result := output.NewResult(tt.target, "status")
+			ctx := output.WithResult(runner.WithQuiet(runner.WithRunner(context.Background(), recorder)), result)
+			if err := tt.provider.Execute(ctx, "status", tt.target); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := result.Get("version"); got != tt.want {
+				t.Errorf("Expected version %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package language
+
+import (
+	"context"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/errs"
+)
+
+// Provider interface defines methods for language package manager implementations
+type Provider interface {
+	Execute(ctx context.Context, action, software string) error
+	GetPackageManager() string
+	IsDryRun() bool
+}
+
+// Supported language package manager actions
+const (
+	ActionInstall   = "install"
+	ActionUninstall = "uninstall"
+	ActionStatus    = "status"
+	ActionList      = "list"
+	ActionSearch    = "search"
+	ActionUpgrade   = "upgrade"
+	ActionInfo      = "info"
+)
+
+// AllActions contains all supported language package manager actions
+var AllActions = []string{
+	ActionInstall,
+	ActionUninstall,
+	ActionStatus,
+	ActionList,
+	ActionSearch,
+	ActionUpgrade,
+	ActionInfo,
+}
+
+// IsValidAction checks if the given action is supported by language package managers
+func IsValidAction(action string) bool {
+	for _, a := range AllActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// Global variable to track dry run mode
+var isDryRunMode = false
+
+// SetDryRun sets the dry run mode for language package managers
+func SetDryRun(enabled bool) {
+	isDryRunMode = enabled
+}
+
+// BaseLanguageProvider runs language package manager actions described by a
+// provider definition
+type BaseLanguageProvider struct {
+	Name       string
+	Definition *definition.Definition
+}
+
+// GetPackageManager returns the language package manager name
+func (p *BaseLanguageProvider) GetPackageManager() string {
+	return p.Name
+}
+
+// IsDryRun checks if dry run mode is enabled
+func (p *BaseLanguageProvider) IsDryRun() bool {
+	return isDryRunMode
+}
+
+// Execute runs the command the definition maps the action to
+func (p *BaseLanguageProvider) Execute(ctx context.Context, action, software string) error {
+	// Validate action
+	if !IsValidAction(action) {
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Definition.Plan(ctx, action, software)
+	}
+
+	return p.Definition.Execute(ctx, action, software)
+}
@@ -1,6 +1,8 @@
 package providers
 
 import (
+	"context"
+
 	ospkg "sai/cmd/providers/os"
 )
 
@@ -13,8 +15,8 @@ type osProviderAdapter struct {
 }
 
 // Execute implements the Provider interface
-func (a *osProviderAdapter) Execute(action, software string) error {
-	return a.provider.Execute(action, software)
+func (a *osProviderAdapter) Execute(ctx context.Context, action, software string) error {
+	return a.provider.Execute(ctx, action, software)
 }
 
 // NewOSProvider creates the appropriate OS provider based on the name
@@ -1,14 +1,15 @@
 package os
 
 import (
-	"runtime"
+	"context"
 	"sai/cmd/providers/os/pkgmanager"
 	"sai/cmd/providers/os/service"
+	"sai/pkg/platform"
 )
 
 // Provider interface defines methods for OS provider implementations
 type Provider interface {
-	Execute(action, software string) error
+	Execute(ctx context.Context, action, software string) error
 	GetPackageManager() string
 }
 
@@ -18,8 +19,8 @@ type pkgManagerAdapter struct {
 }
 
 // Execute implements the Provider interface
-func (a *pkgManagerAdapter) Execute(action, software string) error {
-	return a.provider.Execute(action, software)
+func (a *pkgManagerAdapter) Execute(ctx context.Context, action, software string) error {
+	return a.provider.Execute(ctx, action, software)
 }
 
 // GetPackageManager returns the package manager name
@@ -33,8 +34,7 @@ func NewProvider(name string) Provider {
 	return &pkgManagerAdapter{provider: pkgProvider}
 }
 
-// NewServiceProvider creates the appropriate service provider for the current OS
+// NewServiceProvider creates the appropriate service provider for the current platform
 func NewServiceProvider() service.Provider {
-	osType := runtime.GOOS
-	return service.GetProvider(osType)
+	return service.ForPlatform(platform.Detect())
 }
@@ -1,59 +0,0 @@
-package pkgmanager
-
-import (
-	"fmt"
-	"os/exec"
-)
-
-// APTProvider handles APT-based package operations
-type APTProvider struct {
-	BaseProvider
-}
-
-// Execute runs APT commands
-func (p *APTProvider) Execute(action, software string) error {
-	// Validate action
-	if !IsValidAction(action) {
-		return fmt.Errorf("unsupported action '%s' for APT provider", action)
-	}
-
-	// Check if in dry run mode
-	if p.IsDryRun() {
-		fmt.Printf("[DRY RUN] Would execute %s %s with APT provider\n", action, software)
-		return nil
-	}
-
-	fmt.Printf("Executing %s %s with APT provider\n", action, software)
-
-	var cmd *exec.Cmd
-	switch action {
-	case ActionInstall:
-		cmd = exec.Command("apt-get", "install", "-y", software)
-	case ActionUninstall:
-		cmd = exec.Command("apt-get", "remove", "-y", software)
-	case ActionStatus:
-		cmd = exec.Command("dpkg", "-s", software)
-	case ActionList:
-		cmd = exec.Command("apt", "list", "--installed")
-	case ActionSearch:
-		cmd = exec.Command("apt-cache", "search", software)
-	case ActionUpgrade:
-		cmd = exec.Command("apt-get", "upgrade", "-y", software)
-	case ActionInfo:
-		cmd = exec.Command("apt-cache", "show", software)
-	default:
-		fmt.Printf("Action %s not implemented for APT\n", action)
-		return nil
-	}
-
-	// Would actually run the command in a real implementation
-	fmt.Printf("Would run: %s\n", cmd.String())
-	return nil
-}
-
-// NewAPTProvider creates a new APT provider
-func NewAPTProvider() *APTProvider {
-	return &APTProvider{
-		BaseProvider: BaseProvider{Name: "apt"},
-	}
-}
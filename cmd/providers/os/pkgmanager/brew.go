@@ -1,53 +0,0 @@
-package pkgmanager
-
-import (
-	"fmt"
-	"os/exec"
-)
-
-// BrewProvider handles Homebrew package operations
-type BrewProvider struct {
-	BaseProvider
-}
-
-// Execute runs Homebrew commands
-func (p *BrewProvider) Execute(action, software string) error {
-	// Validate action
-	if !IsValidAction(action) {
-		return fmt.Errorf("unsupported action '%s' for Homebrew provider", action)
-	}
-
-	fmt.Printf("Executing %s %s with Homebrew provider\n", action, software)
-
-	var cmd *exec.Cmd
-	switch action {
-	case ActionInstall:
-		cmd = exec.Command("brew", "install", software)
-	case ActionUninstall:
-		cmd = exec.Command("brew", "uninstall", software)
-	case ActionStatus:
-		cmd = exec.Command("brew", "info", software)
-	case ActionList:
-		cmd = exec.Command("brew", "list")
-	case ActionSearch:
-		cmd = exec.Command("brew", "search", software)
-	case ActionUpgrade:
-		cmd = exec.Command("brew", "upgrade", software)
-	case ActionInfo:
-		cmd = exec.Command("brew", "info", software)
-	default:
-		fmt.Printf("Action %s not implemented for Homebrew\n", action)
-		return nil
-	}
-
-	// Would actually run the command in a real implementation
-	fmt.Printf("Would run: %s\n", cmd.String())
-	return nil
-}
-
-// NewBrewProvider creates a new Homebrew provider
-func NewBrewProvider() *BrewProvider {
-	return &BrewProvider{
-		BaseProvider: BaseProvider{Name: "brew"},
-	}
-}
@@ -1,22 +1,78 @@
 package pkgmanager
 
-// GetProvider creates the appropriate package manager provider based on the name
+import "sai/cmd/providers/definition"
+
+// NewProvider creates a package manager provider from its definition
+func NewProvider(d *definition.Definition) *BaseProvider {
+	return &BaseProvider{Name: d.Name, Definition: d}
+}
+
+// NewAPTProvider creates a new APT provider
+func NewAPTProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("apt"))
+}
+
+// NewRPMProvider creates a new RPM provider
+func NewRPMProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("rpm"))
+}
+
+// NewDNFProvider creates a new DNF provider
+func NewDNFProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("dnf"))
+}
+
+// NewYUMProvider creates a new YUM provider
+func NewYUMProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("yum"))
+}
+
+// NewAPKProvider creates a new APK provider
+func NewAPKProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("apk"))
+}
+
+// NewBrewProvider creates a new Homebrew provider
+func NewBrewProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("brew"))
+}
+
+// NewWingetProvider creates a new Winget provider
+func NewWingetProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("winget"))
+}
+
+// NewPacmanProvider creates a new Pacman provider
+func NewPacmanProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("pacman"))
+}
+
+// NewZypperProvider creates a new Zypper provider
+func NewZypperProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("zypper"))
+}
+
+// NewSnapProvider creates a new Snap provider
+func NewSnapProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("snap"))
+}
+
+// NewFlatpakProvider creates a new Flatpak provider
+func NewFlatpakProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("flatpak"))
+}
+
+// NewNixProvider creates a new Nix provider
+func NewNixProvider() *BaseProvider {
+	return NewProvider(definition.Builtin("nix"))
+}
+
+// GetProvider creates the package manager provider with the given name,
+// custom definitions included
 func GetProvider(name string) Provider {
-	switch name {
-	case "rpm":
-		return NewRPMProvider()
-	case "apt":
-		return NewAPTProvider()
-	case "brew":
-		return NewBrewProvider()
-	case "winget":
-		return NewWingetProvider()
-	case "pacman":
-		return NewPacmanProvider()
-	case "zypper":
-		return NewZypperProvider()
-	default:
-		// Return APT provider as default
-		return NewAPTProvider()
+	if d, err := definition.Get(name); err == nil && d != nil && d.Type == "os" {
+		return NewProvider(d)
 	}
+	// Return APT provider as default
+	return NewAPTProvider()
 }
@@ -1,53 +0,0 @@
-package pkgmanager
-
-import (
-	"fmt"
-	"os/exec"
-)
-
-// PacmanProvider handles Arch Linux package operations
-type PacmanProvider struct {
-	BaseProvider
-}
-
-// Execute runs Pacman commands
-func (p *PacmanProvider) Execute(action, software string) error {
-	// Validate action
-	if !IsValidAction(action) {
-		return fmt.Errorf("unsupported action '%s' for Pacman provider", action)
-	}
-
-	fmt.Printf("Executing %s %s with Pacman provider\n", action, software)
-
-	var cmd *exec.Cmd
-	switch action {
-	case ActionInstall:
-		cmd = exec.Command("pacman", "-S", "--noconfirm", software)
-	case ActionUninstall:
-		cmd = exec.Command("pacman", "-R", "--noconfirm", software)
-	case ActionStatus:
-		cmd = exec.Command("pacman", "-Qi", software)
-	case ActionList:
-		cmd = exec.Command("pacman", "-Q")
-	case ActionSearch:
-		cmd = exec.Command("pacman", "-Ss", software)
-	case ActionUpgrade:
-		cmd = exec.Command("pacman", "-Syu", "--noconfirm")
-	case ActionInfo:
-		cmd = exec.Command("pacman", "-Si", software)
-	default:
-		fmt.Printf("Action %s not implemented for Pacman\n", action)
-		return nil
-	}
-
-	// Would actually run the command in a real implementation
-	fmt.Printf("Would run: %s\n", cmd.String())
-	return nil
-}
-
-// NewPacmanProvider creates a new Pacman provider
-func NewPacmanProvider() *PacmanProvider {
-	return &PacmanProvider{
-		BaseProvider: BaseProvider{Name: "pacman"},
-	}
-}
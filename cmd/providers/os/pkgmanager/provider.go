@@ -1,8 +1,15 @@
 package pkgmanager
 
+import (
+	"context"
+
+	"sai/cmd/providers/definition"
+	"sai/pkg/errs"
+)
+
 // Provider interface defines methods for package manager implementations
 type Provider interface {
-	Execute(action, software string) error
+	Execute(ctx context.Context, action, software string) error
 	GetPackageManager() string
 	IsDryRun() bool // Add method to check if dry run mode is active
 }
@@ -15,6 +22,7 @@ const (
 	ActionList      = "list"
 	ActionSearch    = "search"
 	ActionUpgrade   = "upgrade"
+	ActionUpdate    = "update"
 	ActionInfo      = "info"
 )
 
@@ -26,6 +34,7 @@ var AllActions = []string{
 	ActionList,
 	ActionSearch,
 	ActionUpgrade,
+	ActionUpdate,
 	ActionInfo,
 }
 
@@ -47,9 +56,10 @@ func SetDryRun(enabled bool) {
 	isDryRunMode = enabled
 }
 
-// BaseProvider common functionality for package manager providers
+// BaseProvider runs package manager actions described by a provider definition
 type BaseProvider struct {
-	Name string
+	Name       string
+	Definition *definition.Definition
 }
 
 // GetPackageManager returns the package manager name
@@ -61,3 +71,18 @@ func (p *BaseProvider) GetPackageManager() string {
 func (p *BaseProvider) IsDryRun() bool {
 	return isDryRunMode
 }
+
+// Execute runs the command the definition maps the action to
+func (p *BaseProvider) Execute(ctx context.Context, action, software string) error {
+	// Validate action
+	if !IsValidAction(action) {
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Definition.Plan(ctx, action, software)
+	}
+
+	return p.Definition.Execute(ctx, action, software)
+}
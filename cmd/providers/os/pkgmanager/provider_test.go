@@ -1,7 +1,13 @@
 package pkgmanager
 
 import (
+	"context"
+	"errors"
 	"testing"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
 )
 
 // mockDryRunCheckFunc is used to check if dry run mode is enabled
@@ -13,6 +19,12 @@ func TestDryRunModePackageManagers(t *testing.T) {
 	// Set mock dry run mode
 	mockDryRunEnabled = true
 	defer func() { mockDryRunEnabled = false }() // Reset after test
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	// Record commands instead of running them
+	recorder := runner.NewRecorder()
+	ctx := runner.WithRunner(context.Background(), recorder)
 
 	// Test different package managers
 	providers := []Provider{
@@ -30,16 +42,92 @@ func TestDryRunModePackageManagers(t *testing.T) {
 
 		for _, action := range AllActions {
 			t.Run(pkgManager+"_"+action, func(t *testing.T) {
-				// Execute should not perform real operations in dry run mode
-				err := provider.Execute(action, "test-software")
+				// Execute should plan the command instead of running it in dry run mode
+				result := output.NewResult("test-software", action)
+				err := provider.Execute(output.WithResult(ctx, result), action, "test-software")
 
-				if err != nil {
-					t.Errorf("Expected no error in dry run mode for %s %s, got: %v",
-						pkgManager, action, err)
+				var unsupported *errs.UnsupportedActionError
+				switch {
+				case errors.As(err, &unsupported):
+					// The provider has no command for this action
+				case err != nil:
+					t.Errorf("Expected no error in dry run mode for %s %s, got: %v", pkgManager, action, err)
+				case len(result.Plan) != 1:
+					t.Errorf("Expected one planned step for %s %s, got: %v", pkgManager, action, result.Plan)
 				}
 			})
 		}
 	}
+
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run in dry run mode, got: %v", commands)
+	}
+}
+
+// TestExecuteRunsCommands tests that package managers run the expected commands
+func TestExecuteRunsCommands(t *testing.T) {
+	testCases := []struct {
+		provider Provider
+		action   string
+		expected string
+	}{
+		{NewAPTProvider(), ActionInstall, "apt-get install -y nginx"},
+		{NewAPTProvider(), ActionStatus, "dpkg -s nginx"},
+		{NewBrewProvider(), ActionUninstall, "brew uninstall nginx"},
+		{NewPacmanProvider(), ActionInstall, "pacman -S --noconfirm nginx"},
+		{NewRPMProvider(), ActionInfo, "rpm -qi nginx"},
+		{NewWingetProvider(), ActionSearch, "winget search nginx"},
+		{NewZypperProvider(), ActionUpgrade, "zypper update -y nginx"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.provider.GetPackageManager()+"_"+tc.action, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			ctx := runner.WithRunner(context.Background(), recorder)
+
+			if err := tc.provider.Execute(ctx, tc.action, "nginx"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			commands := recorder.Commands()
+			if len(commands) != 1 || commands[0].String() != tc.expected {
+				t.Errorf("Expected command '%s', got: %v", tc.expected, commands)
+			}
+		})
+	}
+}
+
+// TestStatusReportsVersion tests that the installed version is parsed from the status output
+func TestStatusReportsVersion(t *testing.T) {
+	testCases := []struct {
+		provider Provider
+		stdout   string
+		expected string
+	}{
+		{NewAPTProvider(), "Package: nginx\nStatus: install ok installed\nVersion: 1.22.1-9\n", "1.22.1-9"},
+		{NewRPMProvider(), "nginx-1.20.1-14.el9.x86_64\n", "1.20.1"},
+		{NewPacmanProvider(), "Name            : nginx\nVersion         : 1.24.0-1\n", "1.24.0-1"},
+		{NewZypperProvider(), "Name           : nginx\nVersion        : 1.21.5-150400.3.3.1\n", "1.21.5-150400.3.3.1"},
+		{NewBrewProvider(), "==> nginx: stable 1.25.3 (bottled)\n/opt/homebrew/Cellar/nginx/1.25.3 (26 files)\n", "1.25.3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.provider.GetPackageManager(), func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+				return &runner.Result{Command: cmd, Stdout: tc.stdout}, nil
+			}
+			result := output.NewResult("nginx", ActionStatus)
+			ctx := output.WithResult(runner.WithRunner(context.Background(), recorder), result)
+
+			if err := tc.provider.Execute(ctx, ActionStatus, "nginx"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version := result.Get("version"); version != tc.expected {
+				t.Errorf("Expected version %s, got %q", tc.expected, version)
+			}
+		})
+	}
 }
 
 // Modify the Execute method in BaseProvider to check for dry run mode
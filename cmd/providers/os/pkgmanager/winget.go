@@ -1,53 +0,0 @@
-package pkgmanager
-
-import (
-	"fmt"
-	"os/exec"
-)
-
-// WingetProvider handles Windows package operations
-type WingetProvider struct {
-	BaseProvider
-}
-
-// Execute runs Winget commands
-func (p *WingetProvider) Execute(action, software string) error {
-	// Validate action
-	if !IsValidAction(action) {
-		return fmt.Errorf("unsupported action '%s' for Winget provider", action)
-	}
-
-	fmt.Printf("Executing %s %s with Winget provider\n", action, software)
-
-	var cmd *exec.Cmd
-	switch action {
-	case ActionInstall:
-		cmd = exec.Command("winget", "install", software)
-	case ActionUninstall:
-		cmd = exec.Command("winget", "uninstall", software)
-	case ActionStatus:
-		cmd = exec.Command("winget", "list", software)
-	case ActionList:
-		cmd = exec.Command("winget", "list")
-	case ActionSearch:
-		cmd = exec.Command("winget", "search", software)
-	case ActionUpgrade:
-		cmd = exec.Command("winget", "upgrade", software)
-	case ActionInfo:
-		cmd = exec.Command("winget", "show", software)
-	default:
-		fmt.Printf("Action %s not implemented for Winget\n", action)
-		return nil
-	}
-
-	// Would actually run the command in a real implementation
-	fmt.Printf("Would run: %s\n", cmd.String())
-	return nil
-}
-
-// NewWingetProvider creates a new Winget provider
-func NewWingetProvider() *WingetProvider {
-	return &WingetProvider{
-		BaseProvider: BaseProvider{Name: "winget"},
-	}
-}
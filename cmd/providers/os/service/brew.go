@@ -1,8 +1,12 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
 )
 
 // BrewProvider handles macOS brew services operations
@@ -11,37 +15,54 @@ type BrewProvider struct {
 }
 
 // Execute runs brew services commands
-func (p *BrewProvider) Execute(action, service string) error {
-	// Check if in dry run mode
-	if p.IsDryRun() {
-		fmt.Printf("[DRY RUN] Would execute %s service %s using brew services\n", action, service)
-		return nil
-	}
-
-	fmt.Printf("Managing service %s with Brew Services action %s\n", service, action)
-
-	var cmd *exec.Cmd
+func (p *BrewProvider) Execute(ctx context.Context, action, service string) error {
+	var cmd runner.Command
 	switch action {
 	case ActionStart, ActionStop, ActionRestart:
-		cmd = exec.Command("brew", "services", action, service)
+		cmd = runner.NewCommand("brew", "services", action, service)
 	case ActionEnable, ActionDisable:
 		// Brew services doesn't have direct enable/disable commands
-		// For dry run, we'll just show what would happen
+		// so they are approximated with start/stop
 		if action == ActionEnable {
 			fmt.Println("Note: brew services automatically enables services when started")
-			cmd = exec.Command("brew", "services", "start", service)
+			cmd = runner.NewCommand("brew", "services", "start", service)
 		} else {
 			fmt.Println("Note: brew services doesn't have a direct disable command")
 			fmt.Println("Services can be manually disabled by modifying their plist files")
-			cmd = exec.Command("brew", "services", "stop", service)
+			cmd = runner.NewCommand("brew", "services", "stop", service)
 		}
 	default:
-		return fmt.Errorf("action %s not implemented for Brew Services", action)
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Plan(ctx, cmd)
 	}
 
-	// Would actually run the command in a real implementation
-	fmt.Printf("Would run: %s\n", cmd.String())
-	return nil
+	fmt.Printf("Managing service %s with Brew Services action %s\n", service, action)
+	return p.Run(ctx, cmd)
+}
+
+// Status queries brew services for the state of the service. A service
+// loaded at login is reported as enabled.
+func (p *BrewProvider) Status(ctx context.Context, service string) (State, error) {
+	ok, result, err := p.Query(ctx, runner.NewCommand("brew", "services", "info", service, "--json"))
+	if err != nil || !ok {
+		return State{}, err
+	}
+
+	var info []struct {
+		Running bool `json:"running"`
+		Loaded  bool `json:"loaded"`
+	}
+	if err := json.Unmarshal([]byte(result.Stdout), &info); err != nil {
+		return State{}, fmt.Errorf("parsing brew services info: %w", err)
+	}
+	if len(info) == 0 {
+		return State{}, nil
+	}
+	return State{Running: info[0].Running, Enabled: info[0].Loaded}, nil
 }
 
 // NewBrewProvider creates a new Brew Service provider
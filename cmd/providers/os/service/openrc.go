@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+)
+
+// openRCRunlevel is the runlevel services are enabled in
+const openRCRunlevel = "default"
+
+// OpenRCProvider handles OpenRC service operations, as found on Alpine Linux
+type OpenRCProvider struct {
+	BaseProvider
+}
+
+// Execute runs rc-service and rc-update commands
+func (p *OpenRCProvider) Execute(ctx context.Context, action, service string) error {
+	var cmd runner.Command
+	switch action {
+	case ActionStart, ActionStop, ActionRestart:
+		cmd = runner.NewCommand("rc-service", service, action)
+	case ActionEnable:
+		cmd = runner.NewCommand("rc-update", "add", service, openRCRunlevel)
+	case ActionDisable:
+		cmd = runner.NewCommand("rc-update", "del", service, openRCRunlevel)
+	default:
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Plan(ctx, cmd)
+	}
+
+	fmt.Printf("Executing %s service %s using openrc\n", action, service)
+	return p.Run(ctx, cmd)
+}
+
+// Status queries OpenRC for the state of the service. rc-service exits with
+// a non-zero status when the service is not started; a service is enabled
+// when rc-update lists it in the default runlevel.
+func (p *OpenRCProvider) Status(ctx context.Context, service string) (State, error) {
+	var state State
+	var err error
+	if state.Running, _, err = p.Query(ctx, runner.NewCommand("rc-service", service, "status")); err != nil {
+		return state, err
+	}
+	listed, result, err := p.Query(ctx, runner.NewCommand("rc-update", "show", openRCRunlevel))
+	if err != nil || !listed {
+		return state, err
+	}
+	state.Enabled = inRunlevel(result.Stdout, service)
+	return state, nil
+}
+
+// inRunlevel reports whether the output of rc-update show, made of lines
+// like "  nginx | default", lists the service
+func inRunlevel(output, service string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		name, _, found := strings.Cut(line, "|")
+		if found && strings.TrimSpace(name) == service {
+			return true
+		}
+	}
+	return false
+}
+
+// NewOpenRCProvider creates a new OpenRC provider
+func NewOpenRCProvider() *OpenRCProvider {
+	return &OpenRCProvider{
+		BaseProvider: BaseProvider{Name: "openrc", NeedsRoot: true},
+	}
+}
@@ -1,12 +1,33 @@
 package service
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/platform"
+	"sai/pkg/runner"
+)
+
 // Provider interface defines methods for service management
 type Provider interface {
-	Execute(action, service string) error
+	Execute(ctx context.Context, action, service string) error
+	// Status queries the current state of the service. It only reads the
+	// state, so it runs in dry run mode as well.
+	Status(ctx context.Context, service string) (State, error)
 	GetServiceManager() string
 	IsDryRun() bool
 }
 
+// State is the observed state of a service
+type State struct {
+	Running bool `json:"running" yaml:"running"`
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
 // Supported service actions
 const (
 	ActionStart   = "start"
@@ -46,6 +67,8 @@ func SetDryRun(enabled bool) {
 // BaseProvider common functionality for service providers
 type BaseProvider struct {
 	Name string
+	// NeedsRoot is set for service managers requiring administrator privileges
+	NeedsRoot bool
 }
 
 // GetServiceManager returns the service manager name
@@ -58,6 +81,45 @@ func (p *BaseProvider) IsDryRun() bool {
 	return isDryRunMode
 }
 
+// Run executes the command through the runner attached to the context
+func (p *BaseProvider) Run(ctx context.Context, cmd runner.Command) error {
+	fmt.Printf("Running: %s\n", cmd.String())
+	_, err := runner.Run(ctx, cmd)
+	if errors.Is(err, exec.ErrNotFound) {
+		return &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found in PATH", cmd.Name)}
+	}
+	return err
+}
+
+// Plan adds the command to the execution plan of the result attached to the
+// context instead of running it
+func (p *BaseProvider) Plan(ctx context.Context, cmd runner.Command) error {
+	step := output.NewStep(cmd, p.NeedsRoot, false)
+	output.FromContext(ctx).AddStep(step)
+	if !runner.IsQuiet(ctx) {
+		fmt.Printf("[DRY RUN] Would run: %s\n", step)
+	}
+	return nil
+}
+
+// Query runs a command that reports a condition through its exit status and
+// returns whether the condition holds. The output is captured, not streamed.
+func (p *BaseProvider) Query(ctx context.Context, cmd runner.Command) (bool, *runner.Result, error) {
+	cmd.Quiet = true
+	result, err := runner.Run(ctx, cmd)
+	if err == nil {
+		return true, result, nil
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return false, result, &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found in PATH", cmd.Name)}
+	}
+	var commandErr *errs.CommandFailedError
+	if errors.As(err, &commandErr) {
+		return false, result, nil
+	}
+	return false, result, err
+}
+
 // GetProvider returns the appropriate service provider for the given OS
 func GetProvider(osType string) Provider {
 	switch osType {
@@ -67,3 +129,29 @@ func GetProvider(osType string) Provider {
 		return NewSystemdProvider()
 	}
 }
+
+// ForPlatform returns the service provider for the init system of the
+// detected platform. When the init system is unknown it falls back to OpenRC
+// on Alpine Linux, otherwise to the provider for its OS.
+func ForPlatform(p platform.Platform) Provider {
+	switch p.Init {
+	case platform.InitSystemd:
+		return NewSystemdProvider()
+	case platform.InitOpenRC:
+		return NewOpenRCProvider()
+	case platform.InitSysV:
+		// The init scripts of Alpine are OpenRC scripts
+		if p.Family == platform.FamilyAlpine {
+			return NewOpenRCProvider()
+		}
+		return NewSysVProvider(p.Family == platform.FamilyRedHat || p.Family == platform.FamilySuse)
+	case platform.InitRunit:
+		return NewRunitProvider()
+	case platform.InitS6:
+		return NewS6Provider()
+	}
+	if p.OS == "linux" && p.Family == platform.FamilyAlpine {
+		return NewOpenRCProvider()
+	}
+	return GetProvider(p.OS)
+}
@@ -1,7 +1,16 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/platform"
+	"sai/pkg/runner"
 )
 
 // mockDryRunCheckFunc is used to check if dry run mode is enabled
@@ -13,6 +22,12 @@ func TestDryRunModeServiceProviders(t *testing.T) {
 	// Set mock dry run mode
 	mockDryRunEnabled = true
 	defer func() { mockDryRunEnabled = false }() // Reset after test
+	SetDryRun(true)
+	defer SetDryRun(false)
+
+	// Record commands instead of running them
+	recorder := runner.NewRecorder()
+	ctx := runner.WithRunner(context.Background(), recorder)
 
 	// Test with different service providers
 	// Use all available service provider types
@@ -23,6 +38,10 @@ func TestDryRunModeServiceProviders(t *testing.T) {
 		{"systemd", GetProvider("linux")},
 		{"launchd", GetProvider("darwin")},
 		{"windows", GetProvider("windows")},
+		{"openrc", NewOpenRCProvider()},
+		{"sysvinit", NewSysVProvider(false)},
+		{"runit", NewRunitProvider()},
+		{"s6", NewS6Provider()},
 	}
 
 	// Service management actions to test
@@ -42,16 +61,46 @@ func TestDryRunModeServiceProviders(t *testing.T) {
 
 		for _, action := range actions {
 			t.Run(sp.name+"_"+action, func(t *testing.T) {
-				// Execute should not perform real operations in dry run mode
-				err := sp.provider.Execute(action, "test-service")
+				// Execute should plan the command instead of running it in dry run mode
+				result := output.NewResult("test-service", action)
+				err := sp.provider.Execute(output.WithResult(ctx, result), action, "test-service")
 
-				if err != nil {
-					t.Errorf("Expected no error in dry run mode for %s service provider %s action, got: %v",
-						sp.name, action, err)
+				var unsupported *errs.UnsupportedActionError
+				switch {
+				case errors.As(err, &unsupported):
+					// The provider has no command for this action
+				case err != nil:
+					t.Errorf("Expected no error in dry run mode for %s service provider %s action, got: %v", sp.name, action, err)
+				case len(result.Plan) != 1:
+					t.Errorf("Expected one planned step for %s service provider %s action, got: %v", sp.name, action, result.Plan)
 				}
 			})
 		}
 	}
+
+	if commands := recorder.Commands(); len(commands) != 0 {
+		t.Errorf("Expected no commands to run in dry run mode, got: %v", commands)
+	}
+}
+
+// TestSystemdRunsCommands tests that systemd actions run the expected commands
+func TestSystemdRunsCommands(t *testing.T) {
+	for _, action := range AllServiceActions {
+		t.Run(action, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			ctx := runner.WithRunner(context.Background(), recorder)
+
+			if err := NewSystemdProvider().Execute(ctx, action, "redis"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expected := "systemctl " + action + " redis"
+			commands := recorder.Commands()
+			if len(commands) != 1 || commands[0].String() != expected {
+				t.Errorf("Expected command '%s', got: %v", expected, commands)
+			}
+		})
+	}
 }
 
 // Notes on implementing dry run mode in service providers:
@@ -66,3 +115,235 @@ func TestDryRunModeServiceProviders(t *testing.T) {
 //     }
 //     // Normal execution code...
 // }
+
+// TestSystemdStatus tests that the service state is read from the exit status of systemctl
+func TestSystemdStatus(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Args[0] == "is-active" {
+			return &runner.Result{Command: cmd, ExitCode: 3}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 3}
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	ctx := runner.WithRunner(context.Background(), recorder)
+
+	state, err := NewSystemdProvider().Status(ctx, "redis")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state.Running || !state.Enabled {
+		t.Errorf("Expected a stopped but enabled service, got: %+v", state)
+	}
+	for _, cmd := range recorder.Commands() {
+		if !cmd.Quiet {
+			t.Errorf("Expected status queries to be quiet: %s", cmd.String())
+		}
+	}
+}
+
+// TestForPlatform tests the service manager selected for the detected platform
+func TestForPlatform(t *testing.T) {
+	testCases := []struct {
+		platform platform.Platform
+		expected string
+	}{
+		{platform.Platform{OS: "linux", Distro: "ubuntu", Family: platform.FamilyDebian}, "systemd"},
+		{platform.Platform{OS: "linux", Distro: "alpine", Family: platform.FamilyAlpine}, "openrc"},
+		{platform.Platform{OS: "darwin"}, "brew-services"},
+		{platform.Platform{OS: "linux", Family: platform.FamilyDebian, Init: platform.InitSysV}, "sysvinit"},
+		{platform.Platform{OS: "linux", Family: platform.FamilyAlpine, Init: platform.InitS6}, "s6"},
+		{platform.Platform{OS: "linux", Distro: "alpine", Family: platform.FamilyAlpine, Init: platform.InitSysV}, "openrc"},
+		{platform.Platform{OS: "linux", Family: platform.FamilyOther, Init: platform.InitRunit}, "runit"},
+		{platform.Platform{OS: "linux", Family: platform.FamilyDebian, Init: platform.InitSystemd}, "systemd"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.platform.OS+"_"+tc.platform.Distro, func(t *testing.T) {
+			if got := ForPlatform(tc.platform).GetServiceManager(); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestOpenRCRunsCommands tests that OpenRC actions run the expected commands
+func TestOpenRCRunsCommands(t *testing.T) {
+	expected := map[string]string{
+		ActionStart:   "rc-service redis start",
+		ActionStop:    "rc-service redis stop",
+		ActionRestart: "rc-service redis restart",
+		ActionEnable:  "rc-update add redis default",
+		ActionDisable: "rc-update del redis default",
+	}
+
+	for _, action := range AllServiceActions {
+		t.Run(action, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			ctx := runner.WithRunner(context.Background(), recorder)
+
+			if err := NewOpenRCProvider().Execute(ctx, action, "redis"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			commands := recorder.Commands()
+			if len(commands) != 1 || commands[0].String() != expected[action] {
+				t.Errorf("Expected command '%s', got: %v", expected[action], commands)
+			}
+		})
+	}
+}
+
+// TestOpenRCStatus tests that the service state is read from rc-service and
+// the services of the default runlevel
+func TestOpenRCStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		running  bool
+		runlevel string
+		expected State
+	}{
+		{"Running And Enabled", true, "              nginx | default\n              redis | default\n", State{Running: true, Enabled: true}},
+		{"Stopped", false, "              redis | default\n", State{Enabled: true}},
+		{"Not In Runlevel", true, "         redis-sentinel | default\n", State{Running: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := runner.NewRecorder()
+			recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+				if cmd.Name == "rc-update" {
+					return &runner.Result{Command: cmd, Stdout: tc.runlevel}, nil
+				}
+				if !tc.running {
+					return &runner.Result{Command: cmd, ExitCode: 3}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 3}
+				}
+				return &runner.Result{Command: cmd}, nil
+			}
+			ctx := runner.WithRunner(context.Background(), recorder)
+
+			state, err := NewOpenRCProvider().Status(ctx, "redis")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if state != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, state)
+			}
+		})
+	}
+}
+
+// TestInitProvidersRunCommands tests the commands run by the SysV, runit and s6 providers
+func TestInitProvidersRunCommands(t *testing.T) {
+	runit := NewRunitProvider()
+	runit.ServiceDir = "/var/service"
+	s6 := NewS6Provider()
+	s6.ScanDir = "/run/service"
+
+	testCases := []struct {
+		name     string
+		provider Provider
+		expected map[string]string
+	}{
+		{"sysvinit", NewSysVProvider(false), map[string]string{
+			ActionStart:   "service redis start",
+			ActionStop:    "service redis stop",
+			ActionRestart: "service redis restart",
+			ActionEnable:  "update-rc.d redis enable",
+			ActionDisable: "update-rc.d redis disable",
+		}},
+		{"chkconfig", NewSysVProvider(true), map[string]string{
+			ActionStart:   "service redis start",
+			ActionEnable:  "chkconfig redis on",
+			ActionDisable: "chkconfig redis off",
+		}},
+		{"runit", runit, map[string]string{
+			ActionStart:   "sv start redis",
+			ActionStop:    "sv stop redis",
+			ActionRestart: "sv restart redis",
+			ActionEnable:  "ln -s /etc/sv/redis /var/service/redis",
+			ActionDisable: "rm /var/service/redis",
+		}},
+		{"s6", s6, map[string]string{
+			ActionStart:   "s6-svc -u /run/service/redis",
+			ActionStop:    "s6-svc -d /run/service/redis",
+			ActionRestart: "s6-svc -r /run/service/redis",
+			ActionEnable:  "rm -f /run/service/redis/down",
+			ActionDisable: "touch /run/service/redis/down",
+		}},
+	}
+
+	for _, tc := range testCases {
+		for action, expected := range tc.expected {
+			t.Run(tc.name+"_"+action, func(t *testing.T) {
+				recorder := runner.NewRecorder()
+				ctx := runner.WithRunner(context.Background(), recorder)
+
+				if err := tc.provider.Execute(ctx, action, "redis"); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				commands := recorder.Commands()
+				if len(commands) != 1 || commands[0].String() != expected {
+					t.Errorf("Expected command '%s', got: %v", expected, commands)
+				}
+			})
+		}
+	}
+}
+
+// TestInitProvidersStatus tests how the SysV, runit and s6 providers read the
+// state of a service from their tools and the filesystem
+func TestInitProvidersStatus(t *testing.T) {
+	dir := t.TempDir()
+	for _, path := range []string{"rc2.d", "service/redis", "scan/redis", "scan/nginx"} {
+		if err := os.MkdirAll(filepath.Join(dir, path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{"rc2.d/S01redis", "rc2.d/K01nginx", "scan/nginx/down"} {
+		if err := os.WriteFile(filepath.Join(dir, path), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sysv := NewSysVProvider(false)
+	sysv.RCDir = dir
+	runit := NewRunitProvider()
+	runit.ServiceDir = filepath.Join(dir, "service")
+	s6 := NewS6Provider()
+	s6.ScanDir = filepath.Join(dir, "scan")
+
+	// redis is running and enabled everywhere, nginx is stopped and disabled
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		running := filepath.Base(cmd.Args[len(cmd.Args)-1]) == "redis" || cmd.Args[0] == "redis"
+		switch {
+		case cmd.Name == "sv" && running:
+			return &runner.Result{Command: cmd, Stdout: "run: redis: (pid 123) 45s; run: log: (pid 122) 45s\n"}, nil
+		case cmd.Name == "sv":
+			return &runner.Result{Command: cmd, Stdout: "down: nginx: 12s, normally up\n"}, nil
+		case cmd.Name == "s6-svstat" && running:
+			return &runner.Result{Command: cmd, Stdout: "up (pid 123) 45 seconds\n"}, nil
+		case cmd.Name == "s6-svstat":
+			return &runner.Result{Command: cmd, Stdout: "down (exitcode 0) 12 seconds, normally up, ready 12 seconds\n"}, nil
+		case running:
+			return &runner.Result{Command: cmd}, nil
+		}
+		return &runner.Result{Command: cmd, ExitCode: 3}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 3}
+	}
+	ctx := runner.WithRunner(context.Background(), recorder)
+
+	for _, provider := range []Provider{sysv, runit, s6} {
+		for service, expected := range map[string]State{"redis": {Running: true, Enabled: true}, "nginx": {}} {
+			t.Run(provider.GetServiceManager()+"_"+service, func(t *testing.T) {
+				state, err := provider.Status(ctx, service)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if state != expected {
+					t.Errorf("Expected %+v, got %+v", expected, state)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+)
+
+// runitServiceDirs are the directories runsvdir supervises, in order of
+// preference: Void Linux, Debian and the runit default
+var runitServiceDirs = []string{"/var/service", "/etc/service", "/service"}
+
+// RunitProvider handles runit service operations. A service is enabled by
+// linking its definition into the directory runsvdir supervises.
+type RunitProvider struct {
+	BaseProvider
+	// DefinitionDir holds the service definitions, one directory per service
+	DefinitionDir string
+	// ServiceDir is the directory supervised by runsvdir
+	ServiceDir string
+}
+
+// Execute runs sv commands or links the service into the service directory
+func (p *RunitProvider) Execute(ctx context.Context, action, service string) error {
+	var cmd runner.Command
+	switch action {
+	case ActionStart, ActionStop, ActionRestart:
+		cmd = runner.NewCommand("sv", action, service)
+	case ActionEnable:
+		cmd = runner.NewCommand("ln", "-s", filepath.Join(p.DefinitionDir, service), filepath.Join(p.ServiceDir, service))
+	case ActionDisable:
+		cmd = runner.NewCommand("rm", filepath.Join(p.ServiceDir, service))
+	default:
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Plan(ctx, cmd)
+	}
+
+	fmt.Printf("Executing %s service %s using runit\n", action, service)
+	return p.Run(ctx, cmd)
+}
+
+// Status queries sv for the state of the service, which reports "run:" for a
+// running service. A service is enabled when it is linked into the service
+// directory.
+func (p *RunitProvider) Status(ctx context.Context, service string) (State, error) {
+	var state State
+	ok, result, err := p.Query(ctx, runner.NewCommand("sv", "status", service))
+	if err != nil {
+		return state, err
+	}
+	state.Running = ok && strings.HasPrefix(result.Stdout, "run:")
+	_, err = os.Lstat(filepath.Join(p.ServiceDir, service))
+	state.Enabled = err == nil
+	return state, nil
+}
+
+// NewRunitProvider creates a new runit provider for the first service
+// directory found on the system
+func NewRunitProvider() *RunitProvider {
+	serviceDir := runitServiceDirs[0]
+	for _, dir := range runitServiceDirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			serviceDir = dir
+			break
+		}
+	}
+	return &RunitProvider{
+		BaseProvider:  BaseProvider{Name: "runit", NeedsRoot: true},
+		DefinitionDir: "/etc/sv",
+		ServiceDir:    serviceDir,
+	}
+}
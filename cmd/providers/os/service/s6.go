@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+)
+
+// s6ScanDirs are the scan directories s6-svscan supervises, in order of
+// preference: s6-overlay and the s6 default
+var s6ScanDirs = []string{"/run/service", "/service"}
+
+// s6Signals maps service actions to the s6-svc options sending them
+var s6Signals = map[string]string{
+	ActionStart:   "-u",
+	ActionStop:    "-d",
+	ActionRestart: "-r",
+}
+
+// S6Provider handles s6 service operations. A service is disabled by a down
+// file in its service directory, which keeps s6-supervise from starting it.
+type S6Provider struct {
+	BaseProvider
+	// ScanDir is the directory supervised by s6-svscan
+	ScanDir string
+}
+
+// Execute runs s6-svc commands or adds and removes the down file of the service
+func (p *S6Provider) Execute(ctx context.Context, action, service string) error {
+	dir := filepath.Join(p.ScanDir, service)
+	var cmd runner.Command
+	switch action {
+	case ActionStart, ActionStop, ActionRestart:
+		cmd = runner.NewCommand("s6-svc", s6Signals[action], dir)
+	case ActionEnable:
+		cmd = runner.NewCommand("rm", "-f", filepath.Join(dir, "down"))
+	case ActionDisable:
+		cmd = runner.NewCommand("touch", filepath.Join(dir, "down"))
+	default:
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Plan(ctx, cmd)
+	}
+
+	fmt.Printf("Executing %s service %s using s6\n", action, service)
+	return p.Run(ctx, cmd)
+}
+
+// Status queries s6-svstat for the state of the service, which reports "up"
+// for a running service. A service is enabled when it is supervised and has
+// no down file.
+func (p *S6Provider) Status(ctx context.Context, service string) (State, error) {
+	var state State
+	dir := filepath.Join(p.ScanDir, service)
+	ok, result, err := p.Query(ctx, runner.NewCommand("s6-svstat", dir))
+	if err != nil {
+		return state, err
+	}
+	state.Running = ok && strings.HasPrefix(result.Stdout, "up")
+	if _, err := os.Stat(dir); err == nil {
+		_, err = os.Stat(filepath.Join(dir, "down"))
+		state.Enabled = os.IsNotExist(err)
+	}
+	return state, nil
+}
+
+// NewS6Provider creates a new s6 provider for the first scan directory found
+// on the system
+func NewS6Provider() *S6Provider {
+	scanDir := s6ScanDirs[0]
+	for _, dir := range s6ScanDirs {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			scanDir = dir
+			break
+		}
+	}
+	return &S6Provider{
+		BaseProvider: BaseProvider{Name: "s6", NeedsRoot: true},
+		ScanDir:      scanDir,
+	}
+}
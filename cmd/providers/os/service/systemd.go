@@ -1,8 +1,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
 )
 
 // SystemdProvider handles Linux systemd service operations
@@ -11,45 +14,51 @@ type SystemdProvider struct {
 }
 
 // Execute runs systemd commands
-func (p *SystemdProvider) Execute(action, service string) error {
+func (p *SystemdProvider) Execute(ctx context.Context, action, service string) error {
 	// Validate action
 	if !IsValidAction(action) {
-		return fmt.Errorf("unsupported action '%s' for Systemd provider", action)
-	}
-
-	// Check if in dry run mode
-	if p.IsDryRun() {
-		fmt.Printf("[DRY RUN] Would execute %s service %s using systemd\n", action, service)
-		return nil
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
 	}
 
-	fmt.Printf("Executing %s service %s using systemd\n", action, service)
-
-	var cmd *exec.Cmd
+	var cmd runner.Command
 	switch action {
 	case ActionStart:
-		cmd = exec.Command("systemctl", "start", service)
+		cmd = runner.NewCommand("systemctl", "start", service)
 	case ActionStop:
-		cmd = exec.Command("systemctl", "stop", service)
+		cmd = runner.NewCommand("systemctl", "stop", service)
 	case ActionRestart:
-		cmd = exec.Command("systemctl", "restart", service)
+		cmd = runner.NewCommand("systemctl", "restart", service)
 	case ActionEnable:
-		cmd = exec.Command("systemctl", "enable", service)
+		cmd = runner.NewCommand("systemctl", "enable", service)
 	case ActionDisable:
-		cmd = exec.Command("systemctl", "disable", service)
+		cmd = runner.NewCommand("systemctl", "disable", service)
 	default:
-		fmt.Printf("Action %s not implemented for systemd\n", action)
-		return nil
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
 	}
 
-	// Would actually run the command in a real implementation
-	fmt.Printf("Would run: %s\n", cmd.String())
-	return nil
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Plan(ctx, cmd)
+	}
+
+	fmt.Printf("Executing %s service %s using systemd\n", action, service)
+	return p.Run(ctx, cmd)
+}
+
+// Status queries systemd for the state of the service
+func (p *SystemdProvider) Status(ctx context.Context, service string) (State, error) {
+	var state State
+	var err error
+	if state.Running, _, err = p.Query(ctx, runner.NewCommand("systemctl", "is-active", "--quiet", service)); err != nil {
+		return state, err
+	}
+	state.Enabled, _, err = p.Query(ctx, runner.NewCommand("systemctl", "is-enabled", "--quiet", service))
+	return state, err
 }
 
 // NewSystemdProvider creates a new Systemd provider
 func NewSystemdProvider() *SystemdProvider {
 	return &SystemdProvider{
-		BaseProvider: BaseProvider{Name: "systemd"},
+		BaseProvider: BaseProvider{Name: "systemd", NeedsRoot: true},
 	}
 }
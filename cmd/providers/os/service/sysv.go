@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+)
+
+// SysVProvider handles SysV init script operations. Services are started and
+// stopped through service(8) and enabled with update-rc.d on Debian-like
+// systems or chkconfig on Red Hat-like ones.
+type SysVProvider struct {
+	BaseProvider
+	// Chkconfig selects chkconfig instead of update-rc.d
+	Chkconfig bool
+	// RCDir holds the rc?.d runlevel directories update-rc.d links scripts in
+	RCDir string
+}
+
+// Execute runs service, update-rc.d or chkconfig commands
+func (p *SysVProvider) Execute(ctx context.Context, action, service string) error {
+	var cmd runner.Command
+	switch action {
+	case ActionStart, ActionStop, ActionRestart:
+		cmd = runner.NewCommand("service", service, action)
+	case ActionEnable:
+		cmd = runner.NewCommand("update-rc.d", service, "enable")
+		if p.Chkconfig {
+			cmd = runner.NewCommand("chkconfig", service, "on")
+		}
+	case ActionDisable:
+		cmd = runner.NewCommand("update-rc.d", service, "disable")
+		if p.Chkconfig {
+			cmd = runner.NewCommand("chkconfig", service, "off")
+		}
+	default:
+		return &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	// In dry run mode the command is added to the execution plan instead
+	if p.IsDryRun() {
+		return p.Plan(ctx, cmd)
+	}
+
+	fmt.Printf("Executing %s service %s using sysvinit\n", action, service)
+	return p.Run(ctx, cmd)
+}
+
+// Status queries the init script for the state of the service. LSB scripts
+// exit with 0 when the service is running. chkconfig exits with 0 when the
+// service starts in the current runlevel; with update-rc.d a service is
+// enabled when a start link exists in a multi-user runlevel.
+func (p *SysVProvider) Status(ctx context.Context, service string) (State, error) {
+	var state State
+	var err error
+	if state.Running, _, err = p.Query(ctx, runner.NewCommand("service", service, "status")); err != nil {
+		return state, err
+	}
+	if p.Chkconfig {
+		state.Enabled, _, err = p.Query(ctx, runner.NewCommand("chkconfig", service))
+		return state, err
+	}
+	links, _ := filepath.Glob(filepath.Join(p.RCDir, "rc[2-5].d", "S[0-9][0-9]"+service))
+	state.Enabled = len(links) > 0
+	return state, nil
+}
+
+// NewSysVProvider creates a new SysV init provider, using chkconfig to enable
+// services when asked to
+func NewSysVProvider(chkconfig bool) *SysVProvider {
+	return &SysVProvider{
+		BaseProvider: BaseProvider{Name: "sysvinit", NeedsRoot: true},
+		Chkconfig:    chkconfig,
+		RCDir:        "/etc",
+	}
+}
@@ -1,8 +1,10 @@
 package providers
 
+import "context"
+
 // Provider interface defines methods for provider implementations
 type Provider interface {
-	Execute(action, software string) error
+	Execute(ctx context.Context, action, software string) error
 }
 
 // Supported actions
@@ -1,45 +1,113 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"sai/cmd/handlers"
+	"sai/pkg/audit"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
 
 	"github.com/spf13/cobra"
 )
 
 var providerFlag string
 var dryRunFlag bool
+var outputFlag string
+var versionFlag string
+
+// reportCommands print their own report, so no summary table follows them
+var reportCommands = map[string]bool{
+	"help":         true,
+	"debug":        true,
+	"troubleshoot": true,
+	"config":       true,
+}
 
 // SupportedCommands map of all supported commands
 var SupportedCommands = map[string]handlers.CommandHandler{
-	"install":      func(software string, provider string) { handlers.NewInstallHandler().Handle(software, provider) },
-	"test":         func(software string, provider string) { handlers.NewTestHandler().Handle(software, provider) },
-	"build":        func(software string, provider string) { handlers.NewBuildHandler().Handle(software, provider) },
-	"log":          func(software string, provider string) { handlers.NewLogHandler().Handle(software, provider) },
-	"check":        func(software string, provider string) { handlers.NewCheckHandler().Handle(software, provider) },
-	"observe":      func(software string, provider string) { handlers.NewObserveHandler().Handle(software, provider) },
-	"trace":        func(software string, provider string) { handlers.NewTraceHandler().Handle(software, provider) },
-	"config":       func(software string, provider string) { handlers.NewConfigHandler().Handle(software, provider) },
-	"info":         func(software string, provider string) { handlers.NewInfoHandler().Handle(software, provider) },
-	"debug":        func(software string, provider string) { handlers.NewDebugHandler().Handle(software, provider) },
-	"troubleshoot": func(software string, provider string) { handlers.NewTroubleshootHandler().Handle(software, provider) },
-	"monitor":      func(software string, provider string) { handlers.NewMonitorHandler().Handle(software, provider) },
-	"upgrade":      func(software string, provider string) { handlers.NewUpgradeHandler().Handle(software, provider) },
-	"uninstall":    func(software string, provider string) { handlers.NewUninstallHandler().Handle(software, provider) },
-	"status":       func(software string, provider string) { handlers.NewStatusHandler().Handle(software, provider) },
-	"start":        func(software string, provider string) { handlers.NewStartHandler().Handle(software, provider) },
-	"stop":         func(software string, provider string) { handlers.NewStopHandler().Handle(software, provider) },
-	"restart":      func(software string, provider string) { handlers.NewRestartHandler().Handle(software, provider) },
-	"enable":       func(software string, provider string) { handlers.NewEnableHandler().Handle(software, provider) },
-	"disable":      func(software string, provider string) { handlers.NewDisableHandler().Handle(software, provider) },
-	"list":         func(software string, provider string) { handlers.NewListHandler().Handle(software, provider) },
-	"search":       func(software string, provider string) { handlers.NewSearchHandler().Handle(software, provider) },
-	"update":       func(software string, provider string) { handlers.NewUpdateHandler().Handle(software, provider) },
-	"ask":          func(software string, provider string) { handlers.NewAskHandler().Handle(software, provider) },
-	"help":         func(software string, provider string) { handlers.NewHelpHandler().Handle(software, provider) },
+	"install": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewInstallHandler(), software, provider)
+	},
+	"test": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewTestHandler(), software, provider)
+	},
+	"build": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewBuildHandler(), software, provider)
+	},
+	"log": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewLogHandler(), software, provider)
+	},
+	"check": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewCheckHandler(), software, provider)
+	},
+	"observe": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewObserveHandler(), software, provider)
+	},
+	"trace": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewTraceHandler(), software, provider)
+	},
+	"config": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewConfigHandler(), software, provider)
+	},
+	"info": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewInfoHandler(), software, provider)
+	},
+	"debug": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewDebugHandler(), software, provider)
+	},
+	"troubleshoot": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewTroubleshootHandler(), software, provider)
+	},
+	"monitor": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewMonitorHandler(), software, provider)
+	},
+	"upgrade": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewUpgradeHandler(), software, provider)
+	},
+	"uninstall": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewUninstallHandler(), software, provider)
+	},
+	"status": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewStatusHandler(), software, provider)
+	},
+	"start": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewStartHandler(), software, provider)
+	},
+	"stop": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewStopHandler(), software, provider)
+	},
+	"restart": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewRestartHandler(), software, provider)
+	},
+	"enable": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewEnableHandler(), software, provider)
+	},
+	"disable": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewDisableHandler(), software, provider)
+	},
+	"list": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewListHandler(), software, provider)
+	},
+	"search": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewSearchHandler(), software, provider)
+	},
+	"update": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewUpdateHandler(), software, provider)
+	},
+	"ask": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewAskHandler(), software, provider)
+	},
+	"help": func(ctx context.Context, software, provider string) error {
+		return handlers.Invoke(ctx, handlers.NewHelpHandler(), software, provider)
+	},
 }
 
 var rootCmd = &cobra.Command{
@@ -51,92 +119,169 @@ Usage:
 Example:
   sai nginx install
   sai redis status
+  sai nginx,redis install
+  sai '*' status
+  sai --category database status
   sai ec2 start --provider aws`,
 }
 
-// softwareCmd is dynamically created for each software
-func createSoftwareCmd(software string) *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   software,
-		Short: fmt.Sprintf("Commands for %s", software),
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) == 0 {
-				fmt.Printf("Please specify a command for %s\n", software)
-				_ = cmd.Usage()
-				os.Exit(1)
-			}
-		},
+// handleCommand processes commands in the format: sai <software> <command>
+func handleCommand(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return errs.Usagef("requires at least 2 args")
 	}
 
-	// Add command subcommands to the software command
-	for cmdName, handler := range SupportedCommands {
-		actionCmd := &cobra.Command{
-			Use:   cmdName,
-			Short: fmt.Sprintf("%s %s", cmdName, software),
-			Run: func(cmdName string, handler handlers.CommandHandler) func(*cobra.Command, []string) {
-				return func(cmd *cobra.Command, args []string) {
-					// Set the dry run mode in the handlers package
-					handlers.SetDryRun(dryRunFlag)
-					handler(software, providerFlag)
-				}
-			}(cmdName, handler),
-		}
+	software := args[0]
+	command := args[1]
 
-		// Add the provider and dry-run flags to each command
-		actionCmd.Flags().StringVar(&providerFlag, "provider", "", "Specify a provider to use for this command")
-		actionCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show what commands would be executed without running them")
+	// Set the dry run mode in the handlers package
+	handlers.SetDryRun(dryRunFlag)
 
-		cmd.AddCommand(actionCmd)
+	if _, ok := SupportedCommands[strings.ToLower(command)]; ok {
+		return runAction(cmd.Context(), strings.ToLower(command), software, providerFlag)
 	}
 
-	return cmd
+	return errs.Usagef("unsupported command: %s", command)
 }
 
-// handleCommand processes commands in the format: sai <software> <command>
-func handleCommand(cmd *cobra.Command, args []string) error {
-	if len(args) < 2 {
-		return fmt.Errorf("requires at least 2 args")
+// runAction runs the handler of an action on the selected software and
+// renders its result in the format selected with --output
+func runAction(ctx context.Context, action, software, provider string) error {
+	format, err := output.ParseFormat(outputFlag)
+	if err != nil {
+		return err
+	}
+	targets, err := resolveTargets(action, software)
+	if err != nil {
+		return err
 	}
+	ctx = withSourceOptions(ctx)
+	if isMultiTarget(software) {
+		return runTargets(ctx, format, action, targets, provider)
+	}
+	ctx = handlers.WithVersion(ctx, targets[0].version)
 
-	software := args[0]
-	command := args[1]
+	result, err := executeAction(ctx, format, action, targets[0].name, provider)
+	if format.IsMachine() || !reportCommands[action] {
+		if renderErr := output.Render(os.Stdout, format, result); renderErr != nil && err == nil {
+			err = renderErr
+		}
+	}
+	return err
+}
 
-	// Set the dry run mode in the handlers package
-	handlers.SetDryRun(dryRunFlag)
+// parseVersion splits a name@version software argument and merges it with the
+// --version flag. Only the install and upgrade actions accept a version; a
+// leading @, as in scoped npm packages, is part of the name.
+func parseVersion(action, software, flag string) (string, string, error) {
+	name, version := software, ""
+	if i := strings.LastIndex(software, "@"); i > 0 {
+		name, version = software[:i], software[i+1:]
+		if version == "" {
+			return "", "", errs.Usagef("missing version after @ in %s", software)
+		}
+	}
+	if flag != "" {
+		if version != "" && version != flag {
+			return "", "", errs.Usagef("conflicting versions for %s: %s and --version %s", name, version, flag)
+		}
+		version = flag
+	}
+	if version != "" && !handlers.SupportsVersion(action) {
+		return "", "", errs.Usagef("%s does not accept a version (only install and upgrade do)", action)
+	}
+	return name, version, nil
+}
 
-	if handler, ok := SupportedCommands[strings.ToLower(command)]; ok {
-		handler(software, providerFlag)
-		return nil
+// executeAction runs the handler of an action and returns its structured result
+func executeAction(ctx context.Context, format output.Format, action, software, provider string) (*output.Result, error) {
+	result := output.NewResult(software, action)
+	result.DryRun = dryRunFlag
+
+	restore := redirectMessages(format)
+	err := confirmAction(ctx, action, software, provider)
+	if err == nil {
+		err = SupportedCommands[action](output.Track(ctx, result), software, provider)
 	}
+	restore()
 
-	return fmt.Errorf("unsupported command: %s", command)
+	result.Finish(err)
+	return result, err
+}
+
+// redirectMessages keeps stdout clean for machine-readable output: until the
+// returned function is called, progress messages and the output of the
+// executed commands go to stderr instead
+func redirectMessages(format output.Format) func() {
+	stdout := os.Stdout
+	if format.IsMachine() {
+		os.Stdout = os.Stderr
+	}
+	return func() { os.Stdout = stdout }
 }
 
 func Execute() {
 	// Enable positional arguments with flags
 	cobra.EnableCommandSorting = false
 
-	// Add a run handler for the root command that supports the old format
-	rootCmd.Run = func(cmd *cobra.Command, args []string) {
-		if len(args) < 2 {
-			fmt.Println("Please specify a software and command")
-			_ = cmd.Usage()
-			os.Exit(1)
+	// Add a run handler for the root command that supports the old format.
+	// Arbitrary arguments keep subcommands such as apply from rejecting
+	// software names as unknown commands.
+	rootCmd.Args = cobra.ArbitraryArgs
+	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		// --category selects the software, so only the command is given
+		if categoryFlag != "" && len(args) == 1 {
+			args = []string{"", args[0]}
 		}
-
-		if err := handleCommand(cmd, args); err != nil {
-			fmt.Println(err)
-			_ = cmd.Usage()
-			os.Exit(1)
+		if len(args) < 2 {
+			return errs.Usagef("please specify a software and command")
 		}
+		return handleCommand(cmd, args)
 	}
 
+	// Errors are reported below together with the matching exit code
+	rootCmd.SilenceErrors = true
+	rootCmd.SilenceUsage = true
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &errs.UsageError{Message: err.Error()}
+	})
+
 	// Add global flags to the root command
 	rootCmd.PersistentFlags().StringVar(&providerFlag, "provider", "", "Specify a provider to use for this command")
 	rootCmd.PersistentFlags().BoolVar(&dryRunFlag, "dry-run", false, "Show what commands would be executed without running them")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", "table", "Output format: table, json or yaml")
+	rootCmd.PersistentFlags().StringVar(&versionFlag, "version", "", "Install or upgrade to a specific version")
+	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "Run disruptive and destructive actions without asking for confirmation")
+	rootCmd.PersistentFlags().StringVar(&channelFlag, "channel", "", "Snap channel or flatpak branch to install from")
+	rootCmd.PersistentFlags().StringVar(&remoteFlag, "remote", "", "Flatpak remote to install from")
+	rootCmd.PersistentFlags().BoolVar(&classicFlag, "classic", false, "Install snaps with classic confinement")
+	rootCmd.PersistentFlags().BoolVar(&systemFlag, "system", false, "Install language packages for all users instead of the current user")
+	rootCmd.PersistentFlags().StringVar(&flakeFlag, "flake", "", "Flake reference or nixpkgs revision to install nix packages from")
+	rootCmd.PersistentFlags().StringVar(&prefixFlag, "prefix", "", "Directory release binaries are installed under (default /usr/local for root, ~/.local otherwise)")
+	rootCmd.PersistentFlags().StringVar(&projectFlag, "project", "", "Compose project name (default the name of the software)")
+	rootCmd.PersistentFlags().StringVar(&envFileFlag, "env-file", "", "Env file passed to compose")
+	rootCmd.PersistentFlags().StringVar(&categoryFlag, "category", "", "Act on all software of a category or tag")
+	rootCmd.PersistentFlags().IntVar(&parallelFlag, "parallel", 0, "Targets to act on at the same time (default 4 for read-only actions, 1 otherwise)")
+	rootCmd.PersistentFlags().StringVar(&auditLogFlag, "audit-log", audit.DefaultPath(), "Audit log of the executed commands")
+
+	// Write every executed command to the audit log
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		runner.SetDefault(audit.NewRunner(runner.Default(), audit.NewLog(auditLogFlag)))
+	}
+
+	// Cancel running commands on interrupt
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	handlers.SetContext(ctx)
 
 	// Execute the root command
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	if cmd, err := rootCmd.ExecuteContextC(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var usageErr *errs.UsageError
+		if errors.As(err, &usageErr) {
+			_ = cmd.Usage()
+		}
+		stop()
+		os.Exit(errs.ExitCode(err))
 	}
 }
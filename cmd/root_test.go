@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -9,6 +11,10 @@ import (
 	"testing"
 
 	"sai/cmd/handlers"
+	"sai/pkg/errs"
+	"sai/pkg/platform"
+	"sai/pkg/runner"
+	"sai/pkg/state"
 )
 
 // TestMain sets up the test environment
@@ -16,6 +22,17 @@ func TestMain(m *testing.M) {
 	// Store original os.Args
 	oldArgs := os.Args
 
+	// Record commands instead of running them on the real system
+	runner.SetDefault(runner.NewRecorder())
+	// Do not record test actions in the local state
+	state.SetDefault(nil)
+	// Never wait for answers to confirmation prompts
+	isInteractive = func() bool { return false }
+	// Behave the same whatever the distribution and init system of the host
+	handlers.SetPlatform(&platform.Platform{
+		OS: handlers.OSLinux, Distro: "ubuntu", Family: platform.FamilyDebian, Init: platform.InitSystemd,
+	})
+
 	// Run all tests
 	result := m.Run()
 
@@ -37,9 +54,13 @@ func executeCommand(args ...string) (string, error) {
 	// Set up command arguments
 	os.Args = append([]string{"sai"}, args...)
 
-	// Reset provider and dry-run flags
+	// Reset provider, dry-run, confirmation and output flags
 	providerFlag = ""
 	dryRunFlag = false
+	yesFlag = false
+	categoryFlag = ""
+	parallelFlag = 0
+	outputFlag = ""
 	handlers.SetDryRun(false)
 
 	// Create a new root command for this test
@@ -63,10 +84,12 @@ func executeCommand(args ...string) (string, error) {
 			} else if arg == "--dry-run" {
 				dryRunFlag = true
 				handlers.SetDryRun(true)
+			} else if arg == "--yes" {
+				yesFlag = true
 			}
 		}
 
-		if handler, ok := SupportedCommands[command]; ok {
+		if _, ok := SupportedCommands[command]; ok {
 			w.Close()
 			os.Stdout = oldStdout
 
@@ -75,7 +98,7 @@ func executeCommand(args ...string) (string, error) {
 			os.Stdout = w2
 
 			// Execute the handler directly
-			handler(software, providerFlag)
+			err = runAction(context.Background(), command, software, providerFlag)
 
 			// Restore original stdout
 			w2.Close()
@@ -85,7 +108,7 @@ func executeCommand(args ...string) (string, error) {
 			var buf bytes.Buffer
 			io.Copy(&buf, r2)
 
-			return buf.String(), nil
+			return buf.String(), err
 		} else {
 			err = fmt.Errorf("unsupported command: %s", command)
 		}
@@ -122,7 +145,7 @@ func TestPackageManagementCommands(t *testing.T) {
 
 	for _, tc := range packageCommands {
 		t.Run(tc.command, func(t *testing.T) {
-			output, err := executeCommand(tc.software, tc.command)
+			output, err := executeCommand(tc.software, tc.command, "--yes")
 			if err != nil {
 				t.Fatalf("Failed to execute '%s %s': %v", tc.software, tc.command, err)
 			}
@@ -150,7 +173,7 @@ func TestServiceManagementCommands(t *testing.T) {
 
 	for _, tc := range serviceCommands {
 		t.Run(tc.command, func(t *testing.T) {
-			output, err := executeCommand(tc.service, tc.command)
+			output, err := executeCommand(tc.service, tc.command, "--yes")
 			if err != nil {
 				t.Fatalf("Failed to execute '%s %s': %v", tc.service, tc.command, err)
 			}
@@ -310,3 +333,83 @@ func TestInvalidCommands(t *testing.T) {
 		})
 	}
 }
+
+// TestOutputFormats tests that --output renders a structured result on stdout
+func TestOutputFormats(t *testing.T) {
+	output, err := executeCommand("nginx", "install", "--provider", "apt")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "SOFTWARE") || !strings.Contains(output, "ok") {
+		t.Errorf("Expected a summary table by default, got: %s", output)
+	}
+
+	defer func() { outputFlag = "" }()
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	os.Stderr, _ = os.Open(os.DevNull)
+	providerFlag, dryRunFlag, outputFlag = "apt", false, "json"
+	handlers.SetDryRun(false)
+	err = runAction(context.Background(), "install", "nginx", providerFlag)
+	w.Close()
+	os.Stdout, os.Stderr = oldStdout, oldStderr
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var result struct {
+		Software string `json:"software"`
+		Provider string `json:"provider"`
+		Success  bool   `json:"success"`
+		Commands []struct {
+			Command string `json:"command"`
+		} `json:"commands"`
+	}
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		t.Fatalf("Expected only JSON on stdout: %v", err)
+	}
+	if result.Software != "nginx" || result.Provider != "apt" || !result.Success ||
+		len(result.Commands) != 2 || result.Commands[0].Command != "apt-get install -y nginx" ||
+		result.Commands[1].Command != "dpkg -s nginx" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+// TestParseVersion tests splitting name@version arguments and --version
+func TestParseVersion(t *testing.T) {
+	testCases := []struct {
+		name            string
+		action          string
+		software        string
+		flag            string
+		expectedName    string
+		expectedVersion string
+		expectError     bool
+	}{
+		{"Plain Name", "install", "nginx", "", "nginx", "", false},
+		{"At Version", "install", "nginx@1.24.0", "", "nginx", "1.24.0", false},
+		{"Version Flag", "upgrade", "nginx", "1.24.0", "nginx", "1.24.0", false},
+		{"Same Version Twice", "install", "nginx@1.24.0", "1.24.0", "nginx", "1.24.0", false},
+		{"Scoped Package", "install", "@angular/cli@17.3.0", "", "@angular/cli", "17.3.0", false},
+		{"Scoped Package Without Version", "install", "@angular/cli", "", "@angular/cli", "", false},
+		{"Conflicting Versions", "install", "nginx@1.24.0", "1.22.0", "", "", true},
+		{"Missing Version", "install", "nginx@", "", "", "", true},
+		{"Action Without Versions", "start", "nginx@1.24.0", "", "", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, version, err := parseVersion(tc.action, tc.software, tc.flag)
+			if tc.expectError {
+				if errs.ExitCode(err) != errs.ExitUsage {
+					t.Errorf("Expected a usage error, got %v", err)
+				}
+				return
+			}
+			if err != nil || name != tc.expectedName || version != tc.expectedVersion {
+				t.Errorf("Expected %s and %q, got %s and %q (%v)", tc.expectedName, tc.expectedVersion, name, version, err)
+			}
+		})
+	}
+}
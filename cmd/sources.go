@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+
+	"sai/cmd/providers/definition"
+)
+
+var channelFlag string
+var remoteFlag string
+var classicFlag bool
+var systemFlag bool
+var flakeFlag string
+var prefixFlag string
+var projectFlag string
+var envFileFlag string
+
+// nixpkgsRevision matches a commit of nixpkgs given to --flake instead of a
+// flake reference
+var nixpkgsRevision = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// withSourceOptions attaches the provider options given on the command line
+// to the context as template variables, taking precedence over the saidata
+func withSourceOptions(ctx context.Context) context.Context {
+	vars := make(map[string]string)
+	if channelFlag != "" {
+		vars["channel"] = channelFlag
+		vars["branch"] = channelFlag
+	}
+	if remoteFlag != "" {
+		vars["remote"] = remoteFlag
+	}
+	if classicFlag {
+		vars["confinement"] = "classic"
+	}
+	if systemFlag {
+		vars["system"] = "true"
+	}
+	if prefixFlag != "" {
+		vars["prefix"] = prefixFlag
+	}
+	if projectFlag != "" {
+		vars["project"] = projectFlag
+	}
+	if envFileFlag != "" {
+		// Compose resolves the env file from its own working directory
+		if abs, err := filepath.Abs(envFileFlag); err == nil {
+			vars["env_file"] = abs
+		} else {
+			vars["env_file"] = envFileFlag
+		}
+	}
+	switch {
+	case nixpkgsRevision.MatchString(flakeFlag):
+		vars["revision"] = flakeFlag
+	case flakeFlag != "":
+		// A revision from the saidata would take precedence over the flake
+		vars["flake"], vars["revision"] = flakeFlag, ""
+	}
+	if len(vars) == 0 {
+		return ctx
+	}
+	return definition.WithVariables(ctx, vars)
+}
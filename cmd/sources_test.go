@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sai/cmd/providers/container"
+	"sai/pkg/runner"
+)
+
+// TestSourceOptions tests the channel, remote, confinement and system flags
+func TestSourceOptions(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	defer func() { channelFlag, remoteFlag, classicFlag, systemFlag = "", "", false, false }()
+
+	testCases := []struct {
+		name     string
+		software string
+		provider string
+		channel  string
+		remote   string
+		classic  bool
+		system   bool
+		expected string
+	}{
+		{"Snap Defaults", "code", "snap", "", "", false, false, "snap install code"},
+		{"Snap Channel And Classic", "code", "snap", "latest/edge", "", true, false, "snap install code --channel=latest/edge --classic"},
+		{"Flatpak Defaults", "org.gimp.GIMP", "flatpak", "", "", false, false, "flatpak install -y flathub org.gimp.GIMP"},
+		{"Flatpak Remote And Branch", "org.gimp.GIMP", "flatpak", "beta", "flathub-beta", false, false, "flatpak install -y flathub-beta org.gimp.GIMP//beta"},
+		{"Ignored By Other Providers", "nginx", "apt", "latest/edge", "flathub", true, true, "apt-get install -y nginx"},
+		{"Language System", "black", "pip", "", "", false, true, "pip3 install black"},
+		{"Language User", "black", "pip", "", "", false, false, "pip3 install --user black"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder.Reset()
+			channelFlag, remoteFlag, classicFlag, systemFlag = tc.channel, tc.remote, tc.classic, tc.system
+
+			restore := silenceMessages()
+			err := runAction(context.Background(), "install", tc.software, tc.provider)
+			restore()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			commands := recorder.Commands()
+			if len(commands) == 0 || commands[0].String() != tc.expected {
+				t.Errorf("Expected %q, got %v", tc.expected, commands)
+			}
+		})
+	}
+}
+
+// TestFlakeOption tests selecting the flake or nixpkgs revision nix installs from
+func TestFlakeOption(t *testing.T) {
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	defer func() { flakeFlag = "" }()
+
+	testCases := []struct {
+		name     string
+		flake    string
+		expected string
+	}{
+		{"Default", "", "nixpkgs#jq"},
+		{"Flake Reference", "github:NixOS/nixpkgs/nixos-24.05", "github:NixOS/nixpkgs/nixos-24.05#jq"},
+		{"Revision", "5ad9903c16126a7d949101687af0aa589b1d7d3d", "github:NixOS/nixpkgs/5ad9903c16126a7d949101687af0aa589b1d7d3d#jq"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder.Reset()
+			flakeFlag = tc.flake
+
+			restore := silenceMessages()
+			err := runAction(context.Background(), "install", "jq", "nix")
+			restore()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			commands := recorder.Commands()
+			if len(commands) == 0 || commands[0].Args[len(commands[0].Args)-1] != tc.expected {
+				t.Errorf("Expected an install from %q, got %v", tc.expected, commands)
+			}
+		})
+	}
+}
+
+// TestComposeOptions tests naming the compose project and its env file
+func TestComposeOptions(t *testing.T) {
+	t.Setenv("SAI_STATE_DIR", t.TempDir())
+	recorder := runner.NewRecorder()
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	defer func() { projectFlag, envFileFlag = "", "" }()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectFlag, envFileFlag = "staging", "staging.env"
+
+	restore := silenceMessages()
+	err = runAction(context.Background(), "log", "prometheus", "compose")
+	restore()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "docker compose -p staging -f " + filepath.Join(container.ComposeDir(), "staging", "compose.yaml") +
+		" --env-file " + filepath.Join(wd, "staging.env") + " logs"
+	if commands := recorder.Commands(); len(commands) == 0 || commands[len(commands)-1].String() != expected {
+		t.Errorf("Expected %q, got %v", expected, commands)
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/state"
+
+	"github.com/spf13/cobra"
+)
+
+// stateCmd shows what sai has installed or changed on this machine
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Show the software sai has installed or changed",
+	Long: `State shows the local record of every successful change made by sai: which
+provider installed a piece of software, which version, when and by whom, and
+the exact commands that were run. Uninstall and upgrade reuse the recorded
+provider when --provider is not given.
+
+The state is kept in $SAI_STATE_DIR, /var/lib/sai when running as root, or
+~/.local/state/sai.`,
+}
+
+var stateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the software recorded in the local state",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listState(os.Stdout)
+	},
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show <software>",
+	Short: "Show the recorded state and history of a piece of software",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errs.Usagef("state show requires exactly one software name")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showState(os.Stdout, args[0])
+	},
+}
+
+func init() {
+	stateCmd.AddCommand(stateListCmd, stateShowCmd)
+	rootCmd.AddCommand(stateCmd)
+}
+
+// openState returns the store holding the local state
+func openState() (*state.Store, error) {
+	store := state.Default()
+	if store == nil {
+		return nil, fmt.Errorf("the local state is disabled")
+	}
+	return store, nil
+}
+
+// listState writes all records of the local state
+func listState(w io.Writer) error {
+	format, err := output.ParseFormat(outputFlag)
+	if err != nil {
+		return err
+	}
+	store, err := openState()
+	if err != nil {
+		return err
+	}
+	records, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if format.IsMachine() {
+		return output.Encode(w, format, records)
+	}
+	if len(records) == 0 {
+		fmt.Fprintf(w, "No software recorded in %s\n", store.Path())
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOFTWARE\tSTATE\tPROVIDER\tVERSION\tUPDATED")
+	for _, r := range records {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Software, recordState(r), output.Dash(r.Provider),
+			output.Dash(r.Version), r.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+// showState writes the record and history of a piece of software
+func showState(w io.Writer, software string) error {
+	format, err := output.ParseFormat(outputFlag)
+	if err != nil {
+		return err
+	}
+	store, err := openState()
+	if err != nil {
+		return err
+	}
+	record, err := store.Get(software)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return fmt.Errorf("%w in the local state %s", &errs.SoftwareNotFoundError{Software: software}, store.Path())
+	}
+
+	if format.IsMachine() {
+		return output.Encode(w, format, record)
+	}
+	fmt.Fprintf(w, "Software: %s\n", record.Software)
+	fmt.Fprintf(w, "State:    %s\n", recordState(*record))
+	fmt.Fprintf(w, "Provider: %s\n", output.Dash(record.Provider))
+	fmt.Fprintf(w, "Package:  %s\n", output.Dash(record.Target))
+	fmt.Fprintf(w, "Version:  %s\n", output.Dash(record.Version))
+	fmt.Fprintf(w, "Updated:  %s\n", record.UpdatedAt.Local().Format(time.DateTime))
+	fmt.Fprintln(w, "\nHistory:")
+	for _, a := range record.History {
+		fmt.Fprintf(w, "  %s  %s via %s by %s\n", a.Time.Local().Format(time.DateTime), a.Action, output.Dash(a.Provider), output.Dash(a.User))
+		for _, c := range a.Commands {
+			fmt.Fprintf(w, "      $ %s\n", c)
+		}
+	}
+	return nil
+}
+
+// recordState returns whether the recorded software is installed
+func recordState(r state.Record) string {
+	if r.Installed {
+		return "installed"
+	}
+	if len(r.History) > 0 && r.History[len(r.History)-1].Action == "uninstall" {
+		return "removed"
+	}
+	return "changed"
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"sai/pkg/errs"
+	"sai/pkg/state"
+)
+
+// TestStateCommands tests listing and showing the local state
+func TestStateCommands(t *testing.T) {
+	store := state.NewStore(filepath.Join(t.TempDir(), state.FileName))
+	state.SetDefault(store)
+	defer state.SetDefault(nil)
+	defer func() { outputFlag = "" }()
+
+	err := store.Record("nginx", state.Action{
+		Action: "install", Provider: "apt", Version: "1.22.1-9", Time: time.Now(), User: "ops",
+		Commands: []string{"apt-get install -y nginx"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	outputFlag = ""
+	if err := listState(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "nginx") || !strings.Contains(buf.String(), "1.22.1-9") {
+		t.Errorf("Expected nginx in the list, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := showState(&buf, "nginx"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "$ apt-get install -y nginx") {
+		t.Errorf("Expected the executed command in the history, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	outputFlag = "json"
+	if err := showState(&buf, "nginx"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var record state.Record
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil || record.Provider != "apt" {
+		t.Errorf("Expected a JSON record, got: %s (%v)", buf.String(), err)
+	}
+
+	if err := showState(&buf, "redis"); errs.ExitCode(err) != errs.ExitSoftwareNotFound {
+		t.Errorf("Expected software not found, got: %v", err)
+	}
+}
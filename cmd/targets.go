@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"sai/cmd/handlers"
+	"sai/pkg/data"
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/providers"
+	"sai/pkg/runner"
+)
+
+var categoryFlag string
+var parallelFlag int
+
+// defaultParallel is how many targets read-only actions act on at the same time
+const defaultParallel = 4
+
+// target is a piece of software an action runs on
+type target struct {
+	name    string
+	version string
+}
+
+// isMultiTarget reports whether the software argument selects several
+// targets: '*' for all managed software, a comma-separated list or --category
+func isMultiTarget(software string) bool {
+	return categoryFlag != "" || software == "*" || strings.Contains(software, ",")
+}
+
+// resolveTargets returns the targets selected by the software argument and
+// --category, with the version requested for each of them
+func resolveTargets(action, software string) ([]target, error) {
+	var names []string
+	switch {
+	case categoryFlag != "":
+		if software != "" {
+			return nil, errs.Usagef("--category selects the software; do not name software as well")
+		}
+		matches, err := data.SoftwareInCategory(categoryFlag)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, errs.Usagef("no software in category %s", categoryFlag)
+		}
+		for _, sw := range matches {
+			names = append(names, sw.Name)
+		}
+	case software == "*":
+		managed, err := providers.GetAllManagedSoftware()
+		if err != nil {
+			return nil, err
+		}
+		if len(managed) == 0 {
+			return nil, errors.New("no managed software found: software installed with sai is listed by sai state list")
+		}
+		names = managed
+	default:
+		seen := make(map[string]bool)
+		for _, name := range strings.Split(software, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return nil, errs.Usagef("please specify the software for %s", action)
+		}
+	}
+
+	if versionFlag != "" && len(names) > 1 {
+		return nil, errs.Usagef("--version applies to a single software; use name@version for each of them")
+	}
+	targets := make([]target, len(names))
+	for i, name := range names {
+		name, version, err := parseVersion(action, name, versionFlag)
+		if err != nil {
+			return nil, err
+		}
+		targets[i] = target{name: name, version: version}
+	}
+	return targets, nil
+}
+
+// concurrency returns how many targets an action runs on at the same time:
+// --parallel when given, otherwise a few for read-only actions and one for
+// changes, since package managers lock their databases while they work
+func concurrency(action string, targets int) int {
+	limit := parallelFlag
+	if limit <= 0 {
+		limit = 1
+		if handlers.ActionRisk(action) == handlers.RiskReadOnly {
+			limit = defaultParallel
+		}
+	}
+	if limit > targets {
+		limit = targets
+	}
+	return limit
+}
+
+// runTargets runs an action on several targets with bounded concurrency and
+// renders the results of all of them followed by a summary. A failing target
+// does not stop the others.
+func runTargets(ctx context.Context, format output.Format, action string, targets []target, provider string) error {
+	restore := redirectMessages(format)
+	if err := confirmTargets(ctx, action, targets, provider); err != nil {
+		restore()
+		return err
+	}
+	ctx = withConfirmed(ctx)
+
+	// The output of commands running side by side would interleave, so it
+	// is only captured in the results
+	limit := concurrency(action, len(targets))
+	if limit > 1 {
+		ctx = runner.WithQuiet(ctx)
+	}
+
+	results := make([]*output.Result, len(targets))
+	failures := make([]error, len(targets))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, t target) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result := output.NewResult(t.name, action)
+			result.DryRun = dryRunFlag
+			err := SupportedCommands[action](output.Track(handlers.WithVersion(ctx, t.version), result), t.name, provider)
+			result.Finish(err)
+			results[i], failures[i] = result, err
+		}(i, t)
+	}
+	wg.Wait()
+	restore()
+
+	var firstErr error
+	failed := 0
+	for _, err := range failures {
+		if err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if err := output.Render(os.Stdout, format, results...); err != nil && firstErr == nil {
+		return err
+	}
+	if !format.IsMachine() {
+		fmt.Printf("\n%d of %d succeeded\n", len(targets)-failed, len(targets))
+	}
+	if firstErr != nil {
+		return fmt.Errorf("%s failed for %d of %d targets, first: %w", action, failed, len(targets), firstErr)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"sai/cmd/handlers"
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+	"sai/pkg/state"
+)
+
+// TestResolveTargets tests the selection of targets from the software argument
+func TestResolveTargets(t *testing.T) {
+	store := state.NewStore(filepath.Join(t.TempDir(), state.FileName))
+	now := time.Now().UTC()
+	store.Record("jq", state.Action{Action: "install", Provider: "apt", Time: now})
+	store.Record("git", state.Action{Action: "install", Provider: "apt", Time: now})
+	store.Record("git", state.Action{Action: "uninstall", Provider: "apt", Time: now})
+	state.SetDefault(store)
+	defer state.SetDefault(nil)
+	defer func() { categoryFlag, versionFlag = "", "" }()
+
+	testCases := []struct {
+		name     string
+		action   string
+		software string
+		category string
+		version  string
+		expected []target
+		exitCode int
+	}{
+		{"Single", "status", "nginx", "", "", []target{{name: "nginx"}}, errs.ExitOK},
+		{"List", "status", "nginx, redis,,nginx", "", "", []target{{name: "nginx"}, {name: "redis"}}, errs.ExitOK},
+		{"List With Versions", "install", "nginx@1.24.0,redis", "", "", []target{{"nginx", "1.24.0"}, {name: "redis"}}, errs.ExitOK},
+		{"Managed Software", "status", "*", "", "", []target{{name: "jq"}}, errs.ExitOK},
+		{"Category", "status", "", "sql", "", []target{{name: "mysql"}, {name: "postgresql"}}, errs.ExitOK},
+		{"Category And Software", "status", "nginx", "sql", "", nil, errs.ExitUsage},
+		{"Empty Category", "status", "", "no-such-category", "", nil, errs.ExitUsage},
+		{"Version For Several", "install", "nginx,redis", "", "1.24.0", nil, errs.ExitUsage},
+		{"Empty List", "status", ",", "", "", nil, errs.ExitUsage},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			categoryFlag, versionFlag = tc.category, tc.version
+			targets, err := resolveTargets(tc.action, tc.software)
+			if code := errs.ExitCode(err); code != tc.exitCode {
+				t.Fatalf("Expected exit code %d, got %d (error: %v)", tc.exitCode, code, err)
+			}
+			if err == nil && !reflect.DeepEqual(targets, tc.expected) {
+				t.Errorf("Expected %+v, got %+v", tc.expected, targets)
+			}
+		})
+	}
+
+	state.SetDefault(state.NewStore(filepath.Join(t.TempDir(), state.FileName)))
+	if _, err := resolveTargets("status", "*"); err == nil || !strings.Contains(err.Error(), "no managed software") {
+		t.Errorf("Expected an error without managed software, got %v", err)
+	}
+}
+
+// TestConcurrency tests how many targets run at the same time
+func TestConcurrency(t *testing.T) {
+	defer func() { parallelFlag = 0 }()
+
+	testCases := []struct {
+		name     string
+		action   string
+		parallel int
+		targets  int
+		expected int
+	}{
+		{"Read-Only", "status", 0, 10, defaultParallel},
+		{"Fewer Targets", "status", 0, 2, 2},
+		{"Changes One At A Time", "install", 0, 10, 1},
+		{"Explicit", "install", 3, 10, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parallelFlag = tc.parallel
+			if got := concurrency(tc.action, tc.targets); got != tc.expected {
+				t.Errorf("Expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestRunTargets tests running an action on several targets
+func TestRunTargets(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Args[len(cmd.Args)-1] == "missing" {
+			return &runner.Result{Command: cmd, ExitCode: 100}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 100}
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	runner.SetDefault(recorder)
+	defer runner.SetDefault(runner.NewRecorder())
+	defer func() {
+		outputFlag, yesFlag, parallelFlag = "", false, 0
+		handlers.SetDryRun(false)
+	}()
+	handlers.SetDryRun(false)
+
+	run := func(action, software string) (string, error) {
+		oldStdout, oldStderr := os.Stdout, os.Stderr
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+		os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		err := runAction(context.Background(), action, software, "apt")
+		w.Close()
+		os.Stdout, os.Stderr = oldStdout, oldStderr
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		return buf.String(), err
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		recorder.Reset()
+		outputFlag, parallelFlag = "json", 2
+		out, err := run("status", "nginx,jq,redis")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var results []struct {
+			Software string `json:"software"`
+			Success  bool   `json:"success"`
+		}
+		if err := json.Unmarshal([]byte(out), &results); err != nil {
+			t.Fatalf("Expected a JSON list of results: %v\n%s", err, out)
+		}
+		if len(results) != 3 || results[0].Software != "nginx" || results[1].Software != "jq" || results[2].Software != "redis" {
+			t.Errorf("Expected a result per target in order, got %+v", results)
+		}
+		if len(recorder.Commands()) != 3 {
+			t.Errorf("Expected a status query per target, got %v", recorder.Commands())
+		}
+	})
+
+	t.Run("Partial Failure", func(t *testing.T) {
+		recorder.Reset()
+		outputFlag, parallelFlag = "", 0
+		out, err := run("install", "missing,jq")
+		var commandErr *errs.CommandFailedError
+		if !errors.As(err, &commandErr) || !strings.Contains(err.Error(), "failed for 1 of 2 targets") {
+			t.Errorf("Expected the failure of one target, got %v", err)
+		}
+		if !strings.Contains(out, "1 of 2 succeeded") {
+			t.Errorf("Expected a summary, got: %s", out)
+		}
+		installed := false
+		for _, cmd := range recorder.Commands() {
+			installed = installed || cmd.String() == "apt-get install -y jq"
+		}
+		if !installed {
+			t.Errorf("Expected jq to be installed after the failure, got %v", recorder.Commands())
+		}
+	})
+
+	t.Run("Confirmation", func(t *testing.T) {
+		recorder.Reset()
+		outputFlag = ""
+		_, err := run("uninstall", "nginx,jq")
+		var confirmErr *errs.ConfirmationError
+		if !errors.As(err, &confirmErr) || confirmErr.Software != "nginx, jq" {
+			t.Errorf("Expected a single confirmation error, got %v", err)
+		}
+		if commands := recorder.Commands(); len(commands) != 0 {
+			t.Errorf("Expected no commands without confirmation, got %v", commands)
+		}
+	})
+}
@@ -0,0 +1,271 @@
+// Package audit keeps an append-only log of every command executed by sai in
+// JSON Lines format.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+	"sai/pkg/state"
+)
+
+// Default rotation settings
+const (
+	DefaultMaxSize    = 10 << 20 // 10 MiB
+	DefaultMaxBackups = 5
+)
+
+// Entry is an executed command
+type Entry struct {
+	Time       time.Time `json:"time" yaml:"time"`
+	User       string    `json:"user,omitempty" yaml:"user,omitempty"`
+	Argv       []string  `json:"argv" yaml:"argv"`
+	Dir        string    `json:"dir,omitempty" yaml:"dir,omitempty"`
+	Software   string    `json:"software,omitempty" yaml:"software,omitempty"`
+	Action     string    `json:"action,omitempty" yaml:"action,omitempty"`
+	Provider   string    `json:"provider,omitempty" yaml:"provider,omitempty"`
+	ExitCode   int       `json:"exit_code" yaml:"exit_code"`
+	DurationMS int64     `json:"duration_ms" yaml:"duration_ms"`
+	DryRun     bool      `json:"dry_run" yaml:"dry_run"`
+	Error      string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Filter selects entries of the audit log. Zero fields match everything.
+type Filter struct {
+	Since    time.Time
+	Until    time.Time
+	Software string
+	Action   string
+}
+
+// Match reports whether the entry is selected by the filter
+func (f Filter) Match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	if f.Software != "" && e.Software != f.Software {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	return true
+}
+
+// Log is an audit log file rotated by size. Rotated files are named after
+// the log with a numeric suffix, .1 being the most recent.
+type Log struct {
+	path string
+	// MaxSize is the size in bytes above which the log is rotated
+	MaxSize int64
+	// MaxBackups is the number of rotated files kept
+	MaxBackups int
+
+	mu sync.Mutex
+}
+
+// NewLog creates an audit log writing to the given file
+func NewLog(path string) *Log {
+	return &Log{path: path, MaxSize: DefaultMaxSize, MaxBackups: DefaultMaxBackups}
+}
+
+// Path returns the file the log writes to
+func (l *Log) Path() string {
+	return l.path
+}
+
+// DefaultPath returns the audit log path: SAI_AUDIT_LOG when set,
+// /var/log/sai/audit.jsonl for root, and the state directory otherwise
+func DefaultPath() string {
+	if path := os.Getenv("SAI_AUDIT_LOG"); path != "" {
+		return path
+	}
+	if os.Getenv("SAI_STATE_DIR") == "" && os.Geteuid() == 0 {
+		return "/var/log/sai/audit.jsonl"
+	}
+	return filepath.Join(state.Dir(), "audit.jsonl")
+}
+
+// Append writes an entry at the end of the log, rotating it first when it
+// has grown past MaxSize
+func (l *Log) Append(e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+	if info, err := os.Stat(l.path); err == nil && l.MaxSize > 0 && info.Size()+int64(len(line)) >= l.MaxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// rotate shifts the rotated files and moves the current log to .1
+func (l *Log) rotate() error {
+	if l.MaxBackups <= 0 {
+		return os.Remove(l.path)
+	}
+	os.Remove(l.backup(l.MaxBackups))
+	for i := l.MaxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(l.path, l.backup(1))
+}
+
+// backup returns the name of the n-th rotated file
+func (l *Log) backup(n int) string {
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+// Query returns the entries selected by the filter, oldest first, reading
+// the rotated files as well
+func (l *Log) Query(f Filter) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := []Entry{}
+	files := []string{}
+	for i := l.MaxBackups; i >= 1; i-- {
+		files = append(files, l.backup(i))
+	}
+	files = append(files, l.path)
+
+	for _, path := range files {
+		file, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = readEntries(file, func(e Entry) {
+			if f.Match(e) {
+				entries = append(entries, e)
+			}
+		})
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	}
+	return entries, nil
+}
+
+// readEntries decodes one entry per line, skipping blank lines
+func readEntries(r io.Reader, fn func(Entry)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		fn(e)
+	}
+	return scanner.Err()
+}
+
+// Global variable to track dry run mode
+var isDryRunMode = false
+
+// SetDryRun marks the commands logged from now on as run during a dry run,
+// such as the status queries resolving a plan
+func SetDryRun(enabled bool) {
+	isDryRunMode = enabled
+}
+
+// Runner wraps a runner and writes every executed command to the audit log.
+// The software, action and provider are taken from the result attached to
+// the context, if any.
+type Runner struct {
+	runner runner.Runner
+	log    *Log
+	user   string
+	warn   sync.Once
+}
+
+// NewRunner creates a runner logging the commands run by r
+func NewRunner(r runner.Runner, log *Log) *Runner {
+	return &Runner{runner: r, log: log, user: state.CurrentUser()}
+}
+
+// Run runs the command and appends it to the audit log. Failing to write the
+// log does not fail the command; a warning is printed once instead.
+func (a *Runner) Run(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+	started := time.Now()
+	result, err := a.runner.Run(ctx, cmd)
+
+	entry := Entry{
+		Time:       started.UTC(),
+		User:       a.user,
+		Argv:       cmd.Argv(),
+		Dir:        cmd.Dir,
+		ExitCode:   errs.ExitOK,
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+	if entry.Dir == "" {
+		entry.Dir, _ = os.Getwd()
+	}
+	if result != nil {
+		entry.ExitCode = result.ExitCode
+	} else if err != nil {
+		entry.ExitCode = -1
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if r := output.FromContext(ctx); r != nil {
+		entry.Software, entry.Action, entry.Provider, entry.DryRun = r.Identity()
+	}
+	entry.DryRun = entry.DryRun || isDryRunMode
+
+	if logErr := a.log.Append(entry); logErr != nil {
+		a.warn.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: writing the audit log %s: %v\n", a.log.Path(), logErr)
+		})
+	}
+	return result, err
+}
+
+// LookPath delegates to the wrapped runner
+func (a *Runner) LookPath(file string) (string, error) {
+	return runner.LookPath(runner.WithRunner(context.Background(), a.runner), file)
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// TestRunnerLogsCommands tests that executed commands are appended to the log
+func TestRunnerLogsCommands(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		if cmd.Args[0] == "-s" {
+			return &runner.Result{Command: cmd, ExitCode: 1}, &errs.CommandFailedError{Command: cmd.String(), ExitCode: 1}
+		}
+		return &runner.Result{Command: cmd}, nil
+	}
+	r := NewRunner(recorder, log)
+
+	result := output.NewResult("nginx", "install")
+	result.SetProvider("apt", "os", "nginx")
+	ctx := output.WithResult(context.Background(), result)
+	if _, err := r.Run(ctx, runner.NewCommand("apt-get", "install", "-y", "nginx")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := r.Run(context.Background(), runner.NewCommand("dpkg", "-s", "nginx")); err == nil {
+		t.Fatalf("Expected the command error to be returned")
+	}
+
+	entries, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got: %+v", entries)
+	}
+	first := entries[0]
+	if first.Software != "nginx" || first.Action != "install" || first.Provider != "apt" ||
+		len(first.Argv) != 4 || first.ExitCode != 0 || first.Dir == "" {
+		t.Errorf("Unexpected entry: %+v", first)
+	}
+	if entries[1].ExitCode != 1 || entries[1].Error == "" || entries[1].Software != "" {
+		t.Errorf("Expected a failed entry without software, got: %+v", entries[1])
+	}
+}
+
+// TestRunnerLogsDryRun tests that the commands run during a dry run, such as
+// status queries, are logged as such even without a result
+func TestRunnerLogsDryRun(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	r := NewRunner(runner.NewRecorder(), log)
+
+	SetDryRun(true)
+	_, err := r.Run(context.Background(), runner.NewCommand("dpkg", "-s", "nginx"))
+	SetDryRun(false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := r.Run(context.Background(), runner.NewCommand("apt-get", "install", "-y", "nginx")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	entries, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(entries) != 2 || !entries[0].DryRun || entries[1].DryRun {
+		t.Errorf("Expected only the first entry to be logged as a dry run, got: %+v", entries)
+	}
+}
+
+// TestQueryFilter tests selecting entries by time, software and action
+func TestQueryFilter(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, e := range []Entry{
+		{Software: "nginx", Action: "install"},
+		{Software: "nginx", Action: "start"},
+		{Software: "redis", Action: "install"},
+	} {
+		e.Time = base.Add(time.Duration(i) * time.Hour)
+		e.Argv = []string{"true"}
+		if err := log.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		filter   Filter
+		expected int
+	}{
+		{"All", Filter{}, 3},
+		{"Software", Filter{Software: "nginx"}, 2},
+		{"Action", Filter{Action: "install"}, 2},
+		{"Since", Filter{Since: base.Add(30 * time.Minute)}, 2},
+		{"Range", Filter{Since: base.Add(30 * time.Minute), Until: base.Add(90 * time.Minute)}, 1},
+		{"No Match", Filter{Software: "mysql"}, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := log.Query(tc.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(entries) != tc.expected {
+				t.Errorf("Expected %d entries, got %d", tc.expected, len(entries))
+			}
+		})
+	}
+}
+
+// TestRotation tests that the log is rotated by size and queried across files
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewLog(path)
+	log.MaxSize = 200
+	log.MaxBackups = 2
+
+	for i := 0; i < 10; i++ {
+		if err := log.Append(Entry{Time: time.Now(), Argv: []string{"echo", "entry"}, Software: "nginx"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Errorf("Expected a rotated file: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected at most 2 rotated files")
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() >= 200 {
+		t.Errorf("Expected the current log to stay below the maximum size")
+	}
+
+	entries, err := log.Query(Filter{})
+	if err != nil || len(entries) == 0 || len(entries) >= 10 {
+		t.Errorf("Expected the oldest entries to be dropped, got %d entries (%v)", len(entries), err)
+	}
+}
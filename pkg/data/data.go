@@ -1,41 +1,550 @@
 package data
 
 import (
+	"bytes"
+	"context"
+	"embed"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"sai/pkg/errs"
 )
 
+// Software describes a piece of software and how to manage it on each provider
 type Software struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Categories  []string `json:"categories"`
-	ConfigFile  string   `json:"config_file"`
-	Tags        []string `json:"tags"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Aliases     []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+	Categories  []string `json:"categories,omitempty" yaml:"categories,omitempty"`
+	ConfigFile  string   `json:"config_file,omitempty" yaml:"config_file,omitempty"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	Packages    []Package  `json:"packages,omitempty" yaml:"packages,omitempty"`
+	Services    []Service  `json:"services,omitempty" yaml:"services,omitempty"`
+	ConfigFiles []string   `json:"config_files,omitempty" yaml:"config_files,omitempty"`
+	LogFiles    []string   `json:"log_files,omitempty" yaml:"log_files,omitempty"`
+	Ports       []Port     `json:"ports,omitempty" yaml:"ports,omitempty"`
+	Processes   []string   `json:"processes,omitempty" yaml:"processes,omitempty"`
+	DataDirs    []string   `json:"data_dirs,omitempty" yaml:"data_dirs,omitempty"`
+	Helm        *HelmChart `json:"helm,omitempty" yaml:"helm,omitempty"`
+	Container   *Container `json:"container,omitempty" yaml:"container,omitempty"`
+	Compose     *Compose   `json:"compose,omitempty" yaml:"compose,omitempty"`
+	Snap        *Snap      `json:"snap,omitempty" yaml:"snap,omitempty"`
+	Flatpak     *Flatpak   `json:"flatpak,omitempty" yaml:"flatpak,omitempty"`
+	Nix         *Nix       `json:"nix,omitempty" yaml:"nix,omitempty"`
+	Binary      *Binary    `json:"binary,omitempty" yaml:"binary,omitempty"`
+	Source      *Source    `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Known is false for software resolved without saidata
+	Known bool `json:"-" yaml:"-"`
 }
 
-var softwareData []Software
+// Selector restricts an entry to a provider and/or a distribution.
+// Empty fields match everything.
+type Selector struct {
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	// Distro matches either a distribution ID (e.g. "ubuntu") or a family (e.g. "debian")
+	Distro string `json:"distro,omitempty" yaml:"distro,omitempty"`
+}
 
-func LoadData(filePath string) error {
-	file, err := os.Open(filePath)
+// Package is the name of the package providing the software
+type Package struct {
+	Selector `yaml:",inline"`
+	Name     string `json:"name" yaml:"name"`
+}
+
+// Service is the name of a service unit started by the software
+type Service struct {
+	Selector `yaml:",inline"`
+	Name     string `json:"name" yaml:"name"`
+}
+
+// Port is a network port used by the software
+type Port struct {
+	Port        int    `json:"port" yaml:"port"`
+	Protocol    string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// HelmChart describes how to deploy the software with Helm
+type HelmChart struct {
+	Repo      string `json:"repo,omitempty" yaml:"repo,omitempty"`
+	RepoURL   string `json:"repo_url,omitempty" yaml:"repo_url,omitempty"`
+	Chart     string `json:"chart" yaml:"chart"`
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+// Reference returns the chart reference to pass to helm
+func (h *HelmChart) Reference() string {
+	if h.Repo == "" || strings.Contains(h.Chart, "/") {
+		return h.Chart
+	}
+	return h.Repo + "/" + h.Chart
+}
+
+// Snap describes how to install the software from the Snap Store
+type Snap struct {
+	Channel string `json:"channel,omitempty" yaml:"channel,omitempty"`
+	// Confinement is classic or devmode for snaps that are not strictly confined
+	Confinement string `json:"confinement,omitempty" yaml:"confinement,omitempty"`
+}
+
+// isSnapConfinement reports whether the confinement is one of the Snap Store,
+// strict being the default
+func isSnapConfinement(confinement string) bool {
+	switch confinement {
+	case "", "strict", "classic", "devmode":
+		return true
+	}
+	return false
+}
+
+// Flatpak describes where to install the software from as a flatpak
+type Flatpak struct {
+	Remote string `json:"remote,omitempty" yaml:"remote,omitempty"`
+	Branch string `json:"branch,omitempty" yaml:"branch,omitempty"`
+}
+
+// Nix describes the flake the software is installed from with Nix
+type Nix struct {
+	// Flake is a flake reference such as github:NixOS/nixpkgs/nixos-24.05
+	Flake string `json:"flake,omitempty" yaml:"flake,omitempty"`
+	// Revision pins nixpkgs to a commit, taking precedence over Flake
+	Revision string `json:"revision,omitempty" yaml:"revision,omitempty"`
+}
+
+// Binary describes how to install the software from a release artifact, a
+// single executable or a tar or zip archive. The URLs are templates where
+// {{.os}}, {{.arch}} and {{.version}} stand for the platform, as named by Go
+// unless renamed by OSNames and ArchNames, and the version to install.
+type Binary struct {
+	URL string `json:"url" yaml:"url"`
+	// Version is installed unless another version is requested
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Checksums maps platforms, such as linux_amd64, to the SHA-256 of the
+	// artifact of Version
+	Checksums map[string]string `json:"checksums,omitempty" yaml:"checksums,omitempty"`
+	// ChecksumsURL is the URL of a file in the sha256sum format listing the
+	// checksums of the artifacts, used for any version
+	ChecksumsURL string     `json:"checksums_url,omitempty" yaml:"checksums_url,omitempty"`
+	Signature    *Signature `json:"signature,omitempty" yaml:"signature,omitempty"`
+	// Files are the paths or patterns of the files installed from an archive,
+	// by default the file named after the software. The first one names a
+	// single executable.
+	Files     []string          `json:"files,omitempty" yaml:"files,omitempty"`
+	OSNames   map[string]string `json:"os_names,omitempty" yaml:"os_names,omitempty"`
+	ArchNames map[string]string `json:"arch_names,omitempty" yaml:"arch_names,omitempty"`
+}
+
+// Signature describes the detached signature of a release artifact, checked
+// with minisign or GPG
+type Signature struct {
+	URL string `json:"url" yaml:"url"`
+	// Minisign is the minisign public key of the signer
+	Minisign string `json:"minisign,omitempty" yaml:"minisign,omitempty"`
+	// GPGKey is the ASCII-armored GPG public key of the signer, or its URL
+	GPGKey string `json:"gpg_key,omitempty" yaml:"gpg_key,omitempty"`
+	// Checksums is set when the checksums file is signed instead of the artifact
+	Checksums bool `json:"checksums,omitempty" yaml:"checksums,omitempty"`
+}
+
+// Source is the recipe building the software from a source tarball. The URLs
+// are templates where {{.version}} stands for the version to build.
+type Source struct {
+	URL string `json:"url" yaml:"url"`
+	// Version is built unless another version is requested
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// SHA256 is the checksum of the tarball of Version
+	SHA256 string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	// ChecksumsURL is the URL of a file in the sha256sum format listing the
+	// checksums of the tarballs, used for any version
+	ChecksumsURL string     `json:"checksums_url,omitempty" yaml:"checksums_url,omitempty"`
+	Signature    *Signature `json:"signature,omitempty" yaml:"signature,omitempty"`
+	// BuildSystem is autotools, cmake, meson or make, detected from the files
+	// of the tarball when empty
+	BuildSystem string `json:"build_system,omitempty" yaml:"build_system,omitempty"`
+	// ConfigureArgs are appended to the configure step of the build system
+	ConfigureArgs []string `json:"configure_args,omitempty" yaml:"configure_args,omitempty"`
+	// Steps replace the commands of the build system. They must install into
+	// {{.destdir}}, from where sai copies the files under the prefix.
+	Steps [][]string `json:"steps,omitempty" yaml:"steps,omitempty"`
+	// BuildDependencies are the packages installed with the package manager
+	// of the platform before building
+	BuildDependencies []Package `json:"build_dependencies,omitempty" yaml:"build_dependencies,omitempty"`
+}
+
+// Dependencies returns the build dependencies for the given provider and
+// distribution
+func (s *Source) Dependencies(provider, distro, family string) []string {
+	var names []string
+	for _, dep := range s.BuildDependencies {
+		if dep.score(provider, distro, family) >= 0 {
+			names = append(names, dep.Name)
+		}
+	}
+	return names
+}
+
+// Container describes the container image of the software and how the
+// docker and podman providers run it
+type Container struct {
+	Image string `json:"image" yaml:"image"`
+	Tag   string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	// Name is the name of the container, defaulting to the name of the software
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Ports are published as host:container, optionally followed by /udp
+	Ports []string `json:"ports,omitempty" yaml:"ports,omitempty"`
+	// Volumes are mounted as volume-or-host-path:container-path[:options]
+	Volumes []string          `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	Env     map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// Reference returns the full image reference
+func (c *Container) Reference() string {
+	if c.Tag == "" {
+		return c.Image
+	}
+	return c.Image + ":" + c.Tag
+}
+
+// Compose describes the compose project of multi-container software. The
+// compose provider uses File when set, and otherwise generates a compose file
+// from Services.
+type Compose struct {
+	// File is the compose file, or the directory holding it
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	// Project is the name of the project, defaulting to the name of the software
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	// EnvFile sets the variables interpolated in the compose file
+	EnvFile  string               `json:"env_file,omitempty" yaml:"env_file,omitempty"`
+	Services map[string]Container `json:"services,omitempty" yaml:"services,omitempty"`
+}
+
+// score returns how specifically the selector matches, or -1 if it does not match
+func (s Selector) score(provider, distro, family string) int {
+	score := 0
+	if s.Provider != "" {
+		if s.Provider != provider {
+			return -1
+		}
+		score += 4
+	}
+	switch s.Distro {
+	case "":
+	case distro:
+		score += 2
+	case family:
+		score++
+	default:
+		return -1
+	}
+	return score
+}
+
+// PackageName returns the package name for the given provider and distribution,
+// falling back to the software name
+func (s *Software) PackageName(provider, distro, family string) string {
+	best, bestScore := s.Name, -1
+	for _, p := range s.Packages {
+		if score := p.score(provider, distro, family); score > bestScore {
+			best, bestScore = p.Name, score
+		}
+	}
+	return best
+}
+
+// ServiceName returns the service name for the given service manager and
+// distribution, falling back to the software name
+func (s *Software) ServiceName(provider, distro, family string) string {
+	best, bestScore := s.Name, -1
+	for _, svc := range s.Services {
+		if score := svc.score(provider, distro, family); score > bestScore {
+			best, bestScore = svc.Name, score
+		}
+	}
+	return best
+}
+
+// InCategory reports whether the software belongs to a category, matching its
+// categories and tags case-insensitively
+func (s *Software) InCategory(category string) bool {
+	for _, c := range append(append([]string{}, s.Categories...), s.Tags...) {
+		if strings.EqualFold(c, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// Literal returns software data for a name that has no saidata
+func Literal(name string) *Software {
+	return &Software{Name: name}
+}
+
+// Catalog is a collection of software data indexed by name and alias
+type Catalog struct {
+	mu       sync.RWMutex
+	software map[string]*Software
+	aliases  map[string]string
+}
+
+// NewCatalog creates an empty catalog
+func NewCatalog() *Catalog {
+	return &Catalog{
+		software: make(map[string]*Software),
+		aliases:  make(map[string]string),
+	}
+}
+
+// Add adds software to the catalog, replacing any entry with the same name
+func (c *Catalog) Add(sw Software) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sw.Known = true
+	c.software[sw.Name] = &sw
+	for _, alias := range sw.Aliases {
+		c.aliases[alias] = sw.Name
+	}
+}
+
+// Get returns the software with the given name or alias
+func (c *Catalog) Get(name string) (*Software, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if canonical, ok := c.aliases[name]; ok {
+		if _, exists := c.software[name]; !exists {
+			name = canonical
+		}
+	}
+	if sw, ok := c.software[name]; ok {
+		copied := *sw
+		return &copied, nil
+	}
+	return nil, &errs.SoftwareNotFoundError{Software: name}
+}
+
+// All returns all software in the catalog sorted by name
+func (c *Catalog) All() []Software {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	all := make([]Software, 0, len(c.software))
+	for _, sw := range c.software {
+		all = append(all, *sw)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// LoadFile loads software data from a YAML or JSON file. A file may contain a
+// single software entry or a list of entries.
+func (c *Catalog) LoadFile(path string) error {
+	content, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return c.load(path, content)
+}
+
+// LoadDir loads every YAML and JSON file in a directory
+func (c *Catalog) LoadDir(dir string) error {
+	return c.loadFS(os.DirFS(dir), dir)
+}
 
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&softwareData)
+// loadFS loads every YAML and JSON file at the root of a filesystem
+func (c *Catalog) loadFS(fsys fs.FS, label string) error {
+	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
 		return err
 	}
+	for _, entry := range entries {
+		if entry.IsDir() || !isDataFile(entry.Name()) {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return err
+		}
+		if err := c.load(filepath.Join(label, entry.Name()), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load decodes the content of a data file and adds it to the catalog
+func (c *Catalog) load(path string, content []byte) error {
+	var list []Software
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		trimmed := bytes.TrimSpace(content)
+		if len(trimmed) > 0 && trimmed[0] == '[' {
+			if err := json.Unmarshal(trimmed, &list); err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+		} else {
+			var sw Software
+			if err := json.Unmarshal(trimmed, &sw); err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+			list = append(list, sw)
+		}
+	} else {
+		var node yaml.Node
+		if err := yaml.Unmarshal(content, &node); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+			if err := node.Decode(&list); err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+		} else if len(node.Content) > 0 {
+			var sw Software
+			if err := node.Decode(&sw); err != nil {
+				return fmt.Errorf("parsing %s: %w", path, err)
+			}
+			list = append(list, sw)
+		}
+	}
+
+	for _, sw := range list {
+		if sw.Name == "" {
+			return fmt.Errorf("parsing %s: software entry without a name", path)
+		}
+		if sw.Snap != nil && !isSnapConfinement(sw.Snap.Confinement) {
+			return fmt.Errorf("parsing %s: %s: unsupported snap confinement %q (supported: strict, classic, devmode)",
+				path, sw.Name, sw.Snap.Confinement)
+		}
+		c.Add(sw)
+	}
 	return nil
 }
 
+// isDataFile reports whether the file name has a saidata extension
+func isDataFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+//go:embed saidata/*.yaml
+var builtinData embed.FS
+
+// SearchPath returns the directories searched for saidata, in load order.
+// Later directories override entries from earlier ones.
+func SearchPath() []string {
+	dirs := []string{"/etc/sai/saidata"}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "sai", "saidata"))
+	}
+	if env := os.Getenv("SAI_DATA_PATH"); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	return dirs
+}
+
+// Global catalog
+var (
+	catalog     = NewCatalog()
+	catalogOnce sync.Once
+	catalogErr  error
+)
+
+// loadCatalog loads the built-in saidata and the search path into the global catalog
+func loadCatalog() error {
+	catalogOnce.Do(func() {
+		builtin, err := fs.Sub(builtinData, "saidata")
+		if err == nil {
+			err = catalog.loadFS(builtin, "builtin")
+		}
+		if err != nil {
+			catalogErr = err
+			return
+		}
+		for _, dir := range SearchPath() {
+			if _, statErr := os.Stat(dir); statErr != nil {
+				continue
+			}
+			if err := catalog.LoadDir(dir); err != nil {
+				catalogErr = err
+				return
+			}
+		}
+	})
+	return catalogErr
+}
+
+// LoadData loads additional software data from a file into the global catalog
+func LoadData(filePath string) error {
+	if err := loadCatalog(); err != nil {
+		return err
+	}
+	return catalog.LoadFile(filePath)
+}
+
+// GetSoftware returns the software with the given name from the global catalog
 func GetSoftware(name string) (*Software, error) {
-	for _, s := range softwareData {
-		if s.Name == name {
-			return &s, nil
+	if err := loadCatalog(); err != nil {
+		return nil, err
+	}
+	return catalog.Get(name)
+}
+
+// AllSoftware returns all software in the global catalog
+func AllSoftware() ([]Software, error) {
+	if err := loadCatalog(); err != nil {
+		return nil, err
+	}
+	return catalog.All(), nil
+}
+
+// SoftwareInCategory returns the software of the global catalog in a category
+func SoftwareInCategory(category string) ([]Software, error) {
+	all, err := AllSoftware()
+	if err != nil {
+		return nil, err
+	}
+	var matches []Software
+	for _, sw := range all {
+		if sw.InCategory(category) {
+			matches = append(matches, sw)
 		}
 	}
-	return nil, errors.New("software not found")
+	return matches, nil
+}
+
+// Resolve returns the software data for a name. Unknown software falls back to
+// the literal name; its Known field is false.
+func Resolve(name string) (*Software, error) {
+	sw, err := GetSoftware(name)
+	if err == nil {
+		return sw, nil
+	}
+	var notFound *errs.SoftwareNotFoundError
+	if errors.As(err, &notFound) {
+		return Literal(name), nil
+	}
+	return nil, err
+}
+
+type softwareKey struct{}
+
+// WithSoftware returns a context carrying the resolved software data
+func WithSoftware(ctx context.Context, sw *Software) context.Context {
+	return context.WithValue(ctx, softwareKey{}, sw)
+}
+
+// FromContext returns the software data attached to the context, or nil
+func FromContext(ctx context.Context) *Software {
+	sw, _ := ctx.Value(softwareKey{}).(*Software)
+	return sw
 }
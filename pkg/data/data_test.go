@@ -0,0 +1,162 @@
+package data
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sai/pkg/errs"
+)
+
+// TestBuiltinCatalog tests that the embedded saidata loads and resolves
+func TestBuiltinCatalog(t *testing.T) {
+	testCases := []struct {
+		software        string
+		provider        string
+		distro          string
+		family          string
+		expectedPackage string
+		expectedService string
+	}{
+		{"apache", "apt", "ubuntu", "debian", "apache2", "apache2"},
+		{"apache", "rpm", "fedora", "redhat", "httpd", "httpd"},
+		{"httpd", "zypper", "opensuse-leap", "suse", "apache2", "apache2"},
+		{"apache", "brew", "", "", "httpd", "httpd"},
+		{"redis", "apt", "debian", "debian", "redis-server", "redis-server"},
+		{"redis", "pacman", "arch", "arch", "redis", "redis"},
+		{"nginx", "apt", "debian", "debian", "nginx", "nginx"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.software+"_"+tc.provider, func(t *testing.T) {
+			sw, err := GetSoftware(tc.software)
+			if err != nil {
+				t.Fatalf("Expected %s in the builtin catalog: %v", tc.software, err)
+			}
+			if !sw.Known {
+				t.Errorf("Expected catalog software to be known")
+			}
+			if name := sw.PackageName(tc.provider, tc.distro, tc.family); name != tc.expectedPackage {
+				t.Errorf("Expected package %s, got %s", tc.expectedPackage, name)
+			}
+			if name := sw.ServiceName("systemd", tc.distro, tc.family); name != tc.expectedService {
+				t.Errorf("Expected service %s, got %s", tc.expectedService, name)
+			}
+		})
+	}
+}
+
+// TestResolveUnknownSoftware tests the literal fallback for unknown software
+func TestResolveUnknownSoftware(t *testing.T) {
+	sw, err := Resolve("definitely-not-in-saidata")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sw.Known || sw.Name != "definitely-not-in-saidata" {
+		t.Errorf("Expected literal software, got %+v", sw)
+	}
+	if sw.PackageName("apt", "debian", "debian") != "definitely-not-in-saidata" {
+		t.Errorf("Expected literal package name")
+	}
+
+	_, err = GetSoftware("definitely-not-in-saidata")
+	var notFound *errs.SoftwareNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("Expected SoftwareNotFoundError, got %v", err)
+	}
+}
+
+// TestSoftwareInCategory tests selecting software by category or tag
+func TestSoftwareInCategory(t *testing.T) {
+	testCases := []struct {
+		category string
+		expected []string
+	}{
+		{"database", []string{"mysql", "postgresql", "prometheus", "redis"}},
+		{"SQL", []string{"mysql", "postgresql"}},
+		{"no-such-category", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.category, func(t *testing.T) {
+			matches, err := SoftwareInCategory(tc.category)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, sw := range matches {
+				names = append(names, sw.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+// TestLoadFiles tests loading YAML and JSON files, single entries and lists
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"single.yaml": "name: tomcat\ncategories: [appserver]\npackages:\n  - name: tomcat9\n    provider: apt\n",
+		"list.yml":    "- name: haproxy\n- name: memcached\n  ports:\n    - port: 11211\n",
+		"single.json": `{"name": "consul", "packages": [{"name": "consul-bin", "distro": "arch"}]}`,
+		"list.json":   `[{"name": "vault", "config_file": "/etc/vault.hcl"}]`,
+		"ignored.txt": "name: ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	catalog := NewCatalog()
+	if err := catalog.LoadDir(dir); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if all := catalog.All(); len(all) != 5 {
+		t.Fatalf("Expected 5 entries, got %d", len(all))
+	}
+
+	tomcat, _ := catalog.Get("tomcat")
+	if tomcat.PackageName("apt", "ubuntu", "debian") != "tomcat9" || tomcat.Categories[0] != "appserver" {
+		t.Errorf("Unexpected tomcat data: %+v", tomcat)
+	}
+	memcached, _ := catalog.Get("memcached")
+	if len(memcached.Ports) != 1 || memcached.Ports[0].Port != 11211 {
+		t.Errorf("Unexpected memcached data: %+v", memcached)
+	}
+	consul, _ := catalog.Get("consul")
+	if consul.PackageName("pacman", "manjaro", "arch") != "consul-bin" {
+		t.Errorf("Unexpected consul data: %+v", consul)
+	}
+	vault, _ := catalog.Get("vault")
+	if vault.ConfigFile != "/etc/vault.hcl" {
+		t.Errorf("Unexpected vault data: %+v", vault)
+	}
+}
+
+// TestLoadInvalidFile tests that entries without a name are rejected
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(path, []byte("description: no name\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewCatalog().LoadFile(path); err == nil {
+		t.Errorf("Expected an error for an entry without a name")
+	}
+}
+
+// TestLoadInvalidConfinement tests that unknown snap confinements are rejected
+func TestLoadInvalidConfinement(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(path, []byte("name: hello\nsnap:\n  confinement: loose\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewCatalog().LoadFile(path); err == nil || !strings.Contains(err.Error(), "unsupported snap confinement") {
+		t.Errorf("Expected an error for an unknown confinement, got %v", err)
+	}
+}
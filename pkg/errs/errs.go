@@ -0,0 +1,154 @@
+// Package errs defines the typed errors returned by sai and the process exit
+// codes they map to.
+//
+// Exit codes, stable for scripting:
+//
+//	0  success
+//	1  generic error
+//	2  usage error (missing arguments, unknown command or flag, unconfirmed
+//	   destructive action in a non-interactive session)
+//	3  the action, or the requested version, is not supported by the selected provider
+//	4  the provider is unknown or not available on this system
+//	5  the software could not be found
+//	6  an executed command failed (its own exit code is reported in the message)
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Process exit codes
+const (
+	ExitOK                  = 0
+	ExitError               = 1
+	ExitUsage               = 2
+	ExitUnsupportedAction   = 3
+	ExitProviderUnavailable = 4
+	ExitSoftwareNotFound    = 5
+	ExitCommandFailed       = 6
+)
+
+// UsageError reports invalid command line usage
+type UsageError struct {
+	Message string
+}
+
+func (e *UsageError) Error() string {
+	return e.Message
+}
+
+// Usagef creates a new usage error
+func Usagef(format string, args ...interface{}) error {
+	return &UsageError{Message: fmt.Sprintf(format, args...)}
+}
+
+// UnsupportedActionError reports an action a provider cannot perform
+type UnsupportedActionError struct {
+	Action   string
+	Provider string
+	// Resource is set when only a specific resource type is unsupported
+	Resource string
+}
+
+func (e *UnsupportedActionError) Error() string {
+	msg := fmt.Sprintf("action '%s' is not supported by provider '%s'", e.Action, e.Provider)
+	if e.Resource != "" {
+		msg += fmt.Sprintf(" for resource type '%s'", e.Resource)
+	}
+	return msg
+}
+
+// VersionPinningError reports a provider that cannot install a specific version
+type VersionPinningError struct {
+	Provider string
+	Version  string
+}
+
+func (e *VersionPinningError) Error() string {
+	return fmt.Sprintf("provider '%s' cannot install a specific version (requested %s)", e.Provider, e.Version)
+}
+
+// ConfirmationError reports an action that was not confirmed by the user
+type ConfirmationError struct {
+	Action   string
+	Software string
+	// Declined is set when the user answered no; otherwise the session could
+	// not prompt and --yes was not given
+	Declined bool
+}
+
+func (e *ConfirmationError) Error() string {
+	if e.Declined {
+		return fmt.Sprintf("%s %s cancelled", e.Action, e.Software)
+	}
+	return fmt.Sprintf("refusing to %s %s without confirmation in a non-interactive session; use --yes to proceed", e.Action, e.Software)
+}
+
+// ProviderUnavailableError reports a provider that is unknown or cannot be used
+type ProviderUnavailableError struct {
+	Provider string
+	Reason   string
+}
+
+func (e *ProviderUnavailableError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("provider '%s' is not available", e.Provider)
+	}
+	return fmt.Sprintf("provider '%s' is not available: %s", e.Provider, e.Reason)
+}
+
+// SoftwareNotFoundError reports software that could not be found
+type SoftwareNotFoundError struct {
+	Software string
+	Provider string
+}
+
+func (e *SoftwareNotFoundError) Error() string {
+	if e.Provider == "" {
+		return fmt.Sprintf("software '%s' not found", e.Software)
+	}
+	return fmt.Sprintf("software '%s' not found by provider '%s'", e.Software, e.Provider)
+}
+
+// CommandFailedError reports a command that ran but exited with a non-zero status
+type CommandFailedError struct {
+	Command  string
+	ExitCode int
+}
+
+func (e *CommandFailedError) Error() string {
+	return fmt.Sprintf("command %q failed with exit code %d", e.Command, e.ExitCode)
+}
+
+// ExitCode returns the process exit code for the given error
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var usageErr *UsageError
+	var confirmErr *ConfirmationError
+	var unsupportedErr *UnsupportedActionError
+	var pinningErr *VersionPinningError
+	var unavailableErr *ProviderUnavailableError
+	var notFoundErr *SoftwareNotFoundError
+	var commandErr *CommandFailedError
+
+	switch {
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.As(err, &confirmErr) && !confirmErr.Declined:
+		return ExitUsage
+	case errors.As(err, &unsupportedErr), errors.As(err, &pinningErr):
+		return ExitUnsupportedAction
+	case errors.As(err, &unavailableErr):
+		return ExitProviderUnavailable
+	case errors.As(err, &notFoundErr):
+		return ExitSoftwareNotFound
+	case errors.As(err, &commandErr):
+		return ExitCommandFailed
+	default:
+		return ExitError
+	}
+}
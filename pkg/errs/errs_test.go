@@ -0,0 +1,36 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestExitCode tests the mapping of typed errors to exit codes
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"Nil", nil, ExitOK},
+		{"Generic", errors.New("boom"), ExitError},
+		{"Usage", Usagef("requires at least %d args", 2), ExitUsage},
+		{"Unconfirmed", &ConfirmationError{Action: "uninstall", Software: "nginx"}, ExitUsage},
+		{"Declined", &ConfirmationError{Action: "uninstall", Software: "nginx", Declined: true}, ExitError},
+		{"Unsupported Action", &UnsupportedActionError{Action: "build", Provider: "apt"}, ExitUnsupportedAction},
+		{"Version Pinning", &VersionPinningError{Provider: "pacman", Version: "1.24.0"}, ExitUnsupportedAction},
+		{"Provider Unavailable", &ProviderUnavailableError{Provider: "brew"}, ExitProviderUnavailable},
+		{"Software Not Found", &SoftwareNotFoundError{Software: "nginx"}, ExitSoftwareNotFound},
+		{"Command Failed", &CommandFailedError{Command: "apt-get install -y nginx", ExitCode: 100}, ExitCommandFailed},
+		{"Wrapped", fmt.Errorf("install nginx: %w", &CommandFailedError{ExitCode: 1}), ExitCommandFailed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := ExitCode(tc.err); code != tc.expected {
+				t.Errorf("Expected exit code %d, got %d", tc.expected, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package manifest reads declarative sai manifests and computes the actions
+// needed to bring software to its desired state.
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+)
+
+// Desired package states
+const (
+	StateInstalled = "installed"
+	StateAbsent    = "absent"
+)
+
+// Desired service states
+const (
+	ServiceRunning = "running"
+	ServiceStopped = "stopped"
+)
+
+// Manifest lists software entries and their desired state
+type Manifest struct {
+	Software []Entry `json:"software" yaml:"software"`
+}
+
+// Entry is the desired state of a piece of software
+type Entry struct {
+	Name string `json:"name" yaml:"name"`
+	// State is either installed (the default) or absent
+	State string `json:"state,omitempty" yaml:"state,omitempty"`
+	// Version, when set, is the version that must be installed
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Provider overrides the automatically selected provider
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	// Service is either running or stopped; empty leaves the service alone
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+	// Enabled, when set, controls whether the service starts at boot
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// ManagesService reports whether the entry sets a desired service state
+func (e Entry) ManagesService() bool {
+	return e.Service != "" || e.Enabled != nil
+}
+
+// Load reads a manifest from a YAML or JSON file
+func Load(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Parse decodes and validates a manifest. JSON is accepted as a subset of YAML.
+func Parse(content []byte) (*Manifest, error) {
+	var m Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil {
+		return nil, errs.Usagef("invalid manifest: %v", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks the entries of the manifest and fills in defaults
+func (m *Manifest) Validate() error {
+	seen := make(map[string]bool)
+	for i := range m.Software {
+		e := &m.Software[i]
+		if e.Name == "" {
+			return errs.Usagef("invalid manifest: entry %d has no name", i+1)
+		}
+		if seen[e.Name] {
+			return errs.Usagef("invalid manifest: %s is listed more than once", e.Name)
+		}
+		seen[e.Name] = true
+
+		if e.State == "" {
+			e.State = StateInstalled
+		}
+		switch e.State {
+		case StateInstalled, StateAbsent:
+		default:
+			return errs.Usagef("invalid manifest: %s has state %q (expected installed or absent)", e.Name, e.State)
+		}
+		switch e.Service {
+		case "", ServiceRunning, ServiceStopped:
+		default:
+			return errs.Usagef("invalid manifest: %s has service %q (expected running or stopped)", e.Name, e.Service)
+		}
+		if e.State == StateAbsent && (e.Service == ServiceRunning || (e.Enabled != nil && *e.Enabled)) {
+			return errs.Usagef("invalid manifest: %s cannot be absent with a running or enabled service", e.Name)
+		}
+	}
+	return nil
+}
+
+// State is the observed state of a piece of software
+type State struct {
+	Installed bool   `json:"installed" yaml:"installed"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Running   bool   `json:"running" yaml:"running"`
+	Enabled   bool   `json:"enabled" yaml:"enabled"`
+}
+
+// Step is an action needed to reach the desired state
+type Step struct {
+	Software string `json:"software" yaml:"software"`
+	Action   string `json:"action" yaml:"action"`
+	Provider string `json:"provider,omitempty" yaml:"provider,omitempty"`
+	Reason   string `json:"reason" yaml:"reason"`
+	// Version is the version the install and upgrade steps must install
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Commands are the commands the action resolves to, filled in dry run mode
+	Commands []output.Step `json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// IsService reports whether the step acts on the service rather than the package
+func (s Step) IsService() bool {
+	switch s.Action {
+	case "start", "stop", "restart", "enable", "disable":
+		return true
+	}
+	return false
+}
+
+// Plan returns the actions, in execution order, that bring the software from
+// the current state to the desired one. No steps means nothing needs to change.
+func (e Entry) Plan(current State) []Step {
+	var steps []Step
+	add := func(action, reason string) {
+		step := Step{Software: e.Name, Action: action, Reason: reason}
+		if action == "install" || action == "upgrade" {
+			step.Version = e.Version
+		}
+		steps = append(steps, step)
+	}
+
+	if e.State == StateAbsent {
+		if current.Running {
+			add("stop", "service is running")
+		}
+		if current.Enabled && e.Enabled != nil {
+			add("disable", "service is enabled")
+		}
+		if current.Installed {
+			add("uninstall", "software is installed")
+		}
+		return steps
+	}
+
+	switch {
+	case !current.Installed:
+		add("install", "software is not installed")
+		// A fresh installation has no running or enabled service yet
+		current = State{Installed: true}
+	case e.Version != "" && !VersionMatches(current.Version, e.Version):
+		add("upgrade", fmt.Sprintf("version %s is installed, %s is wanted", current.Version, e.Version))
+	}
+
+	if e.Enabled != nil && *e.Enabled != current.Enabled {
+		if *e.Enabled {
+			add("enable", "service is disabled")
+		} else {
+			add("disable", "service is enabled")
+		}
+	}
+	switch {
+	case e.Service == ServiceRunning && !current.Running:
+		add("start", "service is stopped")
+	case e.Service == ServiceStopped && current.Running:
+		add("stop", "service is running")
+	}
+	return steps
+}
+
+// VersionMatches reports whether an installed version satisfies the wanted
+// one. A wanted version matches the installed version exactly or as a prefix
+// of its components, ignoring any epoch: 1.24 matches 1.24.0 and 1:1.24.0-1.
+func VersionMatches(installed, wanted string) bool {
+	if _, rest, ok := strings.Cut(installed, ":"); ok {
+		installed = rest
+	}
+	if installed == wanted {
+		return true
+	}
+	if !strings.HasPrefix(installed, wanted) {
+		return false
+	}
+	next := installed[len(wanted)]
+	return next == '.' || next == '-' || next == '+' || next == '_'
+}
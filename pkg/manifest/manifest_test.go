@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"reflect"
+	"testing"
+
+	"sai/pkg/errs"
+)
+
+// TestParse tests manifest decoding and validation
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		valid   bool
+	}{
+		{"YAML", "software:\n  - name: nginx\n    service: running\n    enabled: true\n", true},
+		{"JSON", `{"software": [{"name": "nginx", "state": "absent"}]}`, true},
+		{"Missing Name", "software:\n  - state: installed\n", false},
+		{"Duplicate", "software:\n  - name: nginx\n  - name: nginx\n", false},
+		{"Invalid State", "software:\n  - name: nginx\n    state: present\n", false},
+		{"Invalid Service", "software:\n  - name: nginx\n    service: up\n", false},
+		{"Absent And Running", "software:\n  - name: nginx\n    state: absent\n    service: running\n", false},
+		{"Unknown Field", "software:\n  - name: nginx\n    packages: [nginx]\n", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := Parse([]byte(tc.content))
+			if tc.valid {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if m.Software[0].State == "" {
+					t.Errorf("Expected the default state to be filled in")
+				}
+			} else if errs.ExitCode(err) != errs.ExitUsage {
+				t.Errorf("Expected usage error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestPlan tests the actions computed from the current and desired states
+func TestPlan(t *testing.T) {
+	yes, no := true, false
+
+	testCases := []struct {
+		name     string
+		entry    Entry
+		current  State
+		expected []string
+	}{
+		{"Install", Entry{Name: "nginx", State: StateInstalled}, State{}, []string{"install"}},
+		{"Already Installed", Entry{Name: "nginx", State: StateInstalled}, State{Installed: true}, nil},
+		{"Install And Start", Entry{Name: "nginx", State: StateInstalled, Service: ServiceRunning, Enabled: &yes},
+			State{}, []string{"install", "enable", "start"}},
+		{"Start Only", Entry{Name: "nginx", State: StateInstalled, Service: ServiceRunning, Enabled: &yes},
+			State{Installed: true, Enabled: true}, []string{"start"}},
+		{"Stop And Disable", Entry{Name: "nginx", State: StateInstalled, Service: ServiceStopped, Enabled: &no},
+			State{Installed: true, Running: true, Enabled: true}, []string{"disable", "stop"}},
+		{"Version Mismatch", Entry{Name: "nginx", State: StateInstalled, Version: "1.24"},
+			State{Installed: true, Version: "1.22.1-9"}, []string{"upgrade"}},
+		{"Version Match", Entry{Name: "nginx", State: StateInstalled, Version: "1.24"},
+			State{Installed: true, Version: "1.24.0-1"}, nil},
+		{"Remove", Entry{Name: "nginx", State: StateAbsent}, State{Installed: true, Running: true}, []string{"stop", "uninstall"}},
+		{"Already Absent", Entry{Name: "nginx", State: StateAbsent}, State{}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actions []string
+			for _, step := range tc.entry.Plan(tc.current) {
+				actions = append(actions, step.Action)
+				if (step.Action == "install" || step.Action == "upgrade") && step.Version != tc.entry.Version {
+					t.Errorf("Expected %s to pin version %q, got %q", step.Action, tc.entry.Version, step.Version)
+				}
+			}
+			if !reflect.DeepEqual(actions, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, actions)
+			}
+		})
+	}
+}
+
+// TestVersionMatches tests version comparison against installed versions
+func TestVersionMatches(t *testing.T) {
+	testCases := []struct {
+		installed string
+		wanted    string
+		expected  bool
+	}{
+		{"1.24.0", "1.24.0", true},
+		{"1.24.0", "1.24", true},
+		{"1:2.39.5-0+deb12u2", "2.39.5", true},
+		{"1.240.0", "1.24", false},
+		{"1.22.1", "1.24", false},
+		{"", "1.24", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.installed+"_"+tc.wanted, func(t *testing.T) {
+			if got := VersionMatches(tc.installed, tc.wanted); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
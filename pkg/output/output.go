@@ -0,0 +1,363 @@
+// Package output defines the structured result of a sai action and renders it
+// as a human-readable table or as JSON/YAML for machines.
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+)
+
+// Format is an output format
+type Format string
+
+// Supported output formats
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+// Formats contains all supported output formats
+var Formats = []Format{FormatTable, FormatJSON, FormatYAML}
+
+// ParseFormat validates an output format name. The empty string selects the table format.
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return FormatTable, nil
+	}
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return "", errs.Usagef("unsupported output format %q (supported: table, json, yaml)", name)
+}
+
+// IsMachine reports whether the format is meant to be parsed by programs
+func (f Format) IsMachine() bool {
+	return f == FormatJSON || f == FormatYAML
+}
+
+// Command is a command executed while performing an action
+type Command struct {
+	Command    string   `json:"command" yaml:"command"`
+	Argv       []string `json:"argv" yaml:"argv"`
+	ExitCode   int      `json:"exit_code" yaml:"exit_code"`
+	Stdout     string   `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+	Stderr     string   `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+	DurationMS int64    `json:"duration_ms" yaml:"duration_ms"`
+}
+
+// Step is a command of the execution plan built in dry run mode
+type Step struct {
+	Command string   `json:"command" yaml:"command"`
+	Argv    []string `json:"argv,omitempty" yaml:"argv,omitempty"`
+	Env     []string `json:"env,omitempty" yaml:"env,omitempty"`
+	// NeedsRoot is set when the command requires administrator privileges
+	NeedsRoot bool `json:"needs_root" yaml:"needs_root"`
+	// Destructive is set when the command removes software or resources
+	Destructive bool `json:"destructive" yaml:"destructive"`
+}
+
+// NewStep creates the plan step running a command
+func NewStep(cmd runner.Command, needsRoot, destructive bool) Step {
+	return Step{
+		Command:     cmd.String(),
+		Argv:        cmd.Argv(),
+		Env:         cmd.Env,
+		NeedsRoot:   needsRoot,
+		Destructive: destructive,
+	}
+}
+
+// String returns the command line of the step, prefixed by its environment
+func (s Step) String() string {
+	if len(s.Env) == 0 {
+		return s.Command
+	}
+	return strings.Join(s.Env, " ") + " " + s.Command
+}
+
+// Result is the structured outcome of an action on a piece of software
+type Result struct {
+	Software     string            `json:"software" yaml:"software"`
+	Action       string            `json:"action" yaml:"action"`
+	Provider     string            `json:"provider,omitempty" yaml:"provider,omitempty"`
+	ProviderType string            `json:"provider_type,omitempty" yaml:"provider_type,omitempty"`
+	Target       string            `json:"target,omitempty" yaml:"target,omitempty"`
+	DryRun       bool              `json:"dry_run" yaml:"dry_run"`
+	Success      bool              `json:"success" yaml:"success"`
+	ExitCode     int               `json:"exit_code" yaml:"exit_code"`
+	Error        string            `json:"error,omitempty" yaml:"error,omitempty"`
+	Commands     []Command         `json:"commands" yaml:"commands"`
+	Plan         []Step            `json:"plan,omitempty" yaml:"plan,omitempty"`
+	Data         map[string]string `json:"data,omitempty" yaml:"data,omitempty"`
+
+	mu sync.Mutex
+}
+
+// NewResult creates the result of an action
+func NewResult(software, action string) *Result {
+	return &Result{Software: software, Action: action, Commands: []Command{}}
+}
+
+// SetProvider records the provider performing the action and the name of the
+// package or service it acts on. It is a no-op on a nil result.
+func (r *Result) SetProvider(provider, providerType, target string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Provider, r.ProviderType, r.Target = provider, providerType, target
+}
+
+// Set records a piece of data parsed from the output of the action, such as
+// the installed version. It is a no-op on a nil result.
+func (r *Result) Set(key, value string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Data == nil {
+		r.Data = make(map[string]string)
+	}
+	r.Data[key] = value
+}
+
+// Get returns a piece of data recorded with Set
+func (r *Result) Get(key string) string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Data[key]
+}
+
+// AddCommand records an executed command. It is a no-op on a nil result.
+func (r *Result) AddCommand(result *runner.Result) {
+	if r == nil || result == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Commands = append(r.Commands, Command{
+		Command:    result.Command.String(),
+		Argv:       result.Command.Argv(),
+		ExitCode:   result.ExitCode,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		DurationMS: result.Duration.Milliseconds(),
+	})
+}
+
+// AddStep adds a step to the execution plan. It is a no-op on a nil result.
+func (r *Result) AddStep(step Step) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Plan = append(r.Plan, step)
+}
+
+// Identity returns what the result is about: the software, the action, the
+// provider and whether it is a dry run
+func (r *Result) Identity() (software, action, provider string, dryRun bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.Software, r.Action, r.Provider, r.DryRun
+}
+
+// CommandLines returns the executed commands as shell-like strings
+func (r *Result) CommandLines() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := make([]string, len(r.Commands))
+	for i, c := range r.Commands {
+		lines[i] = c.Command
+	}
+	return lines
+}
+
+// Finish records the outcome of the action
+func (r *Result) Finish(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Success = err == nil
+	r.ExitCode = errs.ExitCode(err)
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// Status returns a short human-readable status
+func (r *Result) Status() string {
+	switch {
+	case !r.Success:
+		return fmt.Sprintf("failed (exit %d)", r.ExitCode)
+	case r.DryRun:
+		return "dry-run"
+	default:
+		return "ok"
+	}
+}
+
+type resultKey struct{}
+
+// WithResult returns a context carrying the result being built
+func WithResult(ctx context.Context, r *Result) context.Context {
+	return context.WithValue(ctx, resultKey{}, r)
+}
+
+// FromContext returns the result attached to the context, or nil
+func FromContext(ctx context.Context) *Result {
+	r, _ := ctx.Value(resultKey{}).(*Result)
+	return r
+}
+
+// trackingRunner records every command it runs into a result
+type trackingRunner struct {
+	runner runner.Runner
+	result *Result
+}
+
+// Run runs the command with the wrapped runner and records its outcome
+func (t *trackingRunner) Run(ctx context.Context, cmd runner.Command) (*runner.Result, error) {
+	started := time.Now()
+	result, err := t.runner.Run(ctx, cmd)
+	if result == nil && err != nil {
+		// The command could not start; record it with the conventional -1 exit code
+		result = &runner.Result{Command: cmd, ExitCode: -1, Started: started, Stderr: err.Error()}
+	}
+	t.result.AddCommand(result)
+	return result, err
+}
+
+// LookPath delegates to the wrapped runner
+func (t *trackingRunner) LookPath(file string) (string, error) {
+	return runner.LookPath(runner.WithRunner(context.Background(), t.runner), file)
+}
+
+// Track returns a context carrying the result and a runner recording every
+// command executed through it into the result
+func Track(ctx context.Context, r *Result) context.Context {
+	tracked := &trackingRunner{runner: runner.FromContext(ctx), result: r}
+	return runner.WithRunner(WithResult(ctx, r), tracked)
+}
+
+// Render writes the results in the given format. JSON and YAML render a single
+// result as an object and several results as a list.
+func Render(w io.Writer, format Format, results ...*Result) error {
+	if !format.IsMachine() {
+		if err := renderTable(w, results); err != nil {
+			return err
+		}
+		return RenderPlan(w, results...)
+	}
+	if len(results) == 1 {
+		return Encode(w, format, results[0])
+	}
+	return Encode(w, format, results)
+}
+
+// Encode writes any value as JSON or YAML
+func Encode(w io.Writer, format Format, v any) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	return errors.New("not a machine-readable output format: " + string(format))
+}
+
+// renderTable writes a summary table of the results
+func renderTable(w io.Writer, results []*Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOFTWARE\tACTION\tPROVIDER\tTARGET\tSTATUS\tDETAILS")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Software, r.Action, Dash(r.Provider), Dash(r.Target), r.Status(), Dash(r.details()))
+	}
+	return tw.Flush()
+}
+
+// RenderPlan writes the execution plan of dry runs, if any, as a table
+func RenderPlan(w io.Writer, results ...*Result) error {
+	var steps int
+	for _, r := range results {
+		steps += len(r.Plan)
+	}
+	if steps == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tSOFTWARE\tROOT\tDESTRUCTIVE\tCOMMAND")
+	step := 0
+	for _, r := range results {
+		for _, s := range r.Plan {
+			step++
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", step, r.Software, yesNo(s.NeedsRoot), yesNo(s.Destructive), s)
+		}
+	}
+	return tw.Flush()
+}
+
+// yesNo renders a flag in a table
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// details returns the parsed data as sorted key=value pairs
+func (r *Result) details() string {
+	keys := make([]string, 0, len(r.Data))
+	for k := range r.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + r.Data[k]
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Dash replaces empty table cells with a dash
+func Dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"sai/pkg/errs"
+	"sai/pkg/runner"
+)
+
+// TestParseFormat tests output format validation
+func TestParseFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected Format
+		valid    bool
+	}{
+		{"", FormatTable, true},
+		{"table", FormatTable, true},
+		{"JSON", FormatJSON, true},
+		{"yaml", FormatYAML, true},
+		{"xml", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			format, err := ParseFormat(tc.name)
+			if tc.valid && (err != nil || format != tc.expected) {
+				t.Errorf("Expected format %s, got %s (error: %v)", tc.expected, format, err)
+			}
+			if !tc.valid && errs.ExitCode(err) != errs.ExitUsage {
+				t.Errorf("Expected usage error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestTrackRecordsCommands tests that commands run through a tracked context are recorded
+func TestTrackRecordsCommands(t *testing.T) {
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		return &runner.Result{Command: cmd, ExitCode: 1, Stderr: "boom"},
+			&errs.CommandFailedError{Command: cmd.String(), ExitCode: 1}
+	}
+
+	result := NewResult("nginx", "install")
+	ctx := Track(runner.WithRunner(context.Background(), recorder), result)
+	_, err := runner.Run(ctx, runner.NewCommand("apt-get", "install", "-y", "nginx"))
+	FromContext(ctx).SetProvider("apt", "os", "nginx")
+	result.Finish(err)
+
+	if len(recorder.Commands()) != 1 {
+		t.Fatalf("Expected the wrapped runner to run the command")
+	}
+	if len(result.Commands) != 1 || result.Commands[0].Command != "apt-get install -y nginx" || result.Commands[0].Stderr != "boom" {
+		t.Errorf("Expected recorded command, got: %+v", result.Commands)
+	}
+	if result.Success || result.ExitCode != errs.ExitCommandFailed || result.Provider != "apt" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if _, err := runner.LookPath(ctx, "apt-get"); err != nil {
+		t.Errorf("Expected LookPath to be delegated, got: %v", err)
+	}
+}
+
+// TestRender tests rendering a result in every format
+func TestRender(t *testing.T) {
+	result := NewResult("nginx", "status")
+	result.SetProvider("apt", "os", "nginx")
+	result.Set("version", "1.24.0")
+	result.Finish(nil)
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatJSON, result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+		}
+		if decoded["provider"] != "apt" || decoded["success"] != true {
+			t.Errorf("Unexpected JSON document: %s", buf.String())
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatYAML, result, result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var decoded []map[string]any
+		if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Invalid YAML: %v\n%s", err, buf.String())
+		}
+		if len(decoded) != 2 || decoded[0]["software"] != "nginx" {
+			t.Errorf("Expected a list of two results, got: %s", buf.String())
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatTable, result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 || !strings.HasPrefix(lines[0], "SOFTWARE") || !strings.Contains(lines[1], "version=1.24.0") {
+			t.Errorf("Unexpected table: %s", buf.String())
+		}
+	})
+}
+
+// TestRenderPlan tests rendering the execution plan of a dry run
+func TestRenderPlan(t *testing.T) {
+	result := NewResult("nginx", "uninstall")
+	result.DryRun = true
+	cmd := runner.NewCommand("apt-get", "remove", "-y", "nginx")
+	cmd.Env = []string{"DEBIAN_FRONTEND=noninteractive"}
+	result.AddStep(NewStep(cmd, true, true))
+	result.Finish(nil)
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatJSON, result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var decoded struct {
+			Plan []Step `json:"plan"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+		}
+		if len(decoded.Plan) != 1 || len(decoded.Plan[0].Argv) != 4 || decoded.Plan[0].Env[0] != "DEBIAN_FRONTEND=noninteractive" ||
+			!decoded.Plan[0].NeedsRoot || !decoded.Plan[0].Destructive {
+			t.Errorf("Unexpected plan: %s", buf.String())
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Render(&buf, FormatTable, result); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 5 || !strings.HasPrefix(lines[3], "STEP") ||
+			!strings.HasSuffix(lines[4], "yes          DEBIAN_FRONTEND=noninteractive apt-get remove -y nginx") {
+			t.Errorf("Unexpected table: %s", buf.String())
+		}
+	})
+}
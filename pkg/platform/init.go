@@ -0,0 +1,70 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Init systems
+const (
+	InitSystemd = "systemd"
+	InitOpenRC  = "openrc"
+	InitSysV    = "sysvinit"
+	InitRunit   = "runit"
+	InitS6      = "s6"
+)
+
+// initByComm maps the command name of PID 1 to the init system it runs
+var initByComm = map[string]string{
+	"systemd":     InitSystemd,
+	"openrc-init": InitOpenRC,
+	"runit":       InitRunit,
+	"runit-init":  InitRunit,
+	"s6-svscan":   InitS6,
+}
+
+// initMarkers are checked in order when the command name of PID 1 does not
+// tell the init system, as with a generic "init" or a container entrypoint.
+// The paths of the running init systems come first, then those of the
+// installed ones, and the generic etc/init.d last.
+var initMarkers = []struct {
+	path string
+	init string
+}{
+	{"run/openrc", InitOpenRC},
+	{"run/runit", InitRunit},
+	{"etc/runit/runsvdir", InitRunit},
+	{"run/s6", InitS6},
+	{"run/service", InitS6},
+	// OpenRC keeps its scripts in etc/init.d as well, as on Alpine
+	{"sbin/openrc-run", InitOpenRC},
+	{"etc/runlevels", InitOpenRC},
+	{"etc/init.d", InitSysV},
+}
+
+// detectInit returns the init system of a Linux system, or an empty string
+// when none of the known ones is found
+func detectInit(root string) string {
+	// The check used by sd_booted(3)
+	if exists(filepath.Join(root, "run/systemd/system")) {
+		return InitSystemd
+	}
+	if comm, err := os.ReadFile(filepath.Join(root, "proc/1/comm")); err == nil {
+		if init, ok := initByComm[strings.TrimSpace(string(comm))]; ok {
+			return init
+		}
+	}
+	for _, marker := range initMarkers {
+		if exists(filepath.Join(root, marker.path)) {
+			return marker.init
+		}
+	}
+	return ""
+}
+
+// exists reports whether a file or directory exists at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,35 @@
+package platform
+
+import "testing"
+
+// TestDetectInit tests init system detection against fake root filesystems
+func TestDetectInit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{"Systemd Booted", map[string]string{"run/systemd/system/.keep": "", "proc/1/comm": "init\n"}, InitSystemd},
+		{"Systemd PID 1", map[string]string{"proc/1/comm": "systemd\n"}, InitSystemd},
+		{"Runit", map[string]string{"proc/1/comm": "runit\n", "etc/init.d/README": ""}, InitRunit},
+		{"s6-overlay", map[string]string{"proc/1/comm": "s6-svscan\n"}, InitS6},
+		{"OpenRC Behind Init", map[string]string{"proc/1/comm": "init\n", "run/openrc/softlevel": "default", "etc/init.d/nginx": ""}, InitOpenRC},
+		{"SysV", map[string]string{"proc/1/comm": "init\n", "etc/init.d/nginx": ""}, InitSysV},
+		{"Alpine Without OpenRC Running", map[string]string{"proc/1/comm": "sh\n", "sbin/openrc-run": "", "etc/runlevels/default/.keep": "", "etc/init.d/nginx": ""}, InitOpenRC},
+		{"Alpine Runlevels", map[string]string{"etc/runlevels/default/.keep": "", "etc/init.d/nginx": ""}, InitOpenRC},
+		{"Container Entrypoint", map[string]string{"proc/1/comm": "tini\n"}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := detectInit(writeRoot(t, tc.files)); got != tc.expected {
+				t.Errorf("Expected init system %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	root := writeRoot(t, map[string]string{"etc/os-release": "ID=debian\n", "proc/1/comm": "systemd\n"})
+	if p := detect("darwin", root); p.Init != "" {
+		t.Errorf("Expected no init system on darwin, got %s", p.Init)
+	}
+}
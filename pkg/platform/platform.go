@@ -0,0 +1,197 @@
+package platform
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Distribution families
+const (
+	FamilyDebian = "debian"
+	FamilyRedHat = "redhat"
+	FamilySuse   = "suse"
+	FamilyArch   = "arch"
+	FamilyAlpine = "alpine"
+	FamilyGentoo = "gentoo"
+	FamilyOther  = "other"
+)
+
+// Platform describes the system sai is running on
+type Platform struct {
+	OS      string `json:"os"`
+	Distro  string `json:"distro,omitempty"`
+	Family  string `json:"family,omitempty"`
+	Version string `json:"version,omitempty"`
+	Arch    string `json:"arch"`
+	// Init is the init system managing services on Linux, empty when unknown
+	Init string `json:"init,omitempty"`
+}
+
+// familyByID maps os-release IDs to distribution families
+var familyByID = map[string]string{
+	"debian":              FamilyDebian,
+	"ubuntu":              FamilyDebian,
+	"linuxmint":           FamilyDebian,
+	"raspbian":            FamilyDebian,
+	"pop":                 FamilyDebian,
+	"elementary":          FamilyDebian,
+	"kali":                FamilyDebian,
+	"rhel":                FamilyRedHat,
+	"fedora":              FamilyRedHat,
+	"centos":              FamilyRedHat,
+	"rocky":               FamilyRedHat,
+	"almalinux":           FamilyRedHat,
+	"ol":                  FamilyRedHat,
+	"amzn":                FamilyRedHat,
+	"suse":                FamilySuse,
+	"sles":                FamilySuse,
+	"opensuse":            FamilySuse,
+	"opensuse-leap":       FamilySuse,
+	"opensuse-tumbleweed": FamilySuse,
+	"arch":                FamilyArch,
+	"manjaro":             FamilyArch,
+	"endeavouros":         FamilyArch,
+	"alpine":              FamilyAlpine,
+	"gentoo":              FamilyGentoo,
+}
+
+// releaseFile describes a distribution-specific release file used as a fallback
+type releaseFile struct {
+	path   string
+	distro string
+}
+
+// releaseFiles are checked in order when os-release is not available
+var releaseFiles = []releaseFile{
+	{"etc/redhat-release", ""},
+	{"etc/SuSE-release", "suse"},
+	{"etc/alpine-release", "alpine"},
+	{"etc/arch-release", "arch"},
+	{"etc/gentoo-release", "gentoo"},
+	{"etc/debian_version", "debian"},
+}
+
+// redhatReleaseNames maps the product names found in /etc/redhat-release to IDs
+var redhatReleaseNames = []struct {
+	prefix string
+	id     string
+}{
+	{"Fedora", "fedora"},
+	{"CentOS", "centos"},
+	{"Rocky", "rocky"},
+	{"AlmaLinux", "almalinux"},
+	{"Oracle", "ol"},
+	{"Red Hat", "rhel"},
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// Detect detects the platform of the running system
+func Detect() Platform {
+	return DetectFromRoot("/")
+}
+
+// DetectFromRoot detects the platform reading distribution files below root.
+// Passing a directory other than "/" allows detection against a fake root
+// filesystem.
+func DetectFromRoot(root string) Platform {
+	return detect(runtime.GOOS, root)
+}
+
+// detect detects the platform for the given operating system
+func detect(goos, root string) Platform {
+	p := Platform{OS: goos, Arch: runtime.GOARCH}
+	if goos == "linux" {
+		var like []string
+		p.Distro, like, p.Version = detectLinux(root)
+		p.Family = FamilyOf(p.Distro, like)
+		p.Init = detectInit(root)
+	}
+	return p
+}
+
+// FamilyOf returns the family for a distribution ID, consulting the ID_LIKE
+// values when the ID itself is unknown
+func FamilyOf(id string, like []string) string {
+	if family, ok := familyByID[id]; ok {
+		return family
+	}
+	for _, l := range like {
+		if family, ok := familyByID[l]; ok {
+			return family
+		}
+	}
+	return FamilyOther
+}
+
+// detectLinux returns the distribution ID, the IDs it is derived from and
+// the version of a Linux system
+func detectLinux(root string) (string, []string, string) {
+	if fields := readOSRelease(root); fields["ID"] != "" {
+		like := strings.Fields(strings.ToLower(fields["ID_LIKE"]))
+		return strings.ToLower(fields["ID"]), like, fields["VERSION_ID"]
+	}
+
+	for _, rf := range releaseFiles {
+		content, err := os.ReadFile(filepath.Join(root, rf.path))
+		if err != nil {
+			continue
+		}
+		text := strings.TrimSpace(string(content))
+		distro := rf.distro
+		if rf.path == "etc/redhat-release" {
+			distro = "rhel"
+			for _, name := range redhatReleaseNames {
+				if strings.HasPrefix(text, name.prefix) {
+					distro = name.id
+					break
+				}
+			}
+		}
+		return distro, nil, versionPattern.FindString(text)
+	}
+
+	if fields := readKeyValueFile(filepath.Join(root, "etc/lsb-release")); fields["DISTRIB_ID"] != "" {
+		return strings.ToLower(fields["DISTRIB_ID"]), nil, fields["DISTRIB_RELEASE"]
+	}
+
+	return FamilyOther, nil, ""
+}
+
+// readOSRelease parses /etc/os-release, falling back to /usr/lib/os-release
+func readOSRelease(root string) map[string]string {
+	for _, path := range []string{"etc/os-release", "usr/lib/os-release"} {
+		if fields := readKeyValueFile(filepath.Join(root, path)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+// readKeyValueFile parses a shell-style KEY=value file
+func readKeyValueFile(path string) map[string]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		fields[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+	return fields
+}
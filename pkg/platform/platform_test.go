@@ -0,0 +1,125 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeRoot creates a fake root filesystem containing the given files
+func writeRoot(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// TestDetectLinux tests distribution detection against fake root filesystems
+func TestDetectLinux(t *testing.T) {
+	testCases := []struct {
+		name            string
+		files           map[string]string
+		expectedDistro  string
+		expectedFamily  string
+		expectedVersion string
+	}{
+		{
+			"Ubuntu",
+			map[string]string{"etc/os-release": "NAME=\"Ubuntu\"\nID=ubuntu\nID_LIKE=debian\nVERSION_ID=\"22.04\"\n"},
+			"ubuntu", FamilyDebian, "22.04",
+		},
+		{
+			"Fedora",
+			map[string]string{"etc/os-release": "NAME=\"Fedora Linux\"\nID=fedora\nVERSION_ID=39\n"},
+			"fedora", FamilyRedHat, "39",
+		},
+		{
+			"Rocky via ID_LIKE",
+			map[string]string{"etc/os-release": "ID=\"rocky-custom\"\nID_LIKE=\"rhel centos fedora\"\nVERSION_ID=\"9.3\"\n"},
+			"rocky-custom", FamilyRedHat, "9.3",
+		},
+		{
+			"openSUSE Leap",
+			map[string]string{"etc/os-release": "ID=\"opensuse-leap\"\nID_LIKE=\"suse opensuse\"\nVERSION_ID=\"15.5\"\n"},
+			"opensuse-leap", FamilySuse, "15.5",
+		},
+		{
+			"Arch",
+			map[string]string{"etc/os-release": "# comment\nID=arch\nBUILD_ID=rolling\n"},
+			"arch", FamilyArch, "",
+		},
+		{
+			"usr/lib/os-release",
+			map[string]string{"usr/lib/os-release": "ID=alpine\nVERSION_ID=3.19.1\n"},
+			"alpine", FamilyAlpine, "3.19.1",
+		},
+		{
+			"CentOS redhat-release",
+			map[string]string{"etc/redhat-release": "CentOS Linux release 7.9.2009 (Core)\n"},
+			"centos", FamilyRedHat, "7.9.2009",
+		},
+		{
+			"RHEL redhat-release",
+			map[string]string{"etc/redhat-release": "Red Hat Enterprise Linux release 8.6 (Ootpa)\n"},
+			"rhel", FamilyRedHat, "8.6",
+		},
+		{
+			"Debian version",
+			map[string]string{"etc/debian_version": "12.4\n"},
+			"debian", FamilyDebian, "12.4",
+		},
+		{
+			"Alpine release",
+			map[string]string{"etc/alpine-release": "3.18.0\n"},
+			"alpine", FamilyAlpine, "3.18.0",
+		},
+		{
+			"LSB release",
+			map[string]string{"etc/lsb-release": "DISTRIB_ID=Ubuntu\nDISTRIB_RELEASE=20.04\n"},
+			"ubuntu", FamilyDebian, "20.04",
+		},
+		{
+			"Unknown",
+			map[string]string{},
+			FamilyOther, FamilyOther, "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := detect("linux", writeRoot(t, tc.files))
+
+			if p.OS != "linux" {
+				t.Errorf("Expected OS linux, got %s", p.OS)
+			}
+			if p.Distro != tc.expectedDistro {
+				t.Errorf("Expected distro %s, got %s", tc.expectedDistro, p.Distro)
+			}
+			if p.Family != tc.expectedFamily {
+				t.Errorf("Expected family %s, got %s", tc.expectedFamily, p.Family)
+			}
+			if p.Version != tc.expectedVersion {
+				t.Errorf("Expected version %s, got %s", tc.expectedVersion, p.Version)
+			}
+			if p.Arch == "" {
+				t.Errorf("Expected architecture to be set")
+			}
+		})
+	}
+}
+
+// TestDetectNonLinux tests that distribution files are ignored on other systems
+func TestDetectNonLinux(t *testing.T) {
+	root := writeRoot(t, map[string]string{"etc/os-release": "ID=ubuntu\n"})
+	p := detect("darwin", root)
+	if p.Distro != "" || p.Family != "" {
+		t.Errorf("Expected no distro on darwin, got %+v", p)
+	}
+}
@@ -0,0 +1,331 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// External provider plugins are executables named sai-provider-<name>. Every
+// invocation writes one JSON request to the standard input of the plugin and
+// reads one JSON response from its standard output; the standard error of
+// the plugin is shown to the user unless the context is quiet.
+//
+// The first request of a session is a handshake, answered with the name,
+// type and supported actions of the plugin:
+//
+//	{"protocol":1,"action":"handshake"}
+//	{"protocol":1,"name":"acme","type":"os","version":"1.0.0","actions":["install","status"]}
+//
+// Action requests carry the software, options and dry run mode, and are
+// answered with the outcome:
+//
+//	{"protocol":1,"action":"install","software":"nginx","options":{"os":"linux"},"dry_run":false}
+//	{"success":true,"message":"nginx installed","version":"1.24.0"}
+const (
+	// PluginPrefix is the prefix of the executable name of a plugin
+	PluginPrefix = "sai-provider-"
+	// PluginProtocol is the version of the plugin protocol spoken by sai
+	PluginProtocol = 1
+	// ActionHandshake is the action of the handshake request
+	ActionHandshake = "handshake"
+)
+
+// PluginTypes are the provider types a plugin may declare
+var PluginTypes = []string{"os", "container", "cloud", "language"}
+
+// Request is sent to a plugin on its standard input
+type Request struct {
+	Protocol int               `json:"protocol"`
+	Action   string            `json:"action"`
+	Software string            `json:"software,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+	DryRun   bool              `json:"dry_run"`
+}
+
+// Handshake is the response of a plugin to the handshake request
+type Handshake struct {
+	Protocol int      `json:"protocol"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Version  string   `json:"version,omitempty"`
+	Actions  []string `json:"actions"`
+}
+
+// Response is the response of a plugin to an action request
+type Response struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// NotFound reports that the software does not exist or is not installed
+	NotFound bool `json:"not_found,omitempty"`
+	// Version is the installed version of the software, if known
+	Version string `json:"version,omitempty"`
+	// Commands lists the commands run by the plugin or, in dry run mode,
+	// the commands it would run
+	Commands []string `json:"commands,omitempty"`
+	// Data holds additional details added to the structured result
+	Data map[string]string `json:"data,omitempty"`
+}
+
+// Plugin is an external provider plugin that completed the handshake
+type Plugin struct {
+	Name    string
+	Path    string
+	Type    string
+	Version string
+	Actions []string
+}
+
+// Supports reports whether the plugin declared the action in its handshake
+func (p *Plugin) Supports(action string) bool {
+	for _, a := range p.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// call sends a request to the plugin and decodes its response into v
+func (p *Plugin) call(ctx context.Context, req Request, v interface{}) error {
+	req.Protocol = PluginProtocol
+	input, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	cmd := runner.NewCommand(p.Path)
+	cmd.Stdin = string(input)
+	cmd.Quiet = true
+	result, err := runner.Run(ctx, cmd)
+	if result != nil && result.Stderr != "" && !runner.IsQuiet(ctx) {
+		fmt.Fprint(os.Stderr, result.Stderr)
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return &errs.ProviderUnavailableError{Provider: p.Name, Reason: fmt.Sprintf("%s not found", p.Path)}
+	}
+
+	// A failing plugin may still describe the failure in its response
+	var commandErr *errs.CommandFailedError
+	if err != nil && (!errors.As(err, &commandErr) || result == nil) {
+		return err
+	}
+	if result == nil || strings.TrimSpace(result.Stdout) == "" {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("plugin %s returned an empty response", p.Name)
+	}
+	if decodeErr := json.Unmarshal([]byte(result.Stdout), v); decodeErr != nil {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("plugin %s returned an invalid response: %w", p.Name, decodeErr)
+	}
+	return nil
+}
+
+// Invoke sends an action request to the plugin and returns its response.
+// The options attached to the context are sent along with the request.
+func (p *Plugin) Invoke(ctx context.Context, action, software string, dryRun bool) (*Response, error) {
+	if !p.Supports(action) {
+		return nil, &errs.UnsupportedActionError{Action: action, Provider: p.Name}
+	}
+
+	req := Request{Action: action, Software: software, Options: OptionsFromContext(ctx), DryRun: dryRun}
+	resp := &Response{}
+	if err := p.call(ctx, req, resp); err != nil {
+		return nil, err
+	}
+
+	result := output.FromContext(ctx)
+	if resp.Version != "" {
+		result.Set("version", resp.Version)
+	}
+	for key, value := range resp.Data {
+		result.Set(key, value)
+	}
+
+	if resp.Success {
+		return resp, nil
+	}
+	if resp.NotFound {
+		return resp, &errs.SoftwareNotFoundError{Software: software, Provider: p.Name}
+	}
+	if resp.Error == "" {
+		resp.Error = fmt.Sprintf("%s %s failed", action, software)
+	}
+	return resp, fmt.Errorf("plugin %s: %s", p.Name, resp.Error)
+}
+
+// Execute runs an action on the software, implementing the provider
+// interface used by the command handlers
+func (p *Plugin) Execute(ctx context.Context, action, software string) error {
+	resp, err := p.Invoke(ctx, action, software, false)
+	if resp != nil && resp.Message != "" && !runner.IsQuiet(ctx) {
+		fmt.Println(resp.Message)
+	}
+	return err
+}
+
+// handshake asks the plugin for its name, type and actions
+func (p *Plugin) handshake(ctx context.Context) error {
+	var h Handshake
+	if err := p.call(ctx, Request{Action: ActionHandshake}, &h); err != nil {
+		return err
+	}
+	if h.Protocol != PluginProtocol {
+		return fmt.Errorf("unsupported protocol version %d (sai speaks %d)", h.Protocol, PluginProtocol)
+	}
+	if h.Name != "" && h.Name != p.Name {
+		return fmt.Errorf("plugin declares the name %q", h.Name)
+	}
+	if !isPluginType(h.Type) {
+		return fmt.Errorf("unsupported provider type %q (supported: %s)", h.Type, strings.Join(PluginTypes, ", "))
+	}
+	if len(h.Actions) == 0 {
+		return fmt.Errorf("plugin declares no actions")
+	}
+	p.Type, p.Version, p.Actions = h.Type, h.Version, h.Actions
+	return nil
+}
+
+// isPluginType reports whether a plugin may declare the provider type
+func isPluginType(t string) bool {
+	for _, pt := range PluginTypes {
+		if pt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// PluginDirs returns the directories searched for plugins before the PATH,
+// in order of precedence
+func PluginDirs() []string {
+	var dirs []string
+	if env := os.Getenv("SAI_PLUGIN_PATH"); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "sai", "plugins"))
+	}
+	return append(dirs, "/usr/local/lib/sai/plugins", "/usr/lib/sai/plugins")
+}
+
+// pluginFile returns the executable name of the plugin for a provider
+func pluginFile(name string) string {
+	if runtime.GOOS == "windows" {
+		return PluginPrefix + name + ".exe"
+	}
+	return PluginPrefix + name
+}
+
+// isExecutable reports whether the path is an executable regular file
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0o111 != 0
+}
+
+// FindPlugin returns the path of the plugin for a provider, searching the
+// plugin directories first and the PATH then. exec.ErrNotFound is returned
+// when there is no such plugin.
+func FindPlugin(ctx context.Context, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", exec.ErrNotFound
+	}
+	for _, dir := range PluginDirs() {
+		if path := filepath.Join(dir, pluginFile(name)); isExecutable(path) {
+			return path, nil
+		}
+	}
+	return runner.LookPath(ctx, pluginFile(name))
+}
+
+// ListPlugins returns the names of the plugins installed in the plugin
+// directories and the PATH, without contacting them
+func ListPlugins() []string {
+	dirs := append(PluginDirs(), filepath.SplitList(os.Getenv("PATH"))...)
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), ".exe")
+			if !strings.HasPrefix(name, PluginPrefix) || seen[name] || !isExecutable(filepath.Join(dir, entry.Name())) {
+				continue
+			}
+			seen[name] = true
+			names = append(names, strings.TrimPrefix(name, PluginPrefix))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginMu serializes plugin discovery
+var pluginMu sync.Mutex
+
+// LoadPlugin returns the plugin for a provider, discovering it and
+// completing the handshake on first use. The plugin is registered in the
+// global provider registry with one action per declared action.
+// exec.ErrNotFound is returned when there is no such plugin.
+func LoadPlugin(ctx context.Context, name string) (*Plugin, error) {
+	pluginMu.Lock()
+	defer pluginMu.Unlock()
+
+	if provider, ok := LookupProvider(name); ok && provider.Plugin != nil {
+		return provider.Plugin, nil
+	}
+
+	path, err := FindPlugin(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	plugin := &Plugin{Name: name, Path: path}
+	if err := plugin.handshake(ctx); err != nil {
+		return nil, &errs.ProviderUnavailableError{Provider: name, Reason: fmt.Sprintf("plugin %s: handshake failed: %v", path, err)}
+	}
+
+	provider := &Provider{Type: plugin.Type, Plugin: plugin}
+	for _, action := range plugin.Actions {
+		action := action
+		provider.RegisterAction(action, func(ctx context.Context, software string) error {
+			return plugin.Execute(ctx, action, software)
+		})
+	}
+	RegisterProvider(name, provider)
+	return plugin, nil
+}
+
+type optionsKey struct{}
+
+// WithOptions returns a context carrying the options sent to plugins
+func WithOptions(ctx context.Context, options map[string]string) context.Context {
+	return context.WithValue(ctx, optionsKey{}, options)
+}
+
+// OptionsFromContext returns the options attached to the context, or nil
+func OptionsFromContext(ctx context.Context) map[string]string {
+	options, _ := ctx.Value(optionsKey{}).(map[string]string)
+	return options
+}
@@ -0,0 +1,163 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"sai/pkg/errs"
+	"sai/pkg/output"
+	"sai/pkg/runner"
+)
+
+// pluginRecorder answers plugin requests with the given handshake and
+// action responses, recording the decoded requests
+func pluginRecorder(handshake string, respond func(req Request) string) (*runner.Recorder, *[]Request) {
+	requests := &[]Request{}
+	recorder := runner.NewRecorder()
+	recorder.Respond = func(cmd runner.Command) (*runner.Result, error) {
+		var req Request
+		if err := json.Unmarshal([]byte(cmd.Stdin), &req); err != nil {
+			return nil, err
+		}
+		*requests = append(*requests, req)
+		if req.Action == ActionHandshake {
+			return &runner.Result{Command: cmd, Stdout: handshake}, nil
+		}
+		return &runner.Result{Command: cmd, Stdout: respond(req)}, nil
+	}
+	return recorder, requests
+}
+
+// TestPluginInvoke tests the request sent to a plugin and the handling of its response
+func TestPluginInvoke(t *testing.T) {
+	handshake := `{"protocol":1,"name":"acme","type":"os","version":"0.3.0","actions":["install","status"]}`
+	recorder, requests := pluginRecorder(handshake, func(req Request) string {
+		switch req.Software {
+		case "nginx":
+			return `{"success":true,"message":"installed","version":"1.24.0","data":{"channel":"stable"}}`
+		case "missing":
+			return `{"success":false,"not_found":true}`
+		default:
+			return `{"success":false,"error":"repository unreachable"}`
+		}
+	})
+	ctx := runner.WithRunner(context.Background(), recorder)
+
+	plugin := &Plugin{Name: "acme", Path: "/usr/bin/sai-provider-acme"}
+	if err := plugin.handshake(ctx); err != nil {
+		t.Fatalf("Unexpected handshake error: %v", err)
+	}
+	if plugin.Type != "os" || plugin.Version != "0.3.0" || !plugin.Supports("install") || plugin.Supports("upgrade") {
+		t.Fatalf("Unexpected plugin: %+v", plugin)
+	}
+
+	result := output.NewResult("nginx", "install")
+	ctx = WithOptions(output.WithResult(ctx, result), map[string]string{"os": "linux"})
+	if err := plugin.Execute(ctx, "install", "nginx"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	last := (*requests)[len(*requests)-1]
+	if last.Protocol != PluginProtocol || last.Action != "install" || last.Software != "nginx" || last.Options["os"] != "linux" || last.DryRun {
+		t.Errorf("Unexpected request: %+v", last)
+	}
+	if result.Get("version") != "1.24.0" || result.Get("channel") != "stable" {
+		t.Errorf("Expected the version and data in the result, got: %+v", result.Data)
+	}
+
+	if _, err := plugin.Invoke(ctx, "install", "nginx", true); err != nil || !(*requests)[len(*requests)-1].DryRun {
+		t.Errorf("Expected a dry run request, got: %v", err)
+	}
+
+	var notFound *errs.SoftwareNotFoundError
+	if err := plugin.Execute(ctx, "status", "missing"); !errors.As(err, &notFound) {
+		t.Errorf("Expected a not found error, got: %v", err)
+	}
+	if err := plugin.Execute(ctx, "install", "broken"); err == nil || !strings.Contains(err.Error(), "repository unreachable") {
+		t.Errorf("Expected the plugin error, got: %v", err)
+	}
+	var unsupported *errs.UnsupportedActionError
+	if err := plugin.Execute(ctx, "upgrade", "nginx"); !errors.As(err, &unsupported) {
+		t.Errorf("Expected an unsupported action error, got: %v", err)
+	}
+}
+
+// TestPluginHandshake tests the validation of handshake responses
+func TestPluginHandshake(t *testing.T) {
+	testCases := []struct {
+		name      string
+		handshake string
+		valid     bool
+	}{
+		{"Valid", `{"protocol":1,"type":"cloud","actions":["status"]}`, true},
+		{"Protocol", `{"protocol":2,"type":"os","actions":["status"]}`, false},
+		{"Name", `{"protocol":1,"name":"other","type":"os","actions":["status"]}`, false},
+		{"Type", `{"protocol":1,"type":"spaceship","actions":["status"]}`, false},
+		{"No Actions", `{"protocol":1,"type":"os"}`, false},
+		{"Invalid JSON", `hello`, false},
+		{"Empty", ``, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder, _ := pluginRecorder(tc.handshake, nil)
+			ctx := runner.WithRunner(context.Background(), recorder)
+			err := (&Plugin{Name: "acme", Path: "sai-provider-acme"}).handshake(ctx)
+			if tc.valid && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+// TestLoadPlugin tests discovering a plugin in the plugin path and running it
+func TestLoadPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell script plugins are not supported on Windows")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+read request
+case "$request" in
+*'"handshake"'*) echo '{"protocol":1,"type":"container","actions":["status"]}' ;;
+*) echo 'checking' >&2; echo '{"success":true,"version":"2.0"}' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "sai-provider-script"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SAI_PLUGIN_PATH", dir)
+	ctx := runner.WithRunner(context.Background(), &runner.ExecRunner{})
+
+	plugin, err := LoadPlugin(ctx, "script")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if provider, ok := LookupProvider("script"); !ok || provider.Type != "container" || len(provider.Actions()) != 1 {
+		t.Errorf("Expected the plugin to be registered, got: %+v", provider)
+	}
+	resp, err := plugin.Invoke(ctx, "status", "redis", false)
+	if err != nil || resp.Version != "2.0" {
+		t.Errorf("Unexpected response: %+v (error: %v)", resp, err)
+	}
+
+	found := false
+	for _, name := range ListPlugins() {
+		found = found || name == "script"
+	}
+	if !found {
+		t.Errorf("Expected the plugin to be listed")
+	}
+	if _, err := FindPlugin(ctx, "../script"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected names with separators to be rejected, got: %v", err)
+	}
+}
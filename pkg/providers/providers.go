@@ -1,15 +1,24 @@
 package providers
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"sync"
+
+	"sai/pkg/state"
 )
 
 // ActionFunc defines the signature for an action function.
-type ActionFunc func(software string) error
+type ActionFunc func(ctx context.Context, software string) error
 
 // Provider represents a collection of actions.
 type Provider struct {
+	// Type is the provider type, such as os, container or cloud
+	Type string
+	// Plugin is set for providers backed by an external plugin
+	Plugin *Plugin
+
 	actions map[string]ActionFunc
 }
 
@@ -21,10 +30,20 @@ func (p *Provider) RegisterAction(actionName string, action ActionFunc) {
 	p.actions[actionName] = action
 }
 
+// Actions returns the names of the registered actions in alphabetical order.
+func (p *Provider) Actions() []string {
+	names := make([]string, 0, len(p.actions))
+	for name := range p.actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ExecuteAction executes the specified action for the provider.
-func (p *Provider) ExecuteAction(actionName, software string) error {
+func (p *Provider) ExecuteAction(ctx context.Context, actionName, software string) error {
 	if action, exists := p.actions[actionName]; exists {
-		return action(software)
+		return action(ctx, software)
 	}
 	return fmt.Errorf("action '%s' is not supported for this provider", actionName)
 }
@@ -59,6 +78,26 @@ func (r *ProviderRegistry) GetProvider(name string) *Provider {
 	return &Provider{} // Return a default provider with no actions.
 }
 
+// LookupProvider retrieves a provider by name, reporting whether it is registered.
+func (r *ProviderRegistry) LookupProvider(name string) (*Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, exists := r.providers[name]
+	return provider, exists
+}
+
+// Names returns the names of the registered providers in alphabetical order.
+func (r *ProviderRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Global registry instance
 var registry = NewProviderRegistry()
 
@@ -72,41 +111,23 @@ func GetProvider(name string) *Provider {
 	return registry.GetProvider(name)
 }
 
+// LookupProvider is a helper function to look a provider up globally.
+func LookupProvider(name string) (*Provider, bool) {
+	return registry.LookupProvider(name)
+}
+
 // ExecuteAction is a helper function to execute an action for a provider globally.
-func ExecuteAction(providerName, actionName, software string) error {
+func ExecuteAction(ctx context.Context, providerName, actionName, software string) error {
 	provider := GetProvider(providerName)
-	err := provider.ExecuteAction(actionName, software)
+	err := provider.ExecuteAction(ctx, actionName, software)
 	if err != nil {
 		return fmt.Errorf("error executing action '%s' for provider '%s': %w", actionName, providerName, err)
 	}
 	return nil
 }
 
-// GetAllManagedSoftware returns a list of all managed software.
-func GetAllManagedSoftware() []string {
-	// Example: Return a static list of managed software.
-	return []string{"nginx", "docker", "mysql", "redis", "kubernetes"}
-}
-
-// Example usage of dynamic registration
-func init() {
-	// Example: Registering a "brew" provider with some actions.
-	brewProvider := &Provider{}
-	brewProvider.RegisterAction("install", func(software string) error {
-		fmt.Printf("Installing %s using brew...\n", software)
-		return nil
-	})
-	brewProvider.RegisterAction("observe", func(software string) error {
-		fmt.Printf("Observing %s using brew...\n", software)
-		return nil
-	})
-	RegisterProvider("brew", brewProvider)
-
-	// Example: Registering a "rpm" provider with some actions.
-	rpmProvider := &Provider{}
-	rpmProvider.RegisterAction("install", func(software string) error {
-		fmt.Printf("Installing %s using rpm...\n", software)
-		return nil
-	})
-	RegisterProvider("rpm", rpmProvider)
+// GetAllManagedSoftware returns the software managed by sai, that is the
+// software recorded as installed in the local state.
+func GetAllManagedSoftware() ([]string, error) {
+	return state.Default().Installed()
 }
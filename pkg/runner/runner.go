@@ -0,0 +1,283 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"sai/pkg/errs"
+)
+
+// Command describes an external command to be executed
+type Command struct {
+	Name    string        `json:"name"`
+	Args    []string      `json:"args,omitempty"`
+	Env     []string      `json:"env,omitempty"`
+	Dir     string        `json:"dir,omitempty"`
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Quiet captures the output without streaming it
+	Quiet bool `json:"-"`
+	// Stdin, when set, is written to the standard input of the command
+	// instead of forwarding the input of sai
+	Stdin string `json:"-"`
+}
+
+// NewCommand creates a new command from a program name and its arguments
+func NewCommand(name string, args ...string) Command {
+	return Command{Name: name, Args: args}
+}
+
+// Argv returns the full argument vector, program name included
+func (c Command) Argv() []string {
+	return append([]string{c.Name}, c.Args...)
+}
+
+// String returns a shell-like representation of the command
+func (c Command) String() string {
+	parts := make([]string, 0, len(c.Args)+1)
+	for _, arg := range c.Argv() {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"*?$&|;<>()") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Result holds the outcome of an executed command
+type Result struct {
+	Command  Command       `json:"command"`
+	ExitCode int           `json:"exit_code"`
+	Stdout   string        `json:"stdout,omitempty"`
+	Stderr   string        `json:"stderr,omitempty"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Success reports whether the command exited with status 0
+func (r *Result) Success() bool {
+	return r.ExitCode == 0
+}
+
+// Runner executes commands
+type Runner interface {
+	Run(ctx context.Context, cmd Command) (*Result, error)
+}
+
+// PathFinder is implemented by runners that can locate executables
+type PathFinder interface {
+	LookPath(file string) (string, error)
+}
+
+// ExecRunner runs commands on the local system, streaming their output live
+type ExecRunner struct {
+	// Stdout and Stderr receive the live output. When nil, the process
+	// standard streams are used.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Timeout applies to commands that do not set their own timeout.
+	// Zero means no timeout.
+	Timeout time.Duration
+}
+
+// NewExecRunner creates a runner that executes commands on the local system
+func NewExecRunner() *ExecRunner {
+	return &ExecRunner{}
+}
+
+// Run executes the command and waits for it to complete
+func (r *ExecRunner) Run(ctx context.Context, cmd Command) (*Result, error) {
+	timeout := cmd.Timeout
+	if timeout == 0 {
+		timeout = r.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	stdout, stderr := r.Stdout, r.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	if cmd.Quiet || IsQuiet(ctx) {
+		stdout, stderr = io.Discard, io.Discard
+	}
+
+	var outBuf, errBuf bytes.Buffer
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	c.Dir = cmd.Dir
+	if len(cmd.Env) > 0 {
+		c.Env = append(os.Environ(), cmd.Env...)
+	}
+	c.Stdin = os.Stdin
+	if cmd.Stdin != "" {
+		c.Stdin = strings.NewReader(cmd.Stdin)
+	}
+	c.Stdout = io.MultiWriter(stdout, &outBuf)
+	c.Stderr = io.MultiWriter(stderr, &errBuf)
+
+	result := &Result{Command: cmd, Started: time.Now()}
+	err := c.Run()
+	result.Duration = time.Since(result.Started)
+	result.Stdout = outBuf.String()
+	result.Stderr = errBuf.String()
+
+	if err == nil {
+		return result, nil
+	}
+
+	// A cancelled or expired context takes precedence over the exit status
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		result.ExitCode = -1
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return result, fmt.Errorf("command %q timed out after %s", cmd.String(), timeout)
+		}
+		return result, fmt.Errorf("command %q cancelled: %w", cmd.String(), ctxErr)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return result, &errs.CommandFailedError{Command: cmd.String(), ExitCode: result.ExitCode}
+	}
+
+	result.ExitCode = -1
+	return result, fmt.Errorf("failed to run %q: %w", cmd.String(), err)
+}
+
+// LookPath searches for an executable in the directories of the PATH
+func (r *ExecRunner) LookPath(file string) (string, error) {
+	return exec.LookPath(file)
+}
+
+// Recorder is a Runner that records commands instead of executing them.
+// It is intended for tests.
+type Recorder struct {
+	mu       sync.Mutex
+	commands []Command
+	// Respond, when set, produces the result for each recorded command.
+	// By default every command succeeds with empty output.
+	Respond func(cmd Command) (*Result, error)
+	// Missing lists executables LookPath reports as not found.
+	// Every other executable is found in /usr/bin.
+	Missing []string
+}
+
+// NewRecorder creates a new recording runner
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Run records the command and returns the configured response
+func (r *Recorder) Run(ctx context.Context, cmd Command) (*Result, error) {
+	r.mu.Lock()
+	r.commands = append(r.commands, cmd)
+	respond := r.Respond
+	r.mu.Unlock()
+
+	if respond != nil {
+		return respond(cmd)
+	}
+	return &Result{Command: cmd, Started: time.Now()}, nil
+}
+
+// LookPath pretends every executable not listed in Missing is installed
+func (r *Recorder) LookPath(file string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, missing := range r.Missing {
+		if missing == file {
+			return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+		}
+	}
+	return "/usr/bin/" + file, nil
+}
+
+// Commands returns the commands recorded so far
+func (r *Recorder) Commands() []Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Command(nil), r.commands...)
+}
+
+// Reset clears the recorded commands
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = nil
+}
+
+// Global default runner
+var (
+	defaultMu     sync.RWMutex
+	defaultRunner Runner = NewExecRunner()
+)
+
+// SetDefault replaces the runner used when none is attached to the context
+func SetDefault(r Runner) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRunner = r
+}
+
+// Default returns the runner used when none is attached to the context
+func Default() Runner {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultRunner
+}
+
+type runnerKey struct{}
+
+// WithRunner returns a context carrying the given runner
+func WithRunner(ctx context.Context, r Runner) context.Context {
+	return context.WithValue(ctx, runnerKey{}, r)
+}
+
+// FromContext returns the runner attached to the context, or the default runner
+func FromContext(ctx context.Context) Runner {
+	if r, ok := ctx.Value(runnerKey{}).(Runner); ok && r != nil {
+		return r
+	}
+	return Default()
+}
+
+type quietKey struct{}
+
+// WithQuiet returns a context in which every command is captured without
+// streaming its output, as if it had Quiet set
+func WithQuiet(ctx context.Context) context.Context {
+	return context.WithValue(ctx, quietKey{}, true)
+}
+
+// IsQuiet reports whether commands run with the context are quiet
+func IsQuiet(ctx context.Context) bool {
+	quiet, _ := ctx.Value(quietKey{}).(bool)
+	return quiet
+}
+
+// Run executes the command with the runner attached to the context
+func Run(ctx context.Context, cmd Command) (*Result, error) {
+	return FromContext(ctx).Run(ctx, cmd)
+}
+
+// LookPath locates an executable with the runner attached to the context,
+// falling back to the PATH of the current process
+func LookPath(ctx context.Context, file string) (string, error) {
+	if finder, ok := FromContext(ctx).(PathFinder); ok {
+		return finder.LookPath(file)
+	}
+	return exec.LookPath(file)
+}
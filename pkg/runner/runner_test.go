@@ -0,0 +1,201 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"sai/pkg/errs"
+)
+
+// helperCommand builds a command that re-executes the test binary as a fake tool
+func helperCommand(args ...string) Command {
+	cmd := NewCommand(os.Args[0], append([]string{"-test.run=TestHelperProcess", "--"}, args...)...)
+	cmd.Env = []string{"SAI_WANT_HELPER_PROCESS=1"}
+	return cmd
+}
+
+// TestHelperProcess is not a real test, it is used as a fake external command
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("SAI_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	args = args[1:]
+
+	switch args[0] {
+	case "echo":
+		fmt.Fprintln(os.Stdout, strings.Join(args[1:], " "))
+		fmt.Fprintln(os.Stderr, "to stderr")
+		os.Exit(0)
+	case "cat":
+		io.Copy(os.Stdout, os.Stdin)
+		os.Exit(0)
+	case "exit":
+		code, _ := strconv.Atoi(args[1])
+		os.Exit(code)
+	case "sleep":
+		time.Sleep(10 * time.Second)
+		os.Exit(0)
+	}
+	os.Exit(2)
+}
+
+// TestExecRunnerCapturesOutput tests that output is streamed and captured
+func TestExecRunnerCapturesOutput(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	r := &ExecRunner{Stdout: &stdout, Stderr: &stderr}
+
+	result, err := r.Run(context.Background(), helperCommand("echo", "hello", "world"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.ExitCode != 0 || !result.Success() {
+		t.Errorf("Expected exit code 0, got: %d", result.ExitCode)
+	}
+	if result.Stdout != "hello world\n" {
+		t.Errorf("Expected captured stdout 'hello world', got: %q", result.Stdout)
+	}
+	if stdout.String() != result.Stdout {
+		t.Errorf("Expected stdout to be streamed, got: %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "to stderr") || !strings.Contains(result.Stderr, "to stderr") {
+		t.Errorf("Expected stderr to be streamed and captured, got: %q / %q", stderr.String(), result.Stderr)
+	}
+}
+
+// TestExecRunnerExitCode tests that non-zero exit codes are reported
+func TestExecRunnerExitCode(t *testing.T) {
+	r := &ExecRunner{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+
+	result, err := r.Run(context.Background(), helperCommand("exit", "3"))
+	var exitErr *errs.CommandFailedError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected CommandFailedError, got: %v", err)
+	}
+	if exitErr.ExitCode != 3 || result.ExitCode != 3 {
+		t.Errorf("Expected exit code 3, got: %d / %d", exitErr.ExitCode, result.ExitCode)
+	}
+}
+
+// TestExecRunnerTimeout tests that commands are killed after their timeout
+func TestExecRunnerTimeout(t *testing.T) {
+	r := &ExecRunner{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}, Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	_, err := r.Run(context.Background(), helperCommand("sleep"))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Expected timeout error, got: %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Expected command to be killed on timeout")
+	}
+}
+
+// TestExecRunnerCancel tests that cancelling the context stops the command
+func TestExecRunnerCancel(t *testing.T) {
+	r := &ExecRunner{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	_, err := r.Run(ctx, helperCommand("sleep"))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancellation error, got: %v", err)
+	}
+}
+
+// TestExecRunnerMissingBinary tests the error for commands that cannot start
+func TestExecRunnerMissingBinary(t *testing.T) {
+	r := &ExecRunner{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+
+	_, err := r.Run(context.Background(), NewCommand("sai-definitely-missing-binary"))
+	if err == nil || !strings.Contains(err.Error(), "failed to run") {
+		t.Errorf("Expected start failure, got: %v", err)
+	}
+}
+
+// TestRecorderFromContext tests that the context runner overrides the default
+func TestRecorderFromContext(t *testing.T) {
+	recorder := NewRecorder()
+	ctx := WithRunner(context.Background(), recorder)
+
+	if _, err := Run(ctx, NewCommand("apt-get", "install", "-y", "nginx")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	commands := recorder.Commands()
+	if len(commands) != 1 || commands[0].String() != "apt-get install -y nginx" {
+		t.Errorf("Expected recorded command, got: %v", commands)
+	}
+
+	if FromContext(context.Background()) != Default() {
+		t.Errorf("Expected default runner without a context runner")
+	}
+}
+
+// TestCommandString tests quoting of command arguments
+func TestCommandString(t *testing.T) {
+	cmd := NewCommand("rpm", "-qa", "*nginx*", "two words")
+	expected := `rpm -qa '*nginx*' 'two words'`
+	if cmd.String() != expected {
+		t.Errorf("Expected %s, got: %s", expected, cmd.String())
+	}
+}
+
+// TestQuietCommand tests that quiet commands are captured but not streamed
+func TestQuietCommand(t *testing.T) {
+	var stdout bytes.Buffer
+	r := &ExecRunner{Stdout: &stdout, Stderr: &bytes.Buffer{}}
+
+	cmd := helperCommand("echo", "quiet")
+	cmd.Quiet = true
+	result, err := r.Run(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Stdout != "quiet\n" || stdout.Len() != 0 {
+		t.Errorf("Expected captured but not streamed output, got: %q / %q", result.Stdout, stdout.String())
+	}
+}
+
+// TestCommandStdin tests that the input of a command can be provided
+func TestCommandStdin(t *testing.T) {
+	r := &ExecRunner{Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}}
+
+	cmd := helperCommand("cat")
+	cmd.Stdin = `{"action":"handshake"}`
+	result, err := r.Run(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Stdout != cmd.Stdin {
+		t.Errorf("Expected the input to be echoed, got: %q", result.Stdout)
+	}
+}
+
+// TestRecorderLookPath tests the fake executable lookup of the recorder
+func TestRecorderLookPath(t *testing.T) {
+	recorder := NewRecorder()
+	recorder.Missing = []string{"brew"}
+	ctx := WithRunner(context.Background(), recorder)
+
+	if path, err := LookPath(ctx, "apt-get"); err != nil || path != "/usr/bin/apt-get" {
+		t.Errorf("Expected apt-get to be found, got: %s, %v", path, err)
+	}
+	if _, err := LookPath(ctx, "brew"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Expected brew to be missing, got: %v", err)
+	}
+}
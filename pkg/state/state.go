@@ -0,0 +1,245 @@
+// Package state keeps a local record of the software sai has installed or
+// changed, so that later actions can reuse the provider that installed it.
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the state file inside the state directory
+const FileName = "state.json"
+
+// Action is a successful action performed by sai
+type Action struct {
+	Action       string    `json:"action" yaml:"action"`
+	Provider     string    `json:"provider,omitempty" yaml:"provider,omitempty"`
+	ProviderType string    `json:"provider_type,omitempty" yaml:"provider_type,omitempty"`
+	Target       string    `json:"target,omitempty" yaml:"target,omitempty"`
+	Version      string    `json:"version,omitempty" yaml:"version,omitempty"`
+	Time         time.Time `json:"time" yaml:"time"`
+	User         string    `json:"user,omitempty" yaml:"user,omitempty"`
+	Commands     []string  `json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// Record is what sai knows about a piece of software
+type Record struct {
+	Software     string    `json:"software" yaml:"software"`
+	Installed    bool      `json:"installed" yaml:"installed"`
+	Provider     string    `json:"provider,omitempty" yaml:"provider,omitempty"`
+	ProviderType string    `json:"provider_type,omitempty" yaml:"provider_type,omitempty"`
+	Target       string    `json:"target,omitempty" yaml:"target,omitempty"`
+	Version      string    `json:"version,omitempty" yaml:"version,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at" yaml:"updated_at"`
+	History      []Action  `json:"history" yaml:"history"`
+}
+
+// Apply updates the record with a successful action
+func (r *Record) Apply(a Action) {
+	r.History = append(r.History, a)
+	r.UpdatedAt = a.Time
+
+	switch a.Action {
+	case "install", "upgrade":
+		r.Installed = true
+		r.Provider, r.ProviderType, r.Target = a.Provider, a.ProviderType, a.Target
+		if a.Version != "" {
+			r.Version = a.Version
+		}
+	case "uninstall":
+		r.Installed = false
+		r.Version = ""
+	}
+}
+
+// Store persists records in a JSON file
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore creates a store backed by the given file
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the file backing the store
+func (s *Store) Path() string {
+	return s.path
+}
+
+// load reads all records from the state file
+func (s *Store) load() (map[string]*Record, error) {
+	records := make(map[string]*Record)
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Software map[string]*Record `json:"software"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+	if file.Software != nil {
+		records = file.Software
+	}
+	return records, nil
+}
+
+// save atomically replaces the state file
+func (s *Store) save(records map[string]*Record) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(map[string]any{"software": records}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), FileName+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Record stores a successful action on a piece of software. It is a no-op on
+// a nil store.
+func (s *Store) Record(software string, a Action) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+	record, ok := records[software]
+	if !ok {
+		record = &Record{Software: software}
+		records[software] = record
+	}
+	record.Apply(a)
+	return s.save(records)
+}
+
+// Get returns the record of a piece of software, or nil if sai never changed
+// it. A nil store has no records.
+func (s *Store) Get(software string) (*Record, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return records[software], nil
+}
+
+// List returns all records sorted by software name
+func (s *Store) List() ([]Record, error) {
+	if s == nil {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Record, 0, len(records))
+	for _, r := range records {
+		list = append(list, *r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Software < list[j].Software })
+	return list, nil
+}
+
+// Installed returns the names of the software recorded as installed, sorted
+func (s *Store) Installed() ([]string, error) {
+	records, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, r := range records {
+		if r.Installed {
+			names = append(names, r.Software)
+		}
+	}
+	return names, nil
+}
+
+// Dir returns the directory holding the state: SAI_STATE_DIR when set,
+// /var/lib/sai for root, and $XDG_STATE_HOME/sai or ~/.local/state/sai otherwise
+func Dir() string {
+	if dir := os.Getenv("SAI_STATE_DIR"); dir != "" {
+		return dir
+	}
+	if os.Geteuid() == 0 {
+		return "/var/lib/sai"
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "sai")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", "sai")
+	}
+	return filepath.Join(os.TempDir(), "sai")
+}
+
+// CurrentUser returns the user invoking sai, looking through sudo
+func CurrentUser() string {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// Global default store
+var (
+	defaultMu    sync.RWMutex
+	defaultStore = NewStore(filepath.Join(Dir(), FileName))
+)
+
+// SetDefault replaces the store used to record actions; nil disables recording
+func SetDefault(s *Store) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultStore = s
+}
+
+// Default returns the store used to record actions, or nil when disabled
+func Default() *Store {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultStore
+}
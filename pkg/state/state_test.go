@@ -0,0 +1,87 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestStoreRecord tests recording actions and reading them back
+func TestStoreRecord(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", FileName))
+	now := time.Now().UTC()
+
+	actions := []Action{
+		{Action: "install", Provider: "apt", ProviderType: "os", Target: "nginx", Version: "1.22.1-9", Time: now, Commands: []string{"apt-get install -y nginx"}},
+		{Action: "start", Provider: "systemd", ProviderType: "service", Target: "nginx", Time: now},
+	}
+	for _, a := range actions {
+		if err := store.Record("nginx", a); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if err := store.Record("redis", Action{Action: "install", Provider: "brew", Time: now}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	record, err := store.Get("nginx")
+	if err != nil || record == nil {
+		t.Fatalf("Expected a record, got: %v, %v", record, err)
+	}
+	if !record.Installed || record.Provider != "apt" || record.Version != "1.22.1-9" || len(record.History) != 2 {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+
+	if err := store.Record("nginx", Action{Action: "uninstall", Provider: "apt", Time: now}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	record, _ = store.Get("nginx")
+	if record.Installed || record.Version != "" || record.Provider != "apt" {
+		t.Errorf("Expected nginx to be recorded as removed, got: %+v", record)
+	}
+
+	list, err := store.List()
+	if err != nil || len(list) != 2 || list[0].Software != "nginx" || list[1].Software != "redis" {
+		t.Errorf("Expected two sorted records, got: %+v, %v", list, err)
+	}
+	if installed, err := store.Installed(); err != nil || len(installed) != 1 || installed[0] != "redis" {
+		t.Errorf("Expected only redis to be installed, got: %v, %v", installed, err)
+	}
+
+	if missing, err := store.Get("mysql"); missing != nil || err != nil {
+		t.Errorf("Expected no record for mysql, got: %+v, %v", missing, err)
+	}
+}
+
+// TestNilStore tests that a nil store disables recording
+func TestNilStore(t *testing.T) {
+	var store *Store
+	if err := store.Record("nginx", Action{Action: "install"}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if record, err := store.Get("nginx"); record != nil || err != nil {
+		t.Errorf("Expected no record, got: %+v, %v", record, err)
+	}
+	if installed, err := store.Installed(); len(installed) != 0 || err != nil {
+		t.Errorf("Expected no installed software, got: %v, %v", installed, err)
+	}
+}
+
+// TestDir tests the selection of the state directory
+func TestDir(t *testing.T) {
+	t.Setenv("SAI_STATE_DIR", "/tmp/sai-state")
+	if dir := Dir(); dir != "/tmp/sai-state" {
+		t.Errorf("Expected SAI_STATE_DIR to win, got: %s", dir)
+	}
+
+	t.Setenv("SAI_STATE_DIR", "")
+	t.Setenv("XDG_STATE_HOME", "/tmp/xdg-state")
+	expected := filepath.Join("/tmp/xdg-state", "sai")
+	if os.Geteuid() == 0 {
+		expected = "/var/lib/sai"
+	}
+	if dir := Dir(); dir != expected {
+		t.Errorf("Expected %s, got: %s", expected, dir)
+	}
+}